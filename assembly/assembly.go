@@ -5,30 +5,29 @@ import (
 	"encoding/json"
 
 	"table-app/conf"
+	"table-app/controller"
 	"table-app/gui"
 	"table-app/internal/app"
-	db "table-app/internal/db/client"
 	"table-app/internal/log"
+	"table-app/repository"
 
 	"github.com/pkg/errors"
 )
 
 type Assembly struct {
-	logger        *log.Adapter
-	db            *db.Client
-	shutdownFunc  func()
-	isFileStorage bool
+	logger       *log.Adapter
+	drivers      *repository.DriverRegistry
+	shutdownFunc func()
+	scheduler    *controller.Scheduler
 }
 
 func New(app *app.Application) *Assembly {
 	logger := app.Logger()
-	dbCli := db.NewClient(logger)
 
 	return &Assembly{
-		logger:        logger,
-		db:            dbCli,
-		shutdownFunc:  app.Shutdown,
-		isFileStorage: false,
+		logger:       logger,
+		drivers:      newDriverRegistry(logger),
+		shutdownFunc: app.Shutdown,
 	}
 }
 
@@ -40,33 +39,31 @@ func (a *Assembly) ReceiveConfig(ctx context.Context, remoteConfig []byte) (*gui
 		a.logger.Fatal(ctx, errors.WithMessage(err, "upgrade remote config"))
 	}
 
-	if newCfg.Storage.Database != nil {
-		err = a.db.Upgrade(ctx, *newCfg.Storage.Database)
-		if err != nil {
-			return nil, errors.WithMessage(err, "upgrade db client")
-		}
-	} else {
-		// если используется файловое хранилище данных
-		a.isFileStorage = true
-	}
-
-	locator := NewLocator(a.db, a.logger)
+	locator := NewLocator(a.drivers, a.logger)
 
 	// создание данных для gui с последующим занесением куда-то в ран или еще куда
-	guiApp, err := locator.Config(ctx, newCfg, a.shutdownFunc)
+	guiApp, scheduler, err := locator.Config(ctx, newCfg, a.shutdownFunc)
 	if err != nil {
-		a.logger.Fatal(ctx, errors.WithMessage(err, "get locator config"))
+		return nil, errors.WithMessage(err, "get locator config")
 	}
+	a.scheduler = scheduler
 
 	return guiApp, nil
 }
 
 func (a *Assembly) Runners() []app.Runner {
-	return []app.Runner{
+	runners := []app.Runner{
 		app.RunnerFunc(func(ctx context.Context) error {
 			return nil
 		}),
 	}
+
+	// проведение регулярных платежей при старте и далее по таймеру
+	if a.scheduler != nil {
+		runners = append(runners, a.scheduler)
+	}
+
+	return runners
 }
 
 func (a *Assembly) Closers() []app.Closer {
@@ -76,9 +73,8 @@ func (a *Assembly) Closers() []app.Closer {
 		}),
 	}
 
-	if !a.isFileStorage {
-		closers = append(closers, a.db)
-	}
+	// закрываются открытые хранилища: соединения с бд
+	closers = append(closers, a.drivers)
 
 	return closers
 }
@@ -0,0 +1,49 @@
+package assembly
+
+import (
+	"context"
+
+	"table-app/conf"
+	db "table-app/internal/db/client"
+	"table-app/internal/db/sqlite"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/pkg/errors"
+)
+
+// newDriverRegistry
+// драйверы хранилищ по ключам storage в конфиге; новое хранилище регистрируется здесь
+func newDriverRegistry(logger log.Logger) *repository.DriverRegistry {
+	drivers := repository.NewDriverRegistry()
+
+	drivers.Register(conf.StorageFiles, func(ctx context.Context, storage conf.Storage) (repository.Driver, error) {
+		return repository.OpenFileDriver(*storage.Files, logger)
+	})
+
+	drivers.Register(conf.StorageDatabase, func(ctx context.Context, storage conf.Storage) (repository.Driver, error) {
+		client := db.NewClient(logger)
+		err := client.Upgrade(ctx, *storage.Database)
+		if err != nil {
+			return nil, errors.WithMessage(err, "upgrade db client")
+		}
+
+		return repository.NewSQLDriver(client), nil
+	})
+
+	drivers.Register(conf.StorageSqlite, func(ctx context.Context, storage conf.Storage) (repository.Driver, error) {
+		client := sqlite.NewClient(logger)
+		err := client.Upgrade(ctx, *storage.Sqlite)
+		if err != nil {
+			return nil, errors.WithMessage(err, "upgrade sqlite client")
+		}
+
+		return repository.NewSQLDriver(client), nil
+	})
+
+	drivers.Register(conf.StorageMemory, func(ctx context.Context, storage conf.Storage) (repository.Driver, error) {
+		return repository.NewMemoryDriver(), nil
+	})
+
+	return drivers
+}
@@ -2,12 +2,12 @@ package assembly
 
 import (
 	"context"
+	"time"
 
 	"table-app/conf"
 	"table-app/controller"
 	"table-app/domain"
 	"table-app/gui"
-	"table-app/internal/db"
 	"table-app/internal/log"
 	"table-app/repository"
 	"table-app/service"
@@ -15,34 +15,115 @@ import (
 	"github.com/pkg/errors"
 )
 
-type DB interface {
-	db.DB
-}
-
 type Locator struct {
-	db     DB
-	logger log.Logger
+	drivers *repository.DriverRegistry
+	logger  log.Logger
 }
 
-func NewLocator(db DB, logger log.Logger) Locator {
+func NewLocator(drivers *repository.DriverRegistry, logger log.Logger) Locator {
 	return Locator{
-		db:     db,
-		logger: logger,
+		drivers: drivers,
+		logger:  logger,
 	}
 }
 
-func (l Locator) Config(ctx context.Context, cfg conf.Remote, shutdownFunc func()) (*gui.App, error) {
-	tableRepo := repository.NewTable(l.db, cfg.Storage)
-	categoryRepo := repository.NewCategory(l.db, cfg.Storage)
+// recurringInterval - период проверки наступивших регулярных платежей и платежей по кредитам
+const recurringInterval = time.Hour
+
+// Config
+// собирает кеши, сервисы и gui; возвращает также планировщик регулярных платежей для запуска в фоне
+func (l Locator) Config(ctx context.Context, cfg conf.Remote,
+	shutdownFunc func()) (*gui.App, *controller.Scheduler, error) {
+	// хранилище выбирается по ключу storage в конфиге
+	driver, err := l.drivers.Open(ctx, cfg.Storage)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "open storage")
+	}
+
+	cellsRepo := driver.Cells()
+	categoryRepo := driver.Category()
+	rateRepo := driver.Rate()
+	accountRepo := driver.Account()
+	transferRepo := driver.Transfer()
+	planRepo := driver.Plan()
+	recurringRepo := driver.Recurring()
+	mergeRepo := driver.CategoryMerge()
+	checkpointRepo := driver.Checkpoint()
+	goalRepo := driver.Goal()
+	loanRepo := driver.Loan()
+	valuationRepo := driver.Valuation()
+	splitRepo := driver.Split()
+	attachmentRepo := driver.Attachment()
+	payeeRepo := driver.Payee()
+
+	cellsData, transactions, err := cellsRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get cells")
+	}
+
+	rates, err := rateRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get rates")
+	}
+
+	accounts, err := accountRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get accounts")
+	}
+
+	transfers, err := transferRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get transfers")
+	}
+
+	plans, err := planRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get plans")
+	}
+
+	recurringItems, err := recurringRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get recurring")
+	}
+
+	checkpoints, err := checkpointRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get checkpoints")
+	}
+
+	goals, err := goalRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get goals")
+	}
 
-	cellsData, err := tableRepo.GetAll(ctx)
+	loans, err := loanRepo.GetAll(ctx)
 	if err != nil {
-		return nil, errors.WithMessage(err, "get cells")
+		return nil, nil, errors.WithMessage(err, "get loans")
+	}
+
+	valuations, err := valuationRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get valuations")
+	}
+
+	splits, err := splitRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get splits")
+	}
+
+	attachments, err := attachmentRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get attachments")
+	}
+
+	payees, err := payeeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "get payees")
 	}
 
 	categoryList, err := categoryRepo.GetAll(ctx)
 	if err != nil {
-		return nil, errors.WithMessage(err, "get categories")
+		return nil, nil, errors.WithMessage(err, "get categories")
 	}
 
 	if len(categoryList) == 0 {
@@ -50,29 +131,81 @@ func (l Locator) Config(ctx context.Context, cfg conf.Remote, shutdownFunc func(
 	}
 
 	cellsCache := repository.NewCellsCache()
-	cellsCache.InitCache(cellsData)
+	cellsCache.InitCache(cellsData, transactions)
 	cellsList := cellsCache.GetList()
 
 	categoryCache := repository.NewCategoryCache(cfg.Settings.MainCategoryOrder)
 	categoryCache.InitCache(categoryList)
 	categoryArray := categoryCache.GetCategoryArray()
 
+	rateCache := repository.NewRateCache(cfg.Settings.GetBaseCurrency())
+	rateCache.InitCache(rates)
+
+	accountCache := repository.NewAccountCache()
+	accountCache.InitCache(accounts, transfers)
+
+	planCache := repository.NewPlanCache()
+	planCache.InitCache(plans)
+
+	recurringCache := repository.NewRecurringCache()
+	recurringCache.InitCache(recurringItems)
+
+	checkpointCache := repository.NewCheckpointCache()
+	checkpointCache.InitCache(checkpoints)
+
+	goalCache := repository.NewGoalCache()
+	goalCache.InitCache(goals)
+
+	loanCache := repository.NewLoanCache()
+	loanCache.InitCache(loans)
+
+	valuationCache := repository.NewValuationCache()
+	valuationCache.InitCache(valuations)
+
+	splitCache := repository.NewSplitCache()
+	splitCache.InitCache(splits)
+
+	attachmentCache := repository.NewAttachmentCache()
+	attachmentCache.InitCache(attachments)
+
+	payeeCache := repository.NewPayeeCache()
+	payeeCache.InitCache(payees)
+
 	calculationCache := repository.NewCalculationCache(cfg.Settings)
-	err = calculationCache.InitCache(cellsList, categoryArray)
+	err = calculationCache.InitCache(cellsList, categoryArray, checkpoints, rateCache.Convert)
 	if err != nil {
-		return nil, errors.WithMessage(err, "init calculation cache")
-	}
-
-	isFileStorage := false
-	if cfg.Storage.Files != nil {
-		isFileStorage = true
+		// без курса валюты приложение должно запуститься, чтобы курс можно было ввести
+		l.logger.Warn(ctx, errors.WithMessage(err, "init calculation cache"))
 	}
 
-	tableService := service.NewTable(l.logger, cellsCache, tableRepo, cfg.Settings, isFileStorage)
+	tableService := service.NewTable(l.logger, cellsCache, cellsRepo, cfg.Settings)
 	categoryService := service.NewCategory(l.logger, categoryCache, categoryRepo)
-	calculationService := service.NewCalculation(calculationCache, cellsCache, categoryCache, cfg.Settings)
+	calculationService := service.NewCalculation(calculationCache, cellsCache, categoryCache, rateCache,
+		accountCache, planCache, checkpointCache, loanCache, valuationCache, cfg.Settings)
+	rateService := service.NewRate(l.logger, rateCache, rateRepo)
+	accountService := service.NewAccount(l.logger, accountCache, accountRepo, transferRepo)
+	planService := service.NewPlan(l.logger, planCache, planRepo)
+	recurringService := service.NewRecurring(l.logger, recurringCache, recurringRepo)
+	mergeService := service.NewCategoryMerge(l.logger, cellsCache, categoryCache, mergeRepo)
+	checkpointService := service.NewCheckpoint(l.logger, checkpointCache, checkpointRepo)
+	goalService := service.NewGoal(l.logger, goalCache, goalRepo)
+	loanService := service.NewLoan(l.logger, loanCache, loanRepo)
+	valuationService := service.NewValuation(l.logger, valuationCache, valuationRepo,
+		repository.NewNetWorthExport())
+	splitService := service.NewSplit(l.logger, splitCache, splitRepo)
+	attachmentService := service.NewAttachment(l.logger, attachmentCache, attachmentRepo,
+		repository.NewBackup(driver.DataFiles()))
+	payeeService := service.NewPayee(l.logger, payeeCache, payeeRepo)
+
+	err = calculationService.RecalculateAccounts()
+	if err != nil {
+		l.logger.Warn(ctx, errors.WithMessage(err, "recalculate accounts"))
+	}
 
-	tableCtrl := controller.NewTable(l.logger, tableService, categoryService, calculationService)
+	tableCtrl := controller.NewTable(l.logger, tableService, categoryService, calculationService, rateService,
+		accountService, planService, recurringService, mergeService, checkpointService,
+		goalService, loanService, valuationService, splitService, attachmentService,
+		payeeService)
 
 	guiApp := gui.NewApp(l.logger, gui.NewAppConfig(), tableCtrl, cfg.Settings, shutdownFunc)
 
@@ -82,5 +215,8 @@ func (l Locator) Config(ctx context.Context, cfg conf.Remote, shutdownFunc func(
 		MainCategoryOrder: cfg.Settings.MainCategoryOrder,
 	})
 
-	return guiApp, nil
+	scheduler := controller.NewScheduler(l.logger, tableCtrl, recurringInterval)
+	scheduler.OnUpsert(guiApp.RefreshCell)
+
+	return guiApp, scheduler, nil
 }
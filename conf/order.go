@@ -0,0 +1,113 @@
+package conf
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// CategoryKind
+// вид основной категории, определяет ее участие в расчете расходов и остатка
+type CategoryKind string
+
+const (
+	KindIncome   CategoryKind = "income"
+	KindExpense  CategoryKind = "expense"
+	KindTransfer CategoryKind = "transfer"
+	KindNeutral  CategoryKind = "neutral"
+)
+
+// legacyKinds
+// виды основных категорий для старого формата конфига, где указан только приоритет
+var legacyKinds = map[string]CategoryKind{
+	"Доходы":  KindIncome,
+	"Расходы": KindExpense,
+}
+
+// Order
+// основные категории по названию
+type Order map[string]MainCategory
+
+type MainCategory struct {
+	Priority int
+	Kind     CategoryKind
+}
+
+func (c CategoryKind) IsValid() bool {
+	switch c {
+	case KindIncome, KindExpense, KindTransfer, KindNeutral:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnmarshalJSON
+// поддерживает как объект {"priority": 0, "kind": "income"}, так и старый формат с одним приоритетом
+func (o *Order) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]json.RawMessage)
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return errors.WithMessage(err, "unmarshal main category order")
+	}
+
+	result := make(Order, len(raw))
+	for name, value := range raw {
+		var mainCategory MainCategory
+
+		var priority int
+		if json.Unmarshal(value, &priority) == nil {
+			mainCategory.Priority = priority
+			mainCategory.Kind = legacyKinds[name]
+		} else {
+			err = json.Unmarshal(value, &mainCategory)
+			if err != nil {
+				return errors.WithMessagef(err, "unmarshal main category %s", name)
+			}
+		}
+
+		if len(mainCategory.Kind) == 0 {
+			mainCategory.Kind = KindNeutral
+		}
+
+		if !mainCategory.Kind.IsValid() {
+			return errors.Errorf("main category %s: unknown kind %s", name, mainCategory.Kind)
+		}
+
+		result[name] = mainCategory
+	}
+
+	*o = result
+	return nil
+}
+
+// Priority
+// приоритет основной категории
+func (o Order) Priority(mainCategory string) (int, bool) {
+	category, ok := o[mainCategory]
+	return category.Priority, ok
+}
+
+// Kind
+// вид основной категории; неизвестные категории считаются нейтральными
+func (o Order) Kind(mainCategory string) CategoryKind {
+	category, ok := o[mainCategory]
+	if !ok {
+		return KindNeutral
+	}
+
+	return category.Kind
+}
+
+// FirstOfKind
+// основная категория вида kind с наименьшим приоритетом
+func (o Order) FirstOfKind(kind CategoryKind) (string, bool) {
+	name, priority := "", -1
+	for mainCategory, category := range o {
+		if category.Kind == kind && (priority == -1 || category.Priority < priority) {
+			name, priority = mainCategory, category.Priority
+		}
+	}
+
+	return name, priority != -1
+}
@@ -1,32 +1,161 @@
 package conf
 
 import (
+	"path/filepath"
+	"strings"
+
+	"table-app/entity"
 	db "table-app/internal/db/client"
+	"table-app/internal/db/sqlite"
 	"table-app/internal/log"
+
+	"github.com/pkg/errors"
 )
 
+const DefaultCurrency = "RUB"
+
 type Remote struct {
 	LogLevel log.Level `schemaGen:"logLevel" schema:"Уровень логирования"`
 	Storage  Storage
 	Settings Setting
 }
 
+// ключи хранилищ в storage конфига, по ним выбирается драйвер хранилища
+const (
+	StorageFiles    = "files"
+	StorageDatabase = "database"
+	StorageSqlite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+// Storage
+// задается одно хранилище: файлы csv, сервер postgres, локальный файл sqlite или память
 type Storage struct {
 	Files    *Files
 	Database *db.StorageConfig
+	Sqlite   *sqlite.Config
+	Memory   *Memory
+}
+
+// Memory
+// данные хранятся только в памяти и теряются при закрытии приложения; настроек нет
+type Memory struct{}
+
+// Key
+// ключ единственного заданного хранилища
+func (s Storage) Key() (string, error) {
+	keys := make([]string, 0, 1)
+	if s.Files != nil {
+		keys = append(keys, StorageFiles)
+	}
+	if s.Database != nil {
+		keys = append(keys, StorageDatabase)
+	}
+	if s.Sqlite != nil {
+		keys = append(keys, StorageSqlite)
+	}
+	if s.Memory != nil {
+		keys = append(keys, StorageMemory)
+	}
+
+	switch len(keys) {
+	case 0:
+		return "", errors.New("storage is not configured")
+	case 1:
+		return keys[0], nil
+	default:
+		return "", errors.Errorf("several storages are configured: %s", strings.Join(keys, ", "))
+	}
 }
 
 type Files struct {
-	TableFilePath    string
-	CategoryFilePath string
+	TableFilePath       string
+	CategoryFilePath    string
+	TransactionFilePath string
+	RateFilePath        string
+	AccountFilePath     string
+	TransferFilePath    string
+	PlanFilePath        string
+	RecurringFilePath   string
+	CheckpointFilePath  string
+	GoalFilePath        string
+	LoanFilePath        string
+	ValuationFilePath   string
+	SplitFilePath       string
+	AttachmentFilePath  string
+	PayeeFilePath       string
+	// AttachmentDir - каталог файлов вложений; по умолчанию attachments рядом с файлом таблицы
+	AttachmentDir string
+}
+
+// WithDefaults
+// файлы, которых нет в конфиге старой версии, по умолчанию лежат рядом с файлом таблицы,
+// так же как каталог вложений: например, transactionData.csv
+func (f Files) WithDefaults() Files {
+	dir := filepath.Dir(f.TableFilePath)
+	for _, item := range []struct {
+		path *string
+		name string
+	}{
+		{path: &f.CategoryFilePath, name: "categoryData.csv"},
+		{path: &f.TransactionFilePath, name: "transactionData.csv"},
+		{path: &f.RateFilePath, name: "rateData.csv"},
+		{path: &f.AccountFilePath, name: "accountData.csv"},
+		{path: &f.TransferFilePath, name: "transferData.csv"},
+		{path: &f.PlanFilePath, name: "planData.csv"},
+		{path: &f.RecurringFilePath, name: "recurringData.csv"},
+		{path: &f.CheckpointFilePath, name: "checkpointData.csv"},
+		{path: &f.GoalFilePath, name: "goalData.csv"},
+		{path: &f.LoanFilePath, name: "loanData.csv"},
+		{path: &f.ValuationFilePath, name: "valuationData.csv"},
+		{path: &f.SplitFilePath, name: "splitData.csv"},
+		{path: &f.AttachmentFilePath, name: "attachmentData.csv"},
+		{path: &f.PayeeFilePath, name: "payeeData.csv"},
+	} {
+		if len(*item.path) == 0 {
+			*item.path = filepath.Join(dir, item.name)
+		}
+	}
+
+	return f
+}
+
+// DataFiles
+// заданные пути файлов данных, кроме каталога вложений
+func (f Files) DataFiles() []string {
+	result := make([]string, 0)
+	for _, path := range []string{
+		f.TableFilePath, f.CategoryFilePath, f.TransactionFilePath, f.RateFilePath, f.AccountFilePath,
+		f.TransferFilePath, f.PlanFilePath, f.RecurringFilePath, f.CheckpointFilePath, f.GoalFilePath,
+		f.LoanFilePath, f.ValuationFilePath, f.SplitFilePath, f.AttachmentFilePath, f.PayeeFilePath,
+	} {
+		if len(path) != 0 {
+			result = append(result, path)
+		}
+	}
+
+	return result
 }
 
 type Setting struct {
 	StartYear         int
 	StartMonth        int
-	StartMoney        int
+	StartMoney        entity.Money
+	BaseCurrency      string
+	Currencies        []string
 	Gui               Gui
 	MainCategoryOrder Order
+	Period            Period
+}
+
+// GetBaseCurrency
+// базовая валюта, в которую переводятся все суммы при расчетах
+func (s Setting) GetBaseCurrency() string {
+	if len(s.BaseCurrency) == 0 {
+		return DefaultCurrency
+	}
+
+	return s.BaseCurrency
 }
 
 type Gui struct {
@@ -34,4 +163,10 @@ type Gui struct {
 	CellSizeDpY float32
 }
 
-type Order map[string]int
+// Period
+// разбиение на периоды по умолчанию для отчета по периодам: StartDay - день начала месяца,
+// FiscalStartMonth - первый месяц финансового года; незаданные значения означают календарные периоды
+type Period struct {
+	StartDay         int
+	FiscalStartMonth int
+}
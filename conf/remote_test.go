@@ -0,0 +1,70 @@
+package conf
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// конфиг первой версии: заданы только файлы таблицы и категорий
+const oldStyleConfig = `{
+  "storage": {
+    "files": {
+      "tableFilePath": "data/tableData.csv",
+      "categoryFilePath": "data/categories.csv"
+    }
+  }
+}`
+
+func TestFilesWithDefaultsOldStyleConfig(t *testing.T) {
+	var cfg Remote
+	err := json.Unmarshal([]byte(oldStyleConfig), &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := cfg.Storage.Files.WithDefaults()
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "table", got: files.TableFilePath, want: "data/tableData.csv"},
+		{name: "configured category", got: files.CategoryFilePath, want: "data/categories.csv"},
+		{name: "transaction", got: files.TransactionFilePath, want: filepath.Join("data", "transactionData.csv")},
+		{name: "rate", got: files.RateFilePath, want: filepath.Join("data", "rateData.csv")},
+		{name: "account", got: files.AccountFilePath, want: filepath.Join("data", "accountData.csv")},
+		{name: "transfer", got: files.TransferFilePath, want: filepath.Join("data", "transferData.csv")},
+		{name: "plan", got: files.PlanFilePath, want: filepath.Join("data", "planData.csv")},
+		{name: "recurring", got: files.RecurringFilePath, want: filepath.Join("data", "recurringData.csv")},
+		{name: "checkpoint", got: files.CheckpointFilePath, want: filepath.Join("data", "checkpointData.csv")},
+		{name: "goal", got: files.GoalFilePath, want: filepath.Join("data", "goalData.csv")},
+		{name: "loan", got: files.LoanFilePath, want: filepath.Join("data", "loanData.csv")},
+		{name: "valuation", got: files.ValuationFilePath, want: filepath.Join("data", "valuationData.csv")},
+		{name: "split", got: files.SplitFilePath, want: filepath.Join("data", "splitData.csv")},
+		{name: "attachment", got: files.AttachmentFilePath, want: filepath.Join("data", "attachmentData.csv")},
+		{name: "payee", got: files.PayeeFilePath, want: filepath.Join("data", "payeeData.csv")},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s file = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if len(files.DataFiles()) != len(tests) {
+		t.Errorf("data files = %v, want %d files", files.DataFiles(), len(tests))
+	}
+}
+
+func TestFilesWithDefaultsKeepsConfigured(t *testing.T) {
+	files := Files{TableFilePath: "table.csv", TransactionFilePath: "/var/data/transactions.csv"}.WithDefaults()
+
+	if files.TransactionFilePath != "/var/data/transactions.csv" {
+		t.Errorf("configured transaction file is replaced by %s", files.TransactionFilePath)
+	}
+	if files.PayeeFilePath != "payeeData.csv" {
+		t.Errorf("payee file next to table in working dir = %s", files.PayeeFilePath)
+	}
+}
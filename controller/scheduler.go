@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+// Scheduler
+// фоновая задача: проводит регулярные платежи и платежи по кредитам при старте и далее с интервалом interval
+type Scheduler struct {
+	logger   log.Logger
+	table    Table
+	interval time.Duration
+
+	// onUpsert - уведомление об измененной ячейке, например для обновления gui
+	onUpsert func(cell domain.Cell)
+}
+
+func NewScheduler(logger log.Logger, table Table, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		logger:   logger,
+		table:    table,
+		interval: interval,
+		onUpsert: func(domain.Cell) {},
+	}
+}
+
+func (s *Scheduler) OnUpsert(onUpsert func(cell domain.Cell)) {
+	s.onUpsert = onUpsert
+}
+
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.materialize(ctx)
+		if err != nil {
+			// ошибка одного прохода не останавливает планировщик, следующий проход ее повторит
+			s.logger.Error(ctx, errors.WithMessage(err, "materialize recurring"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) materialize(ctx context.Context) error {
+	now := time.Now()
+
+	cells, err := s.table.MaterializeRecurring(ctx, now)
+	for _, cell := range cells {
+		s.onUpsert(cell)
+	}
+
+	loanCells, loanErr := s.table.MaterializeLoans(ctx, now)
+	for _, cell := range loanCells {
+		s.onUpsert(cell)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if loanErr != nil {
+		return errors.WithMessage(loanErr, "materialize loans")
+	}
+
+	return nil
+}
@@ -2,32 +2,155 @@ package controller
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
+	"table-app/conf"
 	"table-app/domain"
+	"table-app/entity"
 	"table-app/internal/log"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
 
 type TableService interface {
 	Upsert(cell domain.Cell) error
+	UpsertAll(cells []domain.Cell) error
 	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	DeleteCategory(category domain.Category)
+	RenamePayee(oldName, newName string)
 	SaveAll(ctx context.Context) error
-	GetCellById(compositeId string) (domain.Cell, bool)
+	GetCellById(key domain.CellKey) (domain.Cell, bool)
+	AddTransaction(key domain.CellKey, transaction domain.Transaction) (domain.Cell, bool, error)
 }
 
 type CategoryService interface {
 	AddCategory(newCat domain.Category) error
 	CategoryIsExist(category domain.Category) bool
 	UpdateCategory(old, new domain.Category) error
+	GetCategory(mainCategory, name string) (domain.Category, bool)
+	EnsureCategory(kind conf.CategoryKind, name string) (domain.Category, error)
+	DeleteCategory(category domain.Category) error
+	ArchiveCategory(category domain.Category, month time.Month, year int) error
+	ReorderCategory(category domain.Category, shift int) error
+	ChangeMainCategory(category domain.Category, mainCategory string) ([]domain.Category, error)
 	SaveAll(ctx context.Context) error
+	SaveDeleted(ctx context.Context) error
 }
 
 type CalculationService interface {
-	ConsumptionSum(month, year int) int
-	UpsertBalance(month, year int) (map[string]int, error)
-	BalanceSum(month, year int) (int, error)
-	GetAnnualResult(year int) map[string]int
+	ConsumptionSum(month, year int) (entity.Money, error)
+	UpsertBalance(month, year int) (map[entity.MonthYear]entity.Money, error)
+	BalanceSum(month, year int) (entity.Money, error)
+	GetAnnualResult(year int) (map[string]domain.CategoryResult, error)
+	GetPeriodResults(setting domain.PeriodSetting, from, to time.Time) ([]domain.PeriodResult, error)
+	CategoryRollUp(category domain.Category, month, year int) (entity.Money, error)
+	Recalculate() error
+	RecalculateAccounts() error
+	AccountBalances(month, year int) map[string]entity.Money
+	CheckpointDiff(month, year int) (entity.Money, bool)
+	GoalProgress(goals []domain.Goal, now time.Time) ([]domain.GoalProgress, error)
+	NetWorth(fromYear, toYear int) []domain.NetWorthPoint
+	PayeeSpending(fromYear, toYear int) ([]domain.PayeeSpending, error)
+}
+
+type CategoryMergeService interface {
+	Merge(ctx context.Context, from, to domain.Category) error
+}
+
+type RateService interface {
+	GetRates() []domain.ExchangeRate
+	ReplaceRates(rates []domain.ExchangeRate) error
+	SaveAll(ctx context.Context) error
+}
+
+type AccountService interface {
+	GetAccounts() []domain.Account
+	GetTransfers() []domain.Transfer
+	ReplaceAccounts(accounts []domain.Account, transfers []domain.Transfer) error
+	SaveAll(ctx context.Context) error
+}
+
+type PlanService interface {
+	Upsert(plan domain.Plan) error
+	GetPlanById(key domain.CellKey) (domain.Plan, bool)
+	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	MoveCategory(from, to domain.Category)
+	DeleteCategory(category domain.Category)
+	SaveAll(ctx context.Context) error
+}
+
+type RecurringService interface {
+	GetRecurring() []domain.Recurring
+	ReplaceRecurring(items []domain.Recurring) error
+	MarkMaterialized(id string, date time.Time)
+	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	DeleteCategory(category domain.Category)
+	SaveAll(ctx context.Context) error
+}
+
+type CheckpointService interface {
+	GetCheckpoints() []domain.Checkpoint
+	ReplaceCheckpoints(items []domain.Checkpoint) error
+	SaveAll(ctx context.Context) error
+}
+
+type GoalService interface {
+	GetGoals() []domain.Goal
+	ReplaceGoals(goals []domain.Goal) error
+	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	DeleteCategory(category domain.Category)
+	SaveAll(ctx context.Context) error
+}
+
+type LoanService interface {
+	GetLoans() []domain.Loan
+	ReplaceLoans(loans []domain.Loan) error
+	MarkMaterialized(id string, date time.Time)
+	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	DeleteCategory(category domain.Category)
+	SaveAll(ctx context.Context) error
+}
+
+type ValuationService interface {
+	GetValuations() []domain.Valuation
+	ReplaceValuations(items []domain.Valuation) error
+	ExportNetWorth(filePath string, points []domain.NetWorthPoint) error
+	SaveAll(ctx context.Context) error
+}
+
+type SplitService interface {
+	GetSplit(id string) (domain.Split, bool)
+	GetSplitByTransaction(transactionId string) (domain.Split, bool)
+	UpsertSplit(split domain.Split) error
+	DeleteSplit(id string)
+	RenamePayee(oldName, newName string)
+	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	DeleteCategory(category domain.Category)
+	SaveAll(ctx context.Context) error
+}
+
+type AttachmentService interface {
+	GetAttachments(key domain.CellKey) []domain.Attachment
+	AddAttachment(ctx context.Context, item domain.Attachment, sourcePath string) (domain.Attachment, error)
+	DeleteAttachment(id string)
+	AttachmentPath(ctx context.Context, id string) (string, error)
+	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	DeleteCategory(category domain.Category)
+	Backup(ctx context.Context, filePath string) error
+	SaveAll(ctx context.Context) error
+}
+
+type PayeeService interface {
+	GetPayees() []domain.Payee
+	GetPayee(name string) (domain.Payee, bool)
+	ReplacePayees(items []domain.Payee) (map[string]string, error)
+	Remember(names []string, category domain.Category)
+	UpdateCategoryName(oldCateg, newCateg domain.Category)
+	DeleteCategory(category domain.Category)
+	SaveAll(ctx context.Context) error
 }
 
 type Table struct {
@@ -35,30 +158,76 @@ type Table struct {
 	service            TableService
 	categoryService    CategoryService
 	calculationService CalculationService
+	rateService        RateService
+	accountService     AccountService
+	planService        PlanService
+	recurringService   RecurringService
+	mergeService       CategoryMergeService
+	checkpointService  CheckpointService
+	goalService        GoalService
+	loanService        LoanService
+	valuationService   ValuationService
+	splitService       SplitService
+	attachmentService  AttachmentService
+	payeeService       PayeeService
+
+	// materializeLock - планировщик не проводит платежи во время сохранения: иначе дата
+	// последнего проведенного платежа могла бы сохраниться без ячеек с его операциями
+	materializeLock *sync.Mutex
 }
 
-func NewTable(logger log.Logger, service TableService, categoryService CategoryService, calculationService CalculationService) Table {
+func NewTable(logger log.Logger, service TableService, categoryService CategoryService,
+	calculationService CalculationService, rateService RateService, accountService AccountService,
+	planService PlanService, recurringService RecurringService, mergeService CategoryMergeService,
+	checkpointService CheckpointService, goalService GoalService, loanService LoanService,
+	valuationService ValuationService, splitService SplitService, attachmentService AttachmentService,
+	payeeService PayeeService) Table {
 	return Table{
 		logger:             logger,
 		service:            service,
 		categoryService:    categoryService,
 		calculationService: calculationService,
+		rateService:        rateService,
+		accountService:     accountService,
+		planService:        planService,
+		recurringService:   recurringService,
+		mergeService:       mergeService,
+		checkpointService:  checkpointService,
+		goalService:        goalService,
+		loanService:        loanService,
+		valuationService:   valuationService,
+		splitService:       splitService,
+		attachmentService:  attachmentService,
+		payeeService:       payeeService,
+		materializeLock:    &sync.Mutex{},
 	}
 }
 
 // UpsertValue
-// Обновление/добавление нового значения в кеш ячеек
+// Обновление/добавление нового значения в кеш ячеек; новые получатели операций попадают
+// в справочник с категорией ячейки по умолчанию
 func (c Table) UpsertValue(ctx context.Context, cell domain.Cell) error {
 	c.logger.Debug(ctx, "upsert new cell value",
 		log.String("category", cell.Category),
-		log.Int("value", cell.Value))
+		log.String("value", cell.Value.String()))
 
 	err := cell.Validate()
 	if err != nil {
 		return errors.WithMessage(err, "validate cell")
 	}
 
-	return c.service.Upsert(cell)
+	err = c.service.Upsert(cell)
+	if err != nil {
+		return err
+	}
+
+	payees := make([]string, 0)
+	for _, transaction := range cell.ActiveTransactions() {
+		payees = append(payees, transaction.Payee)
+	}
+	c.payeeService.Remember(payees, domain.Category{MainCategory: cell.MainCategory, Name: cell.Category})
+
+	return nil
 }
 
 // SaveAll
@@ -66,6 +235,9 @@ func (c Table) UpsertValue(ctx context.Context, cell domain.Cell) error {
 func (c Table) SaveAll(ctx context.Context) error {
 	c.logger.Debug(ctx, "save all")
 
+	c.materializeLock.Lock()
+	defer c.materializeLock.Unlock()
+
 	// сначала сохраняются изменения в категориях, так как в таблице ячеек обновляются
 	// названия категорий, и по ним далее идет обновление значений ячеек
 	err := c.categoryService.SaveAll(ctx)
@@ -73,7 +245,75 @@ func (c Table) SaveAll(ctx context.Context) error {
 		return errors.WithMessage(err, "save all categories")
 	}
 
-	return c.service.SaveAll(ctx)
+	err = c.rateService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all rates")
+	}
+
+	err = c.accountService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all accounts")
+	}
+
+	err = c.planService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all plans")
+	}
+
+	err = c.checkpointService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all checkpoints")
+	}
+
+	err = c.goalService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all goals")
+	}
+
+	err = c.valuationService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all valuations")
+	}
+
+	err = c.splitService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all splits")
+	}
+
+	err = c.attachmentService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all attachments")
+	}
+
+	err = c.payeeService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all payees")
+	}
+
+	err = c.service.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all cells")
+	}
+
+	// даты последних проведенных платежей сохраняются после ячеек с их операциями:
+	// если ячейки не сохранились, при следующем запуске платежи проведутся заново
+	err = c.recurringService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all recurring")
+	}
+
+	err = c.loanService.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all loans")
+	}
+
+	// категории удаляются после ячеек, которые на них ссылаются
+	err = c.categoryService.SaveDeleted(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save deleted categories")
+	}
+
+	return nil
 }
 
 // AddCategory
@@ -99,13 +339,173 @@ func (c Table) UpdateCategoryName(ctx context.Context, old, new domain.Category)
 	}
 
 	c.service.UpdateCategoryName(old, new)
+	c.planService.UpdateCategoryName(old, new)
+	c.recurringService.UpdateCategoryName(old, new)
+	c.goalService.UpdateCategoryName(old, new)
+	c.loanService.UpdateCategoryName(old, new)
+	c.splitService.UpdateCategoryName(old, new)
+	c.attachmentService.UpdateCategoryName(old, new)
+	c.payeeService.UpdateCategoryName(old, new)
+	return nil
+}
+
+// DeleteCategory
+// Удаление категории вместе с ячейками, планами, регулярными платежами и кредитами, если moveTo не задана,
+// иначе слияние категории с moveTo
+func (c Table) DeleteCategory(ctx context.Context, category domain.Category, moveTo *domain.Category) error {
+	if moveTo != nil {
+		return c.MergeCategory(ctx, category, *moveTo)
+	}
+
+	c.logger.Debug(ctx, "delete category",
+		log.String("mainCategory", category.MainCategory),
+		log.String("category", category.Name))
+
+	category, ok := c.categoryService.GetCategory(category.MainCategory, category.Name)
+	if !ok {
+		return errors.Errorf("category %s not found", category.Name)
+	}
+
+	err := c.categoryService.DeleteCategory(category)
+	if err != nil {
+		return errors.WithMessage(err, "delete category")
+	}
+
+	c.service.DeleteCategory(category)
+	c.planService.DeleteCategory(category)
+	c.recurringService.DeleteCategory(category)
+	c.goalService.DeleteCategory(category)
+	c.loanService.DeleteCategory(category)
+	c.splitService.DeleteCategory(category)
+	c.attachmentService.DeleteCategory(category)
+	c.payeeService.DeleteCategory(category)
+
+	err = c.calculationService.Recalculate()
+	if err != nil {
+		return errors.WithMessage(err, "recalculate")
+	}
+
+	return nil
+}
+
+// MergeCategory
+// Слияние категории from с категорией to: ячейки, операции, планы и регулярные платежи from
+// переносятся в to, категория from удаляется; ячейки и категории сразу сохраняются
+func (c Table) MergeCategory(ctx context.Context, from, to domain.Category) error {
+	c.logger.Debug(ctx, "merge category",
+		log.String("from", from.Name),
+		log.String("to", to.Name))
+
+	// если слияние не сохранилось, кеши уже изменены, поэтому остальные данные переносятся тоже
+	saveErr := c.mergeService.Merge(ctx, from, to)
+	if saveErr != nil && !errors.Is(saveErr, domain.ErrMergeNotSaved) {
+		return errors.WithMessage(saveErr, "merge category")
+	}
+
+	c.planService.MoveCategory(from, to)
+	c.recurringService.UpdateCategoryName(from, to)
+	c.goalService.UpdateCategoryName(from, to)
+	c.loanService.UpdateCategoryName(from, to)
+	c.splitService.UpdateCategoryName(from, to)
+	c.attachmentService.UpdateCategoryName(from, to)
+	c.payeeService.UpdateCategoryName(from, to)
+
+	err := c.calculationService.Recalculate()
+	if err != nil {
+		return errors.WithMessage(err, "recalculate")
+	}
+
+	if saveErr != nil {
+		return errors.WithMessage(saveErr, "merge category")
+	}
+
+	return nil
+}
+
+// ArchiveCategory
+// Архивирование категории с указанного месяца: в следующих месяцах категория не выводится
+func (c Table) ArchiveCategory(ctx context.Context, category domain.Category, month time.Month, year int) error {
+	c.logger.Debug(ctx, "archive category",
+		log.String("category", category.Name),
+		log.Int("month", int(month)),
+		log.Int("year", year))
+
+	return c.categoryService.ArchiveCategory(category, month, year)
+}
+
+// RestoreCategory
+// Возврат категории из архива
+func (c Table) RestoreCategory(ctx context.Context, category domain.Category) error {
+	c.logger.Debug(ctx, "restore category", log.String("category", category.Name))
+
+	return c.categoryService.ArchiveCategory(category, 0, 0)
+}
+
+// ReorderCategory
+// Сдвиг категории среди категорий с тем же родителем: shift < 0 - выше, shift > 0 - ниже
+func (c Table) ReorderCategory(ctx context.Context, category domain.Category, shift int) error {
+	c.logger.Debug(ctx, "reorder category",
+		log.String("category", category.Name),
+		log.Int("shift", shift))
+
+	return c.categoryService.ReorderCategory(category, shift)
+}
+
+// ChangeMainCategory
+// Перенос категории с подкатегориями в другую основную категорию вместе с ячейками,
+// планами и регулярными платежами; вид основной категории меняется, поэтому суммы пересчитываются
+func (c Table) ChangeMainCategory(ctx context.Context, category domain.Category, mainCategory string) error {
+	c.logger.Debug(ctx, "change main category",
+		log.String("category", category.Name),
+		log.String("mainCategory", mainCategory))
+
+	moved, err := c.categoryService.ChangeMainCategory(category, mainCategory)
+	if err != nil {
+		return errors.WithMessage(err, "change main category")
+	}
+
+	for _, old := range moved {
+		new := old
+		new.MainCategory = mainCategory
+
+		c.service.UpdateCategoryName(old, new)
+		c.planService.UpdateCategoryName(old, new)
+		c.recurringService.UpdateCategoryName(old, new)
+		c.goalService.UpdateCategoryName(old, new)
+		c.loanService.UpdateCategoryName(old, new)
+		c.splitService.UpdateCategoryName(old, new)
+		c.attachmentService.UpdateCategoryName(old, new)
+		c.payeeService.UpdateCategoryName(old, new)
+	}
+
+	err = c.calculationService.Recalculate()
+	if err != nil {
+		return errors.WithMessage(err, "recalculate")
+	}
+
 	return nil
 }
 
 // GetCellById
-// Получить ячейку по compositeId
-func (c Table) GetCellById(compositeId string) (domain.Cell, bool) {
-	return c.service.GetCellById(compositeId)
+// Получить ячейку по ключу
+func (c Table) GetCellById(key domain.CellKey) (domain.Cell, bool) {
+	return c.service.GetCellById(key)
+}
+
+// UpsertPlan
+// Обновление/добавление плана категории на месяц; нулевой план удаляется
+func (c Table) UpsertPlan(ctx context.Context, plan domain.Plan) error {
+	c.logger.Debug(ctx, "upsert plan",
+		log.String("category", plan.Category),
+		log.String("value", plan.Value.String()))
+
+	return c.planService.Upsert(plan)
+}
+
+// GetPlanById
+// Получить план по ключу ячейки
+func (c Table) GetPlanById(key domain.CellKey) (domain.Plan, bool) {
+	return c.planService.GetPlanById(key)
 }
 
 // CategoryIsExist
@@ -116,13 +516,18 @@ func (c Table) CategoryIsExist(ctx context.Context, category domain.Category) bo
 
 // GetConsumptionSum
 // Получение суммы расходов по конкретному месяцу и году
-func (c Table) GetConsumptionSum(month, year int) int {
-	return c.calculationService.ConsumptionSum(month, year)
+func (c Table) GetConsumptionSum(month, year int) (entity.Money, error) {
+	res, err := c.calculationService.ConsumptionSum(month, year)
+	if err != nil {
+		return res, errors.WithMessage(err, "get consumption sum")
+	}
+
+	return res, nil
 }
 
 // GetBalanceSum
 // Получение суммы остатка по конкретному месяцу и году
-func (c Table) GetBalanceSum(month, year int) (int, error) {
+func (c Table) GetBalanceSum(month, year int) (entity.Money, error) {
 	res, err := c.calculationService.BalanceSum(month, year)
 	if err != nil {
 		return 0, errors.WithMessage(err, "get balance sum")
@@ -133,17 +538,678 @@ func (c Table) GetBalanceSum(month, year int) (int, error) {
 
 // UpsertBalance
 // Обновление остатка
-func (c Table) UpsertBalance(month, year int) (map[string]int, error) {
+func (c Table) UpsertBalance(month, year int) (map[entity.MonthYear]entity.Money, error) {
 	res, err := c.calculationService.UpsertBalance(month, year)
 	if err != nil {
 		return nil, errors.WithMessage(err, "upsert balance")
 	}
 
+	err = c.calculationService.RecalculateAccounts()
+	if err != nil {
+		return res, errors.WithMessage(err, "recalculate accounts")
+	}
+
 	return res, nil
 }
 
 // GetAnnualResult
 // Годовой итог
-func (c Table) GetAnnualResult(year int) map[string]int {
-	return c.calculationService.GetAnnualResult(year)
+func (c Table) GetAnnualResult(year int) (map[string]domain.CategoryResult, error) {
+	res, err := c.calculationService.GetAnnualResult(year)
+	if err != nil {
+		return res, errors.WithMessage(err, "get annual result")
+	}
+
+	return res, nil
+}
+
+// GetPeriodResults
+// Итоги по периодам: неделям, месяцам, кварталам или финансовым годам
+func (c Table) GetPeriodResults(setting domain.PeriodSetting, from, to time.Time) ([]domain.PeriodResult, error) {
+	res, err := c.calculationService.GetPeriodResults(setting, from, to)
+	if err != nil {
+		return res, errors.WithMessage(err, "get period results")
+	}
+
+	return res, nil
+}
+
+// GetCategoryRollUp
+// Сумма категории за месяц вместе с подкатегориями
+func (c Table) GetCategoryRollUp(category domain.Category, month, year int) (entity.Money, error) {
+	res, err := c.calculationService.CategoryRollUp(category, month, year)
+	if err != nil {
+		return res, errors.WithMessage(err, "get category roll-up")
+	}
+
+	return res, nil
+}
+
+// GetRates
+// Таблица курсов валют
+func (c Table) GetRates() []domain.ExchangeRate {
+	return c.rateService.GetRates()
+}
+
+// UpdateRates
+// Замена таблицы курсов валют и пересчет расходов и остатков
+func (c Table) UpdateRates(ctx context.Context, rates []domain.ExchangeRate) error {
+	c.logger.Debug(ctx, "update rates", log.Int("count", len(rates)))
+
+	err := c.rateService.ReplaceRates(rates)
+	if err != nil {
+		return errors.WithMessage(err, "replace rates")
+	}
+
+	err = c.calculationService.Recalculate()
+	if err != nil {
+		return errors.WithMessage(err, "recalculate")
+	}
+
+	return nil
+}
+
+// GetAccounts
+// Список счетов
+func (c Table) GetAccounts() []domain.Account {
+	return c.accountService.GetAccounts()
+}
+
+// GetTransfers
+// Список переводов между счетами
+func (c Table) GetTransfers() []domain.Transfer {
+	return c.accountService.GetTransfers()
+}
+
+// UpdateAccounts
+// Замена счетов и переводов и пересчет остатков по счетам
+func (c Table) UpdateAccounts(ctx context.Context, accounts []domain.Account, transfers []domain.Transfer) error {
+	c.logger.Debug(ctx, "update accounts",
+		log.Int("accounts", len(accounts)),
+		log.Int("transfers", len(transfers)))
+
+	err := c.accountService.ReplaceAccounts(accounts, transfers)
+	if err != nil {
+		return errors.WithMessage(err, "replace accounts")
+	}
+
+	err = c.calculationService.RecalculateAccounts()
+	if err != nil {
+		return errors.WithMessage(err, "recalculate accounts")
+	}
+
+	return nil
+}
+
+// GetAccountBalances
+// Остатки по счетам на конец месяца
+func (c Table) GetAccountBalances(month, year int) map[string]entity.Money {
+	return c.calculationService.AccountBalances(month, year)
+}
+
+// GetRecurring
+// Список регулярных платежей
+func (c Table) GetRecurring() []domain.Recurring {
+	return c.recurringService.GetRecurring()
+}
+
+// UpdateRecurring
+// Замена списка регулярных платежей
+func (c Table) UpdateRecurring(ctx context.Context, items []domain.Recurring) error {
+	c.logger.Debug(ctx, "update recurring", log.Int("count", len(items)))
+
+	err := c.recurringService.ReplaceRecurring(items)
+	if err != nil {
+		return errors.WithMessage(err, "replace recurring")
+	}
+
+	return nil
+}
+
+// MaterializeRecurring
+// Проведение наступивших к now регулярных платежей операциями в ячейки; возвращает измененные ячейки
+func (c Table) MaterializeRecurring(ctx context.Context, now time.Time) ([]domain.Cell, error) {
+	c.materializeLock.Lock()
+	defer c.materializeLock.Unlock()
+
+	cells := make([]domain.Cell, 0)
+
+	for _, item := range c.recurringService.GetRecurring() {
+		dates := item.Due(now)
+		if len(dates) == 0 {
+			continue
+		}
+
+		category, categoryIsExist := c.categoryService.GetCategory(item.MainCategory, item.Category)
+
+		for _, date := range dates {
+			// в архивную категорию платежи больше не проводятся
+			if categoryIsExist && !category.IsActiveIn(date.Month(), date.Year()) {
+				continue
+			}
+
+			key := domain.NewCellKey(item.MainCategory, item.Category, date.Month(), date.Year())
+			cell, ok, err := c.addTransaction(ctx, key, item.Transaction(date))
+			if err != nil {
+				return cells, errors.WithMessagef(err, "upsert recurring %s", item.Category)
+			}
+			if !ok {
+				continue
+			}
+
+			cells = append(cells, cell)
+		}
+
+		c.recurringService.MarkMaterialized(item.Id, dates[len(dates)-1])
+	}
+
+	return cells, nil
+}
+
+// GetCheckpoints
+// Список сверок с фактическим остатком
+func (c Table) GetCheckpoints() []domain.Checkpoint {
+	return c.checkpointService.GetCheckpoints()
+}
+
+// UpdateCheckpoints
+// Замена списка сверок и пересчет остатков: следующий за сверкой месяц начинается с фактического остатка
+func (c Table) UpdateCheckpoints(ctx context.Context, items []domain.Checkpoint) error {
+	c.logger.Debug(ctx, "update checkpoints", log.Int("count", len(items)))
+
+	err := c.checkpointService.ReplaceCheckpoints(items)
+	if err != nil {
+		return errors.WithMessage(err, "replace checkpoints")
+	}
+
+	err = c.calculationService.Recalculate()
+	if err != nil {
+		return errors.WithMessage(err, "recalculate")
+	}
+
+	return nil
+}
+
+// GetCheckpointDiff
+// Расхождение последней сверки месяца с расчетным остатком
+func (c Table) GetCheckpointDiff(month, year int) (entity.Money, bool) {
+	return c.calculationService.CheckpointDiff(month, year)
+}
+
+// BookCheckpointDiff
+// Проведение расхождения сверки операцией в категорию "Неучтенное" первой основной категории расходов;
+// категория создается при необходимости. Проводится только сохраненная последняя сверка месяца.
+// Возвращает измененную ячейку
+func (c Table) BookCheckpointDiff(ctx context.Context, checkpoint domain.Checkpoint) (domain.Cell, error) {
+	latest, ok := domain.LatestCheckpoints(c.checkpointService.GetCheckpoints())[checkpoint.MonthYear()]
+	if !ok || latest.Id != checkpoint.Id {
+		return domain.Cell{}, errors.New("checkpoint is not the latest saved checkpoint of the month")
+	}
+
+	// дата берется из сохраненной сверки, а не из редактируемой
+	checkpoint = latest
+	month, year := checkpoint.Date.Month(), checkpoint.Date.Year()
+	diff, ok := c.calculationService.CheckpointDiff(int(month), year)
+	if !ok {
+		return domain.Cell{}, errors.New("checkpoint difference is not calculated")
+	}
+
+	if diff == 0 {
+		return domain.Cell{}, errors.New("checkpoint matches the calculated balance")
+	}
+
+	category, err := c.categoryService.EnsureCategory(conf.KindExpense, domain.UnaccountedCategory)
+	if err != nil {
+		return domain.Cell{}, errors.WithMessage(err, "ensure unaccounted category")
+	}
+
+	if !category.IsActiveIn(month, year) {
+		return domain.Cell{}, errors.Errorf("category %s is archived", category.Name)
+	}
+
+	cell, ok := c.GetCellById(category.CellKey(month, year))
+	if !ok {
+		cell = domain.Cell{
+			MainCategory: category.MainCategory,
+			Category:     category.Name,
+			Month:        month,
+			Year:         year,
+		}
+	}
+
+	// недостача - расход, излишек - отрицательный расход
+	cell.AddTransaction(domain.Transaction{
+		Id:        uuid.New().String(),
+		Date:      checkpoint.Date,
+		Amount:    -diff,
+		Note:      "Сверка " + checkpoint.Date.Format("02.01.2006"),
+		IsUpdated: true,
+	})
+
+	err = c.UpsertValue(ctx, cell)
+	if err != nil {
+		return domain.Cell{}, errors.WithMessage(err, "upsert unaccounted cell")
+	}
+
+	return cell, nil
+}
+
+// GetGoals
+// Список целей накопления
+func (c Table) GetGoals() []domain.Goal {
+	return c.goalService.GetGoals()
+}
+
+// UpdateGoals
+// Замена списка целей накопления
+func (c Table) UpdateGoals(ctx context.Context, goals []domain.Goal) error {
+	c.logger.Debug(ctx, "update goals", log.Int("count", len(goals)))
+
+	err := c.goalService.ReplaceGoals(goals)
+	if err != nil {
+		return errors.WithMessage(err, "replace goals")
+	}
+
+	return nil
+}
+
+// GetGoalProgress
+// Прогресс целей накопления на месяц now
+func (c Table) GetGoalProgress(now time.Time) ([]domain.GoalProgress, error) {
+	res, err := c.calculationService.GoalProgress(c.goalService.GetGoals(), now)
+	if err != nil {
+		return res, errors.WithMessage(err, "get goal progress")
+	}
+
+	return res, nil
+}
+
+// GetLoans
+// Список кредитов
+func (c Table) GetLoans() []domain.Loan {
+	return c.loanService.GetLoans()
+}
+
+// UpdateLoans
+// Замена списка кредитов
+func (c Table) UpdateLoans(ctx context.Context, loans []domain.Loan) error {
+	c.logger.Debug(ctx, "update loans", log.Int("count", len(loans)))
+
+	err := c.loanService.ReplaceLoans(loans)
+	if err != nil {
+		return errors.WithMessage(err, "replace loans")
+	}
+
+	return nil
+}
+
+// MaterializeLoans
+// Проведение наступивших к now платежей по кредитам: проценты и погашение долга проводятся
+// операциями в свои категории; возвращает измененные ячейки
+func (c Table) MaterializeLoans(ctx context.Context, now time.Time) ([]domain.Cell, error) {
+	c.materializeLock.Lock()
+	defer c.materializeLock.Unlock()
+
+	cells := make([]domain.Cell, 0)
+
+	for _, loan := range c.loanService.GetLoans() {
+		payments := loan.Due(now)
+		if len(payments) == 0 {
+			continue
+		}
+
+		for _, payment := range payments {
+			interest, principal := loan.Transactions(payment)
+
+			cell, ok, err := c.addLoanTransaction(ctx, loan.InterestMainCategory, loan.InterestCategory, interest)
+			if err != nil {
+				return cells, errors.WithMessagef(err, "upsert loan %s interest", loan.Name)
+			}
+			if ok {
+				cells = append(cells, cell)
+			}
+
+			cell, ok, err = c.addLoanTransaction(ctx, loan.PrincipalMainCategory, loan.PrincipalCategory, principal)
+			if err != nil {
+				return cells, errors.WithMessagef(err, "upsert loan %s principal", loan.Name)
+			}
+			if ok {
+				cells = append(cells, cell)
+			}
+		}
+
+		c.loanService.MarkMaterialized(loan.Id, payments[len(payments)-1].Date)
+	}
+
+	return cells, nil
+}
+
+// addLoanTransaction
+// добавляет операцию платежа в ячейку категории; false - операция уже проведена,
+// нулевая или категория в архиве
+func (c Table) addLoanTransaction(ctx context.Context, mainCategory, categoryName string,
+	transaction domain.Transaction) (domain.Cell, bool, error) {
+	if transaction.Amount == 0 {
+		return domain.Cell{}, false, nil
+	}
+
+	month, year := transaction.Date.Month(), transaction.Date.Year()
+	category, ok := c.categoryService.GetCategory(mainCategory, categoryName)
+	if ok && !category.IsActiveIn(month, year) {
+		return domain.Cell{}, false, nil
+	}
+
+	return c.addTransaction(ctx, domain.NewCellKey(mainCategory, categoryName, month, year), transaction)
+}
+
+// addTransaction
+// добавляет сформированную планировщиком операцию в ячейку: ячейка читается и обновляется
+// под одной блокировкой кеша, поэтому одновременное сохранение окна ячейки ее не затрет;
+// false - операция с таким id уже проведена
+func (c Table) addTransaction(ctx context.Context, key domain.CellKey,
+	transaction domain.Transaction) (domain.Cell, bool, error) {
+	c.logger.Debug(ctx, "add scheduled transaction",
+		log.String("category", key.Category),
+		log.String("amount", transaction.Amount.String()))
+
+	cell, ok, err := c.service.AddTransaction(key, transaction)
+	if err != nil || !ok {
+		return domain.Cell{}, false, err
+	}
+
+	c.payeeService.Remember([]string{transaction.Payee},
+		domain.Category{MainCategory: key.MainCategory, Name: key.Category})
+
+	return cell, true, nil
+}
+
+// GetValuations
+// Список оценок активов и обязательств
+func (c Table) GetValuations() []domain.Valuation {
+	return c.valuationService.GetValuations()
+}
+
+// UpdateValuations
+// Замена списка оценок активов и обязательств
+func (c Table) UpdateValuations(ctx context.Context, items []domain.Valuation) error {
+	c.logger.Debug(ctx, "update valuations", log.Int("count", len(items)))
+
+	err := c.valuationService.ReplaceValuations(items)
+	if err != nil {
+		return errors.WithMessage(err, "replace valuations")
+	}
+
+	return nil
+}
+
+// GetNetWorth
+// Капитал на конец каждого месяца с fromYear по toYear
+func (c Table) GetNetWorth(fromYear, toYear int) []domain.NetWorthPoint {
+	return c.calculationService.NetWorth(fromYear, toYear)
+}
+
+// ExportNetWorth
+// Выгрузка капитала на конец каждого месяца с fromYear по toYear в csv файл
+func (c Table) ExportNetWorth(ctx context.Context, filePath string, fromYear, toYear int) error {
+	c.logger.Debug(ctx, "export net worth", log.String("filePath", filePath))
+
+	err := c.valuationService.ExportNetWorth(filePath, c.calculationService.NetWorth(fromYear, toYear))
+	if err != nil {
+		return errors.WithMessage(err, "export net worth")
+	}
+
+	return nil
+}
+
+// GetSplitByTransaction
+// Разделенная операция, частью которой является операция ячейки
+func (c Table) GetSplitByTransaction(transactionId string) (domain.Split, bool) {
+	return c.splitService.GetSplitByTransaction(transactionId)
+}
+
+// UpdateSplit
+// Сохранение разделенной операции: суммы частей пересчитываются, операции частей обновляются
+// во всех затронутых ячейках за одну операцию с кешем; возвращает измененные ячейки
+func (c Table) UpdateSplit(ctx context.Context, split domain.Split) ([]domain.Cell, error) {
+	c.logger.Debug(ctx, "update split", log.String("id", split.Id), log.Int("parts", len(split.Parts)))
+
+	if split.ByPercent {
+		split = split.Rebalance(split.Amount)
+	}
+
+	err := split.Validate()
+	if err != nil {
+		return nil, errors.WithMessage(err, "validate split")
+	}
+
+	if len(split.Id) == 0 {
+		split.Id = uuid.New().String()
+	}
+
+	prev, _ := c.splitService.GetSplit(split.Id)
+	prevKeys := make(map[string]domain.CellKey, len(prev.Parts))
+	for _, part := range prev.Parts {
+		prevKeys[part.TransactionId] = prev.CellKey(part)
+	}
+
+	kept := make(map[string]bool, len(split.Parts))
+	for i := range split.Parts {
+		part := &split.Parts[i]
+
+		category, ok := c.categoryService.GetCategory(part.MainCategory, part.Category)
+		if !ok {
+			return nil, errors.Errorf("category %s not found", part.Category)
+		}
+
+		if !category.IsActiveIn(split.Date.Month(), split.Date.Year()) {
+			return nil, errors.Errorf("category %s is archived", part.Category)
+		}
+
+		// часть, перенесенная в другую ячейку, становится новой операцией, а старая удаляется
+		prevKey, ok := prevKeys[part.TransactionId]
+		if !ok || prevKey != split.CellKey(*part) {
+			part.TransactionId = uuid.New().String()
+		}
+		kept[part.TransactionId] = true
+	}
+
+	cells := make(map[domain.CellKey]domain.Cell)
+	for _, part := range prev.Parts {
+		if kept[part.TransactionId] {
+			continue
+		}
+
+		cell := c.splitCell(cells, prev, part)
+		cell.RemoveTransaction(part.TransactionId)
+		cells[cell.Key()] = cell
+	}
+
+	for _, part := range split.Parts {
+		cell := c.splitCell(cells, split, part)
+		cell.UpsertTransaction(split.Transaction(part))
+		cells[cell.Key()] = cell
+	}
+
+	res, err := c.upsertSplitCells(cells)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.splitService.UpsertSplit(split)
+	if err != nil {
+		return res, errors.WithMessage(err, "upsert split")
+	}
+
+	first := split.Parts[0]
+	c.payeeService.Remember([]string{split.Payee},
+		domain.Category{MainCategory: first.MainCategory, Name: first.Category})
+
+	return res, nil
+}
+
+// DeleteSplit
+// Удаление разделенной операции вместе с операциями частей; возвращает измененные ячейки
+func (c Table) DeleteSplit(ctx context.Context, id string) ([]domain.Cell, error) {
+	c.logger.Debug(ctx, "delete split", log.String("id", id))
+
+	split, ok := c.splitService.GetSplit(id)
+	if !ok {
+		return nil, errors.Errorf("split %s not found", id)
+	}
+
+	cells := make(map[domain.CellKey]domain.Cell)
+	for _, part := range split.Parts {
+		cell := c.splitCell(cells, split, part)
+		cell.RemoveTransaction(part.TransactionId)
+		cells[cell.Key()] = cell
+	}
+
+	res, err := c.upsertSplitCells(cells)
+	if err != nil {
+		return nil, err
+	}
+
+	c.splitService.DeleteSplit(id)
+	return res, nil
+}
+
+// splitCell
+// ячейка части: уже измененная в этой операции, из кеша или новая; операции копируются,
+// чтобы изменения не попали в кеш до общего обновления
+func (c Table) splitCell(cells map[domain.CellKey]domain.Cell, split domain.Split,
+	part domain.SplitPart) domain.Cell {
+	key := split.CellKey(part)
+	if cell, ok := cells[key]; ok {
+		return cell
+	}
+
+	cell, ok := c.GetCellById(key)
+	if !ok {
+		return domain.Cell{
+			MainCategory: part.MainCategory,
+			Category:     part.Category,
+			Month:        split.Date.Month(),
+			Year:         split.Date.Year(),
+		}
+	}
+
+	cell.Transactions = slices.Clone(cell.Transactions)
+	return cell
+}
+
+// upsertSplitCells
+// проверяет все ячейки и только затем обновляет их разом
+func (c Table) upsertSplitCells(cells map[domain.CellKey]domain.Cell) ([]domain.Cell, error) {
+	res := make([]domain.Cell, 0, len(cells))
+	for _, cell := range cells {
+		err := cell.Validate()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "validate cell %s", cell.Category)
+		}
+
+		res = append(res, cell)
+	}
+
+	err := c.service.UpsertAll(res)
+	if err != nil {
+		return nil, errors.WithMessage(err, "upsert split cells")
+	}
+
+	return res, nil
+}
+
+// GetAttachments
+// Вложения ячейки и ее операций
+func (c Table) GetAttachments(key domain.CellKey) []domain.Attachment {
+	return c.attachmentService.GetAttachments(key)
+}
+
+// AddAttachment
+// Прикрепление файла к ячейке или, если transactionId задан, к операции ячейки
+func (c Table) AddAttachment(ctx context.Context, key domain.CellKey, transactionId,
+	sourcePath string) (domain.Attachment, error) {
+	c.logger.Debug(ctx, "add attachment",
+		log.String("category", key.Category),
+		log.String("sourcePath", sourcePath))
+
+	item, err := c.attachmentService.AddAttachment(ctx, domain.Attachment{
+		MainCategory:  key.MainCategory,
+		Category:      key.Category,
+		Month:         key.Month,
+		Year:          key.Year,
+		TransactionId: transactionId,
+	}, sourcePath)
+	if err != nil {
+		return domain.Attachment{}, errors.WithMessage(err, "add attachment")
+	}
+
+	return item, nil
+}
+
+// DeleteAttachment
+// Удаление вложения
+func (c Table) DeleteAttachment(ctx context.Context, id string) {
+	c.logger.Debug(ctx, "delete attachment", log.String("id", id))
+
+	c.attachmentService.DeleteAttachment(id)
+}
+
+// AttachmentPath
+// Путь к файлу вложения для открытия в программе просмотра
+func (c Table) AttachmentPath(ctx context.Context, id string) (string, error) {
+	return c.attachmentService.AttachmentPath(ctx, id)
+}
+
+// Backup
+// Сохранение всех данных и резервная копия в zip архив вместе с вложениями
+func (c Table) Backup(ctx context.Context, filePath string) error {
+	c.logger.Debug(ctx, "backup", log.String("filePath", filePath))
+
+	err := c.SaveAll(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "save all")
+	}
+
+	err = c.attachmentService.Backup(ctx, filePath)
+	if err != nil {
+		return errors.WithMessage(err, "backup")
+	}
+
+	return nil
+}
+
+// GetPayees
+// Справочник получателей
+func (c Table) GetPayees() []domain.Payee {
+	return c.payeeService.GetPayees()
+}
+
+// GetPayee
+// Получатель из справочника по названию без учета регистра
+func (c Table) GetPayee(name string) (domain.Payee, bool) {
+	return c.payeeService.GetPayee(name)
+}
+
+// UpdatePayees
+// Замена справочника получателей; переименованный получатель меняется и во всех операциях
+func (c Table) UpdatePayees(ctx context.Context, items []domain.Payee) error {
+	c.logger.Debug(ctx, "update payees", log.Int("count", len(items)))
+
+	renamed, err := c.payeeService.ReplacePayees(items)
+	if err != nil {
+		return errors.WithMessage(err, "replace payees")
+	}
+
+	for newName, oldName := range renamed {
+		c.service.RenamePayee(oldName, newName)
+		c.splitService.RenamePayee(oldName, newName)
+	}
+
+	return nil
+}
+
+// GetPayeeSpending
+// Расходы по получателям с fromYear по toYear
+func (c Table) GetPayeeSpending(fromYear, toYear int) ([]domain.PayeeSpending, error) {
+	return c.calculationService.PayeeSpending(fromYear, toYear)
 }
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// UnassignedAccountId - условный счет для ячеек и операций без счета;
+// его начальный остаток - начальная сумма из настроек за вычетом начальных остатков счетов
+const UnassignedAccountId = ""
+
+const UnassignedAccountName = "Без счета"
+
+const (
+	AccountCash    = "cash"
+	AccountDebit   = "debit"
+	AccountSavings = "savings"
+)
+
+var AccountKinds = []string{AccountCash, AccountDebit, AccountSavings}
+
+// Account
+// счет (кошелек) с собственным начальным остатком на месяц начала учета
+type Account struct {
+	Id             string
+	Name           string
+	Kind           string
+	OpeningBalance entity.Money
+	Priority       int
+}
+
+func (a Account) Validate() error {
+	if len(a.Name) == 0 {
+		return errors.New("account name is empty")
+	}
+
+	for _, kind := range AccountKinds {
+		if a.Kind == kind {
+			return nil
+		}
+	}
+
+	return errors.Errorf("unknown account kind %s", a.Kind)
+}
+
+// Transfer
+// перевод между счетами в базовой валюте; на расходы и общий остаток не влияет
+type Transfer struct {
+	Id            string
+	FromAccountId string
+	ToAccountId   string
+	Amount        entity.Money
+	Date          time.Time
+	Note          string
+}
+
+func (t Transfer) Validate() error {
+	if t.FromAccountId == t.ToAccountId {
+		return errors.New("transfer accounts are the same")
+	}
+
+	if t.Amount <= 0 {
+		return errors.New("transfer amount must be positive")
+	}
+
+	if t.Date.IsZero() {
+		return errors.New("transfer date is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// attachmentExtensions - типы файлов, которые можно прикрепить: изображения и pdf
+var attachmentExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".webp": true,
+	".bmp":  true,
+	".pdf":  true,
+}
+
+// Attachment
+// файл (чек, счет), прикрепленный к ячейке категории за месяц или к одной из ее операций;
+// пустой TransactionId - вложение относится ко всей ячейке
+type Attachment struct {
+	Id            string
+	MainCategory  string
+	Category      string
+	Month         time.Month
+	Year          int
+	TransactionId string
+	// Name - исходное имя файла
+	Name  string
+	Size  int64
+	Added time.Time
+}
+
+// IsAttachmentFile
+// можно ли прикрепить файл с таким именем
+func IsAttachmentFile(name string) bool {
+	return attachmentExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+func (a Attachment) Validate() error {
+	if len(a.MainCategory) == 0 || len(a.Category) == 0 {
+		return errors.New("attachment category is empty")
+	}
+
+	if a.Month > 12 || a.Month < 1 {
+		return errors.New("invalid attachment month")
+	}
+
+	if len(a.Name) == 0 {
+		return errors.New("attachment name is empty")
+	}
+
+	if !IsAttachmentFile(a.Name) {
+		return errors.Errorf("unsupported attachment type %s", filepath.Ext(a.Name))
+	}
+
+	return nil
+}
+
+func (a Attachment) CellKey() CellKey {
+	return NewCellKey(a.MainCategory, a.Category, a.Month, a.Year)
+}
+
+// IsLinkedTo
+// относится ли вложение к ячейке категории
+func (a Attachment) IsLinkedTo(category Category) bool {
+	return a.MainCategory == category.MainCategory && a.Category == category.Name
+}
+
+// FileName
+// имя файла вложения в каталоге вложений: id и расширение исходного файла
+func (a Attachment) FileName() string {
+	return a.Id + strings.ToLower(filepath.Ext(a.Name))
+}
@@ -4,28 +4,82 @@ import (
 	"strconv"
 	"time"
 
+	"table-app/conf"
+
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 const startCategoryName = "Категория"
 
+// ErrMergeNotSaved
+// слияние категорий выполнено в кешах, но не записано в хранилище
+var ErrMergeNotSaved = errors.New("category merge is not saved")
+
+// MergeNotSavedError
+// ошибка хранилища при сохранении слияния: errors.Is находит в ней и ErrMergeNotSaved,
+// и исходную ошибку хранилища
+type MergeNotSavedError struct {
+	Err error
+}
+
+func (e MergeNotSavedError) Error() string {
+	return ErrMergeNotSaved.Error() + ": " + e.Err.Error()
+}
+
+func (e MergeNotSavedError) Unwrap() []error {
+	return []error{ErrMergeNotSaved, e.Err}
+}
+
 type Category struct {
 	Id           string
 	Name         string
 	MainCategory string
 	Priority     int
+	Currency     string
+
+	// ParentId - родительская категория той же основной категории; пустой - категория верхнего уровня
+	ParentId string
+
+	// ArchivedMonth, ArchivedYear - месяц, с которого категория в архиве; нулевой год - категория активна
+	ArchivedMonth time.Month
+	ArchivedYear  int
+}
+
+// IsArchived
+// категория скрыта из новых месяцев
+func (c Category) IsArchived() bool {
+	return c.ArchivedYear != 0
+}
+
+// IsActiveIn
+// категория доступна для ввода в указанном месяце
+func (c Category) IsActiveIn(month time.Month, year int) bool {
+	if !c.IsArchived() {
+		return true
+	}
+
+	return year < c.ArchivedYear || year == c.ArchivedYear && month < c.ArchivedMonth
+}
+
+// IsShownInYear
+// категория выводится в таблице года, если в нем есть хотя бы один месяц до архивации
+func (c Category) IsShownInYear(year int) bool {
+	return c.IsActiveIn(time.January, year)
 }
 
-func (c Category) CellCompositeId(month time.Month, year int) string {
-	return c.MainCategory + c.Name + strconv.Itoa(int(month)) + strconv.Itoa(year)
+// CellKey
+// ключ ячейки категории за месяц
+func (c Category) CellKey(month time.Month, year int) CellKey {
+	return NewCellKey(c.MainCategory, c.Name, month, year)
 }
 
-func GetStartingCategories(mainCategories map[string]int) []Category {
+func GetStartingCategories(mainCategories conf.Order) []Category {
 	result := make([]Category, 0)
-	for mainCategory, priority := range mainCategories {
+	for mainCategory, order := range mainCategories {
 		category := Category{
 			Id:           uuid.New().String(),
-			Name:         startCategoryName + " " + strconv.Itoa(priority+1),
+			Name:         startCategoryName + " " + strconv.Itoa(order.Priority+1),
 			MainCategory: mainCategory,
 			Priority:     1,
 		}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMergeNotSavedError(t *testing.T) {
+	storageErr := &fs.PathError{Op: "rename", Path: "category.csv", Err: fs.ErrPermission}
+	err := errors.WithMessage(MergeNotSavedError{Err: errors.WithMessage(storageErr, "save merge")}, "merge category")
+
+	if !errors.Is(err, ErrMergeNotSaved) {
+		t.Error("ErrMergeNotSaved is not found")
+	}
+
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Error("storage error is not found")
+	}
+
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "category.csv" {
+		t.Errorf("errors.As path error = %v", pathErr)
+	}
+
+	want := "merge category: category merge is not saved: save merge: rename category.csv: permission denied"
+	if err.Error() != want {
+		t.Errorf("message = %q, want %q", err.Error(), want)
+	}
+}
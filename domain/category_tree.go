@@ -0,0 +1,145 @@
+package domain
+
+import "sort"
+
+// CategoryTree
+// связи вложенных категорий; категория без родителя, с неизвестным родителем
+// или замкнутая в цикл родителей считается корневой
+type CategoryTree struct {
+	byId     map[string]Category
+	children map[string][]Category
+}
+
+func NewCategoryTree(categories []Category) CategoryTree {
+	tree := CategoryTree{
+		byId:     make(map[string]Category, len(categories)),
+		children: make(map[string][]Category),
+	}
+
+	for _, category := range categories {
+		tree.byId[category.Id] = category
+	}
+
+	for _, category := range categories {
+		parentId := category.ParentId
+		if _, ok := tree.byId[parentId]; !ok || tree.inCycle(category) {
+			parentId = ""
+			category.ParentId = ""
+			tree.byId[category.Id] = category
+		}
+		tree.children[parentId] = append(tree.children[parentId], category)
+	}
+
+	for parentId := range tree.children {
+		children := tree.children[parentId]
+		sort.Slice(children, func(i, j int) bool {
+			return children[i].Priority < children[j].Priority
+		})
+	}
+
+	return tree
+}
+
+// inCycle
+// приводит ли цепочка родителей категории обратно к ней
+func (t CategoryTree) inCycle(category Category) bool {
+	parent, ok := t.byId[category.ParentId]
+	for i := 0; ok && i < len(t.byId); i++ {
+		if parent.Id == category.Id {
+			return true
+		}
+		parent, ok = t.byId[parent.ParentId]
+	}
+
+	return false
+}
+
+// FindByName
+// поиск категории по основной категории и названию
+func (t CategoryTree) FindByName(mainCategory, name string) (Category, bool) {
+	for _, category := range t.byId {
+		if category.MainCategory == mainCategory && category.Name == name {
+			return category, true
+		}
+	}
+
+	return Category{}, false
+}
+
+// Children
+// непосредственные подкатегории в порядке приоритета
+func (t CategoryTree) Children(id string) []Category {
+	return t.children[id]
+}
+
+// Siblings
+// категории с тем же родителем, включая саму категорию, в порядке приоритета
+func (t CategoryTree) Siblings(id string) []Category {
+	category, ok := t.byId[id]
+	if !ok {
+		return nil
+	}
+
+	return t.children[category.ParentId]
+}
+
+func (t CategoryTree) HasChildren(id string) bool {
+	return len(t.children[id]) != 0
+}
+
+// Ancestors
+// родители категории от ближайшего к корневому
+func (t CategoryTree) Ancestors(category Category) []Category {
+	result := make([]Category, 0)
+	visited := map[string]bool{category.Id: true}
+
+	parent, ok := t.byId[category.ParentId]
+	for ok && !visited[parent.Id] {
+		visited[parent.Id] = true
+		result = append(result, parent)
+		parent, ok = t.byId[parent.ParentId]
+	}
+
+	return result
+}
+
+// Level
+// глубина вложенности, у корневой категории 0
+func (t CategoryTree) Level(category Category) int {
+	return len(t.Ancestors(category))
+}
+
+// Descendants
+// все вложенные категории на любую глубину
+func (t CategoryTree) Descendants(id string) []Category {
+	result := make([]Category, 0)
+	visited := map[string]bool{id: true}
+
+	var walk func(parentId string)
+	walk = func(parentId string) {
+		for _, child := range t.children[parentId] {
+			if visited[child.Id] {
+				continue
+			}
+			visited[child.Id] = true
+
+			result = append(result, child)
+			walk(child.Id)
+		}
+	}
+	walk(id)
+
+	return result
+}
+
+// Ordered
+// категории в порядке обхода дерева: родитель, затем его подкатегории
+func (t CategoryTree) Ordered() []Category {
+	result := make([]Category, 0, len(t.byId))
+	for _, root := range t.children[""] {
+		result = append(result, root)
+		result = append(result, t.Descendants(root.Id)...)
+	}
+
+	return result
+}
@@ -1,25 +1,33 @@
 package domain
 
 import (
-	"strconv"
 	"time"
 
+	"table-app/entity"
+
 	"github.com/pkg/errors"
 )
 
+const adjustmentNote = "Корректировка"
+
 type Cell struct {
 	Id           string
 	MainCategory string
 	Category     string
-	Value        int
+	Value        entity.Money
+	Currency     string
+	AccountId    string
 	Month        time.Month
 	Year         int
 	IsUpdated    bool
 	IsDeleted    bool
+
+	// Transactions - операции, из которых складывается Value
+	Transactions []Transaction
 }
 
-func (c Cell) CompositeId() string {
-	return c.MainCategory + c.Category + strconv.Itoa(int(c.Month)) + strconv.Itoa(c.Year)
+func (c Cell) Key() CellKey {
+	return NewCellKey(c.MainCategory, c.Category, c.Month, c.Year)
 }
 
 func (c Cell) Validate() error {
@@ -31,5 +39,205 @@ func (c Cell) Validate() error {
 		return errors.New("invalid month")
 	}
 
+	for _, transaction := range c.Transactions {
+		err := transaction.Validate()
+		if err != nil {
+			return errors.WithMessage(err, "validate transaction")
+		}
+	}
+
 	return nil
 }
+
+// CalculateValue
+// пересчитывает значение ячейки по ее операциям;
+// если операций нет, значение остается введенным вручную
+func (c *Cell) CalculateValue() {
+	transactions := c.ActiveTransactions()
+	if len(transactions) == 0 {
+		return
+	}
+
+	var sum entity.Money
+	for _, transaction := range transactions {
+		sum += transaction.Amount
+	}
+
+	c.Value = sum
+}
+
+// SetValue
+// устанавливает значение ячейки; если у ячейки есть операции,
+// разница с текущим значением добавляется корректирующей операцией
+func (c *Cell) SetValue(value entity.Money, date time.Time) {
+	if len(c.ActiveTransactions()) == 0 {
+		c.Value = value
+		return
+	}
+
+	diff := value - c.Value
+	if diff == 0 {
+		return
+	}
+
+	c.Transactions = append(c.Transactions, Transaction{
+		Date:      date,
+		Amount:    diff,
+		Note:      adjustmentNote,
+		IsUpdated: true,
+	})
+	c.CalculateValue()
+}
+
+// AddTransaction
+// добавляет операцию в ячейку; значение, введенное до появления операций,
+// сначала превращается в отдельную операцию, чтобы не потеряться при пересчете
+func (c *Cell) AddTransaction(transaction Transaction) {
+	if len(c.ActiveTransactions()) == 0 && c.Value != 0 {
+		c.Transactions = append(c.Transactions, Transaction{
+			Date:      c.DefaultDate(),
+			Amount:    c.Value,
+			IsUpdated: true,
+		})
+	}
+
+	c.Transactions = append(c.Transactions, transaction)
+	c.CalculateValue()
+}
+
+// UpsertTransaction
+// заменяет операцию с тем же id или добавляет новую
+func (c *Cell) UpsertTransaction(transaction Transaction) {
+	for i := range c.Transactions {
+		if c.Transactions[i].Id == transaction.Id && !c.Transactions[i].IsDeleted {
+			c.Transactions[i] = transaction
+			c.CalculateValue()
+			return
+		}
+	}
+
+	c.AddTransaction(transaction)
+}
+
+// RemoveTransaction
+// помечает операцию на удаление; без оставшихся операций значение ячейки обнуляется
+func (c *Cell) RemoveTransaction(id string) {
+	for i := range c.Transactions {
+		if c.Transactions[i].Id == id {
+			c.Transactions[i].IsDeleted = true
+		}
+	}
+
+	if len(c.ActiveTransactions()) == 0 {
+		c.Value = 0
+		return
+	}
+
+	c.CalculateValue()
+}
+
+// Absorb
+// переносит в ячейку значение и операции другой ячейки того же месяца;
+// операции сохраняют id и счет исходной ячейки, если он у них не указан
+func (c *Cell) Absorb(other Cell) {
+	transactions := other.ActiveTransactions()
+	if len(transactions) == 0 && len(c.ActiveTransactions()) == 0 && other.AccountId == c.AccountId {
+		c.Value += other.Value
+		return
+	}
+
+	if len(transactions) == 0 && other.Value != 0 {
+		transactions = append(transactions, Transaction{
+			Date:   other.DefaultDate(),
+			Amount: other.Value,
+		})
+	}
+
+	for _, transaction := range transactions {
+		if len(transaction.AccountId) == 0 {
+			transaction.AccountId = other.AccountId
+		}
+		transaction.CellId = c.Id
+		transaction.IsUpdated = true
+
+		c.AddTransaction(transaction)
+	}
+}
+
+// HasTransaction
+// есть ли у ячейки операция с таким id, в том числе помеченная на удаление
+func (c Cell) HasTransaction(id string) bool {
+	for _, transaction := range c.Transactions {
+		if transaction.Id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AccountFlows
+// распределение значения ячейки по счетам: по операциям, если они есть,
+// иначе целиком на счет ячейки; операции без счета относятся к счету ячейки
+func (c Cell) AccountFlows() map[string]entity.Money {
+	result := make(map[string]entity.Money)
+
+	transactions := c.ActiveTransactions()
+	if len(transactions) == 0 {
+		result[c.AccountId] = c.Value
+		return result
+	}
+
+	for _, transaction := range transactions {
+		accountId := transaction.AccountId
+		if len(accountId) == 0 {
+			accountId = c.AccountId
+		}
+
+		result[accountId] += transaction.Amount
+	}
+
+	return result
+}
+
+// DatedValues
+// значение ячейки по дням: по датам операций, если они есть,
+// иначе целиком на первое число месяца ячейки
+func (c Cell) DatedValues() []DatedValue {
+	transactions := c.ActiveTransactions()
+	if len(transactions) == 0 {
+		return []DatedValue{{Date: time.Date(c.Year, c.Month, 1, 0, 0, 0, 0, time.UTC), Amount: c.Value}}
+	}
+
+	result := make([]DatedValue, 0, len(transactions))
+	for _, transaction := range transactions {
+		result = append(result, DatedValue{Date: transaction.Date, Amount: transaction.Amount})
+	}
+
+	return result
+}
+
+// DefaultDate
+// дата для новой операции: сегодня, если ячейка относится к текущему месяцу,
+// иначе первое число месяца ячейки
+func (c Cell) DefaultDate() time.Time {
+	now := time.Now()
+	if now.Year() == c.Year && now.Month() == c.Month {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	return time.Date(c.Year, c.Month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ActiveTransactions
+// операции ячейки без помеченных на удаление
+func (c Cell) ActiveTransactions() []Transaction {
+	result := make([]Transaction, 0, len(c.Transactions))
+	for _, transaction := range c.Transactions {
+		if !transaction.IsDeleted {
+			result = append(result, transaction)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"table-app/entity"
+)
+
+// CellKey
+// ключ ячейки: категория и месяц хранятся отдельными полями, поэтому,
+// в отличие от склейки строк, "Еда1" за январь и "Еда" за ноябрь не совпадают
+type CellKey struct {
+	MainCategory string
+	Category     string
+	Month        time.Month
+	Year         int
+}
+
+func NewCellKey(mainCategory, category string, month time.Month, year int) CellKey {
+	return CellKey{
+		MainCategory: mainCategory,
+		Category:     category,
+		Month:        month,
+		Year:         year,
+	}
+}
+
+// Date
+// месяц ячейки
+func (k CellKey) Date() entity.MonthYear {
+	return entity.MonthYear{Month: int(k.Month), Year: k.Year}
+}
+
+// String
+// однозначное строковое представление, например для имен узлов gui:
+// названия экранируются, поэтому разделитель не может встретиться внутри них
+func (k CellKey) String() string {
+	return strconv.Quote(k.MainCategory) + ":" + strconv.Quote(k.Category) + ":" +
+		strconv.Itoa(int(k.Month)) + ":" + strconv.Itoa(k.Year)
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/conf"
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// UnaccountedCategory - категория расходов, в которую проводится расхождение со сверкой
+const UnaccountedCategory = "Неучтенное"
+
+// Checkpoint
+// сверка: фактический остаток всех средств в базовой валюте на конец дня Date
+type Checkpoint struct {
+	Id      string
+	Date    time.Time
+	Balance entity.Money
+	Note    string
+}
+
+func (c Checkpoint) Validate() error {
+	if c.Date.IsZero() {
+		return errors.New("checkpoint date is empty")
+	}
+
+	return nil
+}
+
+func (c Checkpoint) MonthYear() entity.MonthYear {
+	return entity.MonthYear{Month: int(c.Date.Month()), Year: c.Date.Year()}
+}
+
+// LatestCheckpoints
+// последняя сверка каждого месяца: по ней считается расхождение месяца
+func LatestCheckpoints(checkpoints []Checkpoint) map[entity.MonthYear]Checkpoint {
+	res := make(map[entity.MonthYear]Checkpoint)
+	for _, checkpoint := range checkpoints {
+		latest, ok := res[checkpoint.MonthYear()]
+		if !ok || checkpoint.Date.After(latest.Date) {
+			res[checkpoint.MonthYear()] = checkpoint
+		}
+	}
+
+	return res
+}
+
+// CheckpointDiff
+// расхождение сверки с расчетом: фактический остаток минус остаток начала месяца prevBalance
+// и доходы за вычетом расходов месяца по дату сверки включительно
+func CheckpointDiff(checkpoint Checkpoint, prevBalance entity.Money, categories [][]Category,
+	valuesList map[CellKey]Cell, order conf.Order, convert Converter) (entity.Money, error) {
+	var flow entity.Money
+	var firstErr error
+
+	month, year := checkpoint.Date.Month(), checkpoint.Date.Year()
+	for _, mainCategory := range categories {
+		for _, categ := range mainCategory {
+			var sign entity.Money
+			switch order.Kind(categ.MainCategory) {
+			case conf.KindIncome:
+				sign = 1
+			case conf.KindExpense:
+				sign = -1
+			default:
+				continue
+			}
+
+			cell, ok := valuesList[categ.CellKey(month, year)]
+			if !ok {
+				continue
+			}
+
+			currency := ResolveCurrency(cell.Currency, categ.Currency, "")
+			for _, dated := range cell.DatedValues() {
+				if dated.Date.After(checkpoint.Date) {
+					continue
+				}
+
+				value := dated.Amount
+				if convert != nil {
+					var err error
+					value, err = convert(dated.Amount, currency, month, year)
+					if err != nil {
+						if firstErr == nil {
+							firstErr = err
+						}
+						continue
+					}
+				}
+
+				flow += sign * value
+			}
+		}
+	}
+
+	return checkpoint.Balance - prevBalance - flow, firstErr
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	GoalLinkCategory = "category"
+	GoalLinkAccount  = "account"
+)
+
+// Goal
+// цель накопления: сумма Target к сроку Deadline; накопленное складывается из ячеек
+// привязанных категорий (вместе с подкатегориями) и остатков привязанных счетов
+type Goal struct {
+	Id       string
+	Name     string
+	Target   entity.Money
+	Deadline time.Time
+	Links    []GoalLink
+}
+
+// GoalLink
+// привязка цели: категория (MainCategory, Category) или счет (AccountId) в зависимости от Kind
+type GoalLink struct {
+	Kind         string
+	MainCategory string
+	Category     string
+	AccountId    string
+}
+
+// GoalProgress
+// прогресс цели на месяц расчета: Percent - доля накопленного в процентах,
+// Monthly - ежемесячный взнос, нужный, чтобы успеть к сроку
+type GoalProgress struct {
+	Goal       Goal
+	Saved      entity.Money
+	Percent    int
+	MonthsLeft int
+	Monthly    entity.Money
+}
+
+func (g Goal) Validate() error {
+	if len(g.Name) == 0 {
+		return errors.New("goal name is empty")
+	}
+
+	if g.Target <= 0 {
+		return errors.New("goal target must be positive")
+	}
+
+	if g.Deadline.IsZero() {
+		return errors.New("goal deadline is empty")
+	}
+
+	if len(g.Links) == 0 {
+		return errors.New("goal has no linked categories or accounts")
+	}
+
+	for _, link := range g.Links {
+		switch link.Kind {
+		case GoalLinkCategory:
+			if len(link.MainCategory) == 0 || len(link.Category) == 0 {
+				return errors.New("goal category is empty")
+			}
+		case GoalLinkAccount:
+		default:
+			return errors.Errorf("unknown goal link kind %s", link.Kind)
+		}
+	}
+
+	return nil
+}
+
+// IsLinkedTo
+// привязана ли цель к категории
+func (l GoalLink) IsLinkedTo(category Category) bool {
+	return l.Kind == GoalLinkCategory && l.MainCategory == category.MainCategory && l.Category == category.Name
+}
+
+// Progress
+// прогресс цели при накопленной сумме saved на месяц now; срок включает месяц дедлайна,
+// после срока весь остаток нужно внести в текущем месяце
+func (g Goal) Progress(saved entity.Money, now time.Time) GoalProgress {
+	res := GoalProgress{
+		Goal:  g,
+		Saved: saved,
+	}
+
+	if g.Target > 0 {
+		res.Percent = int(int64(saved) * 100 / int64(g.Target))
+	}
+
+	res.MonthsLeft = (g.Deadline.Year()-now.Year())*12 + int(g.Deadline.Month()-now.Month()) + 1
+	if res.MonthsLeft < 1 {
+		res.MonthsLeft = 1
+	}
+
+	remaining := g.Target - saved
+	if remaining <= 0 {
+		return res
+	}
+
+	// взнос округляется вверх до копейки, чтобы к сроку набралась вся сумма
+	months := entity.Money(res.MonthsLeft)
+	res.Monthly = (remaining + months - 1) / months
+
+	return res
+}
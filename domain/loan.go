@@ -0,0 +1,219 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"table-app/entity"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	LoanAnnuity        = "annuity"
+	LoanDifferentiated = "differentiated"
+)
+
+// rateDivider - ставка хранится в сотых долях процента, за год
+const rateDivider = 100 * 100 * 12
+
+// Loan
+// кредит в базовой валюте: платежи проводятся в ячейки каждый месяц, начиная со StartDate,
+// проценты - в категорию InterestCategory, погашение долга - в PrincipalCategory
+type Loan struct {
+	Id        string
+	Name      string
+	Principal entity.Money
+	// Rate - годовая ставка в сотых долях процента: 1250 - 12,5%
+	Rate       int
+	TermMonths int
+	Type       string
+	// StartDate - дата первого платежа; если в месяце меньше дней, платеж проводится в последний день
+	StartDate time.Time
+	AccountId string
+
+	InterestMainCategory  string
+	InterestCategory      string
+	PrincipalMainCategory string
+	PrincipalCategory     string
+
+	// LastDate - дата последнего проведенного платежа
+	LastDate time.Time
+}
+
+// LoanPayment
+// платеж по графику: проценты, погашение долга и остаток долга после платежа
+type LoanPayment struct {
+	Date      time.Time
+	Interest  entity.Money
+	Principal entity.Money
+	Balance   entity.Money
+}
+
+func (p LoanPayment) Total() entity.Money {
+	return p.Interest + p.Principal
+}
+
+func (l Loan) Validate() error {
+	if len(l.Name) == 0 {
+		return errors.New("loan name is empty")
+	}
+
+	if l.Principal <= 0 {
+		return errors.New("loan principal must be positive")
+	}
+
+	if l.Rate < 0 {
+		return errors.New("loan rate is negative")
+	}
+
+	if l.TermMonths < 1 {
+		return errors.New("invalid loan term")
+	}
+
+	if l.Type != LoanAnnuity && l.Type != LoanDifferentiated {
+		return errors.Errorf("unknown loan type %s", l.Type)
+	}
+
+	if l.StartDate.IsZero() {
+		return errors.New("first payment date is empty")
+	}
+
+	if len(l.InterestMainCategory) == 0 || len(l.InterestCategory) == 0 ||
+		len(l.PrincipalMainCategory) == 0 || len(l.PrincipalCategory) == 0 {
+		return errors.New("loan category is empty")
+	}
+
+	return nil
+}
+
+// IsLinkedTo
+// проводятся ли платежи кредита в категорию
+func (l Loan) IsLinkedTo(category Category) bool {
+	return (l.InterestMainCategory == category.MainCategory && l.InterestCategory == category.Name) ||
+		(l.PrincipalMainCategory == category.MainCategory && l.PrincipalCategory == category.Name)
+}
+
+// Schedule
+// график платежей: аннуитетный - равные платежи, дифференцированный - равное погашение долга
+// и убывающие проценты; последний платеж гасит остаток долга, набежавший из-за округления
+func (l Loan) Schedule() []LoanPayment {
+	result := make([]LoanPayment, 0, max(l.TermMonths, 0))
+	if l.TermMonths < 1 || l.Principal <= 0 {
+		return result
+	}
+
+	annuity := l.annuityPayment()
+	balance := l.Principal
+	for i := 0; i < l.TermMonths; i++ {
+		// проценты за месяц округляются до копейки
+		interest := entity.Money((int64(balance)*int64(l.Rate) + rateDivider/2) / rateDivider)
+
+		var principal entity.Money
+		if l.Type == LoanAnnuity {
+			principal = annuity - interest
+		} else {
+			principal = l.Principal / entity.Money(l.TermMonths)
+		}
+
+		if i == l.TermMonths-1 || principal > balance {
+			principal = balance
+		}
+		balance -= principal
+
+		result = append(result, LoanPayment{
+			Date:      l.paymentDate(i),
+			Interest:  interest,
+			Principal: principal,
+			Balance:   balance,
+		})
+	}
+
+	return result
+}
+
+// annuityPayment
+// ежемесячный аннуитетный платеж, округленный до копейки
+func (l Loan) annuityPayment() entity.Money {
+	if l.Rate == 0 {
+		return (l.Principal + entity.Money(l.TermMonths) - 1) / entity.Money(l.TermMonths)
+	}
+
+	rate := float64(l.Rate) / rateDivider
+	payment := float64(l.Principal) * rate / (1 - math.Pow(1+rate, -float64(l.TermMonths)))
+
+	return entity.Money(math.Round(payment))
+}
+
+func (l Loan) paymentDate(i int) time.Time {
+	firstDay := time.Date(l.StartDate.Year(), l.StartDate.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+	day := min(l.StartDate.Day(), firstDay.AddDate(0, 1, -1).Day())
+
+	return time.Date(firstDay.Year(), firstDay.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// Due
+// платежи, которые наступили к now и еще не проведены
+func (l Loan) Due(now time.Time) []LoanPayment {
+	result := make([]LoanPayment, 0)
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, payment := range l.Schedule() {
+		if payment.Date.After(today) {
+			break
+		}
+
+		if !l.LastDate.IsZero() && !payment.Date.After(l.LastDate) {
+			continue
+		}
+
+		result = append(result, payment)
+	}
+
+	return result
+}
+
+// Outstanding
+// остаток долга на конец дня date; кредит считается выданным за месяц до первого платежа
+func (l Loan) Outstanding(date time.Time) entity.Money {
+	if date.Before(l.StartDate.AddDate(0, -1, 0)) {
+		return 0
+	}
+
+	balance := l.Principal
+	for _, payment := range l.Schedule() {
+		if payment.Date.After(date) {
+			break
+		}
+
+		balance = payment.Balance
+	}
+
+	return balance
+}
+
+// Transactions
+// операции платежа: проценты и погашение долга; id зависят только от кредита и даты,
+// поэтому повторное проведение не создаст дубль
+func (l Loan) Transactions(payment LoanPayment) (Transaction, Transaction) {
+	interest := Transaction{
+		Id:        uuid.NewSHA1(uuid.NameSpaceOID, []byte(l.Id+payment.Date.Format("2006-01-02")+"interest")).String(),
+		AccountId: l.AccountId,
+		Date:      payment.Date,
+		Amount:    payment.Interest,
+		Note:      l.Name + ": проценты",
+		IsUpdated: true,
+	}
+
+	principal := Transaction{
+		Id:        uuid.NewSHA1(uuid.NameSpaceOID, []byte(l.Id+payment.Date.Format("2006-01-02")+"principal")).String(),
+		AccountId: l.AccountId,
+		Date:      payment.Date,
+		Amount:    payment.Principal,
+		Note:      l.Name + ": погашение долга",
+		IsUpdated: true,
+	}
+
+	return interest, principal
+}
@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"table-app/entity"
+)
+
+func testDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestLoanSchedule(t *testing.T) {
+	tests := []struct {
+		name string
+		loan Loan
+		want []LoanPayment
+	}{
+		{
+			name: "annuity",
+			loan: Loan{Principal: 100000, Rate: 1200, TermMonths: 2, Type: LoanAnnuity,
+				StartDate: testDate(2024, time.January, 15)},
+			want: []LoanPayment{
+				{Date: testDate(2024, time.January, 15), Interest: 1000, Principal: 49751, Balance: 50249},
+				{Date: testDate(2024, time.February, 15), Interest: 502, Principal: 50249, Balance: 0},
+			},
+		},
+		{
+			// последний платеж на копейку больше: остаток от округления процентов
+			name: "annuity rounding remainder in last payment",
+			loan: Loan{Principal: 100000, Rate: 1200, TermMonths: 3, Type: LoanAnnuity,
+				StartDate: testDate(2024, time.January, 15)},
+			want: []LoanPayment{
+				{Date: testDate(2024, time.January, 15), Interest: 1000, Principal: 33002, Balance: 66998},
+				{Date: testDate(2024, time.February, 15), Interest: 670, Principal: 33332, Balance: 33666},
+				{Date: testDate(2024, time.March, 15), Interest: 337, Principal: 33666, Balance: 0},
+			},
+		},
+		{
+			name: "annuity without interest",
+			loan: Loan{Principal: 1000, Rate: 0, TermMonths: 3, Type: LoanAnnuity,
+				StartDate: testDate(2024, time.January, 15)},
+			want: []LoanPayment{
+				{Date: testDate(2024, time.January, 15), Interest: 0, Principal: 334, Balance: 666},
+				{Date: testDate(2024, time.February, 15), Interest: 0, Principal: 334, Balance: 332},
+				{Date: testDate(2024, time.March, 15), Interest: 0, Principal: 332, Balance: 0},
+			},
+		},
+		{
+			name: "differentiated with remainder in last payment",
+			loan: Loan{Principal: 100000, Rate: 1200, TermMonths: 3, Type: LoanDifferentiated,
+				StartDate: testDate(2024, time.January, 15)},
+			want: []LoanPayment{
+				{Date: testDate(2024, time.January, 15), Interest: 1000, Principal: 33333, Balance: 66667},
+				{Date: testDate(2024, time.February, 15), Interest: 667, Principal: 33333, Balance: 33334},
+				{Date: testDate(2024, time.March, 15), Interest: 333, Principal: 33334, Balance: 0},
+			},
+		},
+		{
+			name: "payment day moves to the end of short months",
+			loan: Loan{Principal: 300, Rate: 0, TermMonths: 3, Type: LoanDifferentiated,
+				StartDate: testDate(2024, time.January, 31)},
+			want: []LoanPayment{
+				{Date: testDate(2024, time.January, 31), Principal: 100, Balance: 200},
+				{Date: testDate(2024, time.February, 29), Principal: 100, Balance: 100},
+				{Date: testDate(2024, time.March, 31), Principal: 100, Balance: 0},
+			},
+		},
+		{
+			name: "empty term",
+			loan: Loan{Principal: 1000, Rate: 1200, TermMonths: 0, Type: LoanAnnuity,
+				StartDate: testDate(2024, time.January, 15)},
+			want: []LoanPayment{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.loan.Schedule()
+			if len(got) != len(tt.want) {
+				t.Fatalf("Schedule() = %+v, want %+v", got, tt.want)
+			}
+
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("payment %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoanSchedulePaysPrincipal(t *testing.T) {
+	for _, loanType := range []string{LoanAnnuity, LoanDifferentiated} {
+		loan := Loan{Principal: 123456789, Rate: 1799, TermMonths: 37, Type: loanType,
+			StartDate: testDate(2024, time.January, 10)}
+
+		var paid entity.Money
+		for _, payment := range loan.Schedule() {
+			if payment.Principal < 0 || payment.Interest < 0 {
+				t.Fatalf("%s: negative payment %+v", loanType, payment)
+			}
+			paid += payment.Principal
+		}
+
+		if paid != loan.Principal {
+			t.Errorf("%s: paid principal %d, want %d", loanType, paid, loan.Principal)
+		}
+	}
+}
+
+func TestLoanOutstanding(t *testing.T) {
+	loan := Loan{Principal: 100000, Rate: 1200, TermMonths: 3, Type: LoanAnnuity,
+		StartDate: testDate(2024, time.January, 15)}
+
+	tests := []struct {
+		name string
+		date time.Time
+		want entity.Money
+	}{
+		{name: "before loan is issued", date: testDate(2023, time.December, 14), want: 0},
+		{name: "issued a month before first payment", date: testDate(2023, time.December, 15), want: 100000},
+		{name: "day before first payment", date: testDate(2024, time.January, 14), want: 100000},
+		{name: "first payment day", date: testDate(2024, time.January, 15), want: 66998},
+		{name: "between payments", date: testDate(2024, time.February, 1), want: 66998},
+		{name: "second payment day", date: testDate(2024, time.February, 15), want: 33666},
+		{name: "repaid", date: testDate(2024, time.March, 15), want: 0},
+		{name: "after repayment", date: testDate(2025, time.January, 1), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := loan.Outstanding(tt.date)
+			if got != tt.want {
+				t.Errorf("Outstanding(%s) = %d, want %d", tt.date.Format(time.DateOnly), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanDue(t *testing.T) {
+	loan := Loan{Principal: 100000, Rate: 1200, TermMonths: 3, Type: LoanAnnuity,
+		StartDate: testDate(2024, time.January, 15), LastDate: testDate(2024, time.January, 15)}
+
+	due := loan.Due(time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC))
+	if len(due) != 2 {
+		t.Fatalf("Due() = %+v, want 2 payments", due)
+	}
+	if !due[0].Date.Equal(testDate(2024, time.February, 15)) || !due[1].Date.Equal(testDate(2024, time.March, 15)) {
+		t.Errorf("due dates = %s, %s", due[0].Date, due[1].Date)
+	}
+}
+
+func TestLoanTransactionsIds(t *testing.T) {
+	loan := Loan{Id: "loan", Principal: 100000, Rate: 1200, TermMonths: 3, Type: LoanAnnuity,
+		StartDate: testDate(2024, time.January, 15)}
+	schedule := loan.Schedule()
+
+	interest, principal := loan.Transactions(schedule[0])
+	if interest.Id == principal.Id {
+		t.Error("interest and principal transactions have the same id")
+	}
+
+	// повторное проведение того же платежа дает те же id
+	interestAgain, principalAgain := loan.Transactions(schedule[0])
+	if interest.Id != interestAgain.Id || principal.Id != principalAgain.Id {
+		t.Error("transaction ids are not deterministic")
+	}
+
+	nextInterest, _ := loan.Transactions(schedule[1])
+	if nextInterest.Id == interest.Id {
+		t.Error("payments of different dates have the same id")
+	}
+
+	other := loan
+	other.Id = "other"
+	otherInterest, _ := other.Transactions(schedule[0])
+	if otherInterest.Id == interest.Id {
+		t.Error("payments of different loans have the same id")
+	}
+
+	if interest.Amount != schedule[0].Interest || principal.Amount != schedule[0].Principal {
+		t.Errorf("amounts = %d, %d, want %d, %d", interest.Amount, principal.Amount,
+			schedule[0].Interest, schedule[0].Principal)
+	}
+}
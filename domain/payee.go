@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"strings"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// Payee
+// получатель (магазин, организация) из справочника; операции ссылаются на него по названию,
+// категория по умолчанию предлагается при вводе получателя
+type Payee struct {
+	Id           string
+	Name         string
+	MainCategory string
+	Category     string
+}
+
+// PayeeSpending
+// расходы по получателю в базовой валюте по годам
+type PayeeSpending struct {
+	Payee  string
+	ByYear map[int]entity.Money
+	Total  entity.Money
+}
+
+func (p Payee) Validate() error {
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		return errors.New("payee name is empty")
+	}
+
+	if len(p.MainCategory) == 0 != (len(p.Category) == 0) {
+		return errors.New("payee category is incomplete")
+	}
+
+	return nil
+}
+
+// HasCategory
+// задана ли категория по умолчанию
+func (p Payee) HasCategory() bool {
+	return len(p.MainCategory) != 0 && len(p.Category) != 0
+}
+
+// IsLinkedTo
+// является ли категория категорией получателя по умолчанию
+func (p Payee) IsLinkedTo(category Category) bool {
+	return p.MainCategory == category.MainCategory && p.Category == category.Name
+}
+
+// IsSamePayee
+// названия получателей сравниваются без учета регистра и пробелов по краям
+func IsSamePayee(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
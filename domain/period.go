@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+const periodDateLayout = "02.01.2006"
+
+// PeriodKind
+// длительность периода, по которому суммируются значения
+type PeriodKind string
+
+const (
+	PeriodWeek    PeriodKind = "week"
+	PeriodMonth   PeriodKind = "month"
+	PeriodQuarter PeriodKind = "quarter"
+	PeriodYear    PeriodKind = "year"
+)
+
+// PeriodSetting
+// разбиение времени на периоды: StartDay - день, с которого начинается месяц
+// (например, 10 для учета от зарплаты до зарплаты), StartMonth - первый месяц
+// финансового года, от него же отсчитываются кварталы. Неделя начинается с понедельника
+type PeriodSetting struct {
+	Kind       PeriodKind
+	StartDay   int
+	StartMonth time.Month
+}
+
+// Period
+// интервал дат [Start, End)
+type Period struct {
+	Kind  PeriodKind
+	Start time.Time
+	End   time.Time
+}
+
+// PeriodResult
+// итоги периода в базовой валюте: по категориям (родитель включает подкатегории), доходы и расходы
+type PeriodResult struct {
+	Period      Period
+	ByCategory  map[string]entity.Money
+	Income      entity.Money
+	Consumption entity.Money
+}
+
+// DatedValue
+// часть значения ячейки, относящаяся к конкретному дню
+type DatedValue struct {
+	Date   time.Time
+	Amount entity.Money
+}
+
+func (s PeriodSetting) Validate() error {
+	switch s.Kind {
+	case PeriodWeek, PeriodMonth, PeriodQuarter, PeriodYear:
+	default:
+		return errors.Errorf("unknown period kind %s", s.Kind)
+	}
+
+	// с 29 числа месяц начинался бы не в каждом месяце
+	if s.StartDay < 1 || s.StartDay > 28 {
+		return errors.New("start day must be from 1 to 28")
+	}
+
+	if s.StartMonth < time.January || s.StartMonth > time.December {
+		return errors.New("invalid start month")
+	}
+
+	return nil
+}
+
+// PeriodOf
+// период, в который попадает дата
+func (s PeriodSetting) PeriodOf(date time.Time) Period {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	if s.Kind == PeriodWeek {
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return Period{Kind: s.Kind, Start: start, End: start.AddDate(0, 0, 7)}
+	}
+
+	start := time.Date(day.Year(), day.Month(), s.StartDay, 0, 0, 0, 0, time.UTC)
+	if day.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+
+	// сдвиг месяца от начала финансового года
+	offset := (int(start.Month()) - int(s.StartMonth) + 12) % 12
+
+	switch s.Kind {
+	case PeriodQuarter:
+		start = start.AddDate(0, -(offset % 3), 0)
+		return Period{Kind: s.Kind, Start: start, End: start.AddDate(0, 3, 0)}
+	case PeriodYear:
+		start = start.AddDate(0, -offset, 0)
+		return Period{Kind: s.Kind, Start: start, End: start.AddDate(1, 0, 0)}
+	default:
+		return Period{Kind: s.Kind, Start: start, End: start.AddDate(0, 1, 0)}
+	}
+}
+
+// Periods
+// последовательные периоды, покрывающие интервал [from, to)
+func (s PeriodSetting) Periods(from, to time.Time) []Period {
+	res := make([]Period, 0)
+	for period := s.PeriodOf(from); period.Start.Before(to); period = s.PeriodOf(period.End) {
+		res = append(res, period)
+	}
+
+	return res
+}
+
+// Contains
+// попадает ли дата в период
+func (p Period) Contains(date time.Time) bool {
+	return !date.Before(p.Start) && date.Before(p.End)
+}
+
+// Title
+// название периода: календарные месяц, квартал и год называются по имени, остальные - диапазоном дат
+func (p Period) Title() string {
+	last := p.End.AddDate(0, 0, -1)
+	if p.Start.Day() != 1 || p.Kind == PeriodWeek {
+		return p.Start.Format(periodDateLayout) + " - " + last.Format(periodDateLayout)
+	}
+
+	switch p.Kind {
+	case PeriodMonth:
+		return RusMonths[int(p.Start.Month())] + " " + strconv.Itoa(p.Start.Year())
+	case PeriodQuarter:
+		if (p.Start.Month()-time.January)%3 == 0 {
+			return strconv.Itoa(int(p.Start.Month()-time.January)/3+1) + " квартал " + strconv.Itoa(p.Start.Year())
+		}
+	case PeriodYear:
+		if p.Start.Month() == time.January {
+			return strconv.Itoa(p.Start.Year())
+		}
+
+		return strconv.Itoa(p.Start.Year()) + "/" + strconv.Itoa(last.Year())
+	}
+
+	return RusMonths[int(p.Start.Month())] + " " + strconv.Itoa(p.Start.Year()) + " - " +
+		RusMonths[int(last.Month())] + " " + strconv.Itoa(last.Year())
+}
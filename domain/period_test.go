@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodSettingPeriodOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting PeriodSetting
+		date    time.Time
+		start   time.Time
+		end     time.Time
+	}{
+		{name: "calendar month", setting: PeriodSetting{Kind: PeriodMonth, StartDay: 1, StartMonth: time.January},
+			date: testDate(2024, time.February, 29), start: testDate(2024, time.February, 1),
+			end: testDate(2024, time.March, 1)},
+		{name: "month from start day", setting: PeriodSetting{Kind: PeriodMonth, StartDay: 10, StartMonth: time.January},
+			date: testDate(2024, time.March, 10), start: testDate(2024, time.March, 10),
+			end: testDate(2024, time.April, 10)},
+		{name: "day before start day", setting: PeriodSetting{Kind: PeriodMonth, StartDay: 10, StartMonth: time.January},
+			date: testDate(2024, time.March, 9), start: testDate(2024, time.February, 10),
+			end: testDate(2024, time.March, 10)},
+		{name: "time of day is ignored", setting: PeriodSetting{Kind: PeriodMonth, StartDay: 10, StartMonth: time.January},
+			date: time.Date(2024, time.March, 9, 23, 59, 59, 0, time.UTC), start: testDate(2024, time.February, 10),
+			end: testDate(2024, time.March, 10)},
+		{name: "january before start day wraps to december", setting: PeriodSetting{Kind: PeriodMonth, StartDay: 10,
+			StartMonth: time.January}, date: testDate(2024, time.January, 5), start: testDate(2023, time.December, 10),
+			end: testDate(2024, time.January, 10)},
+		{name: "december after start day ends in january", setting: PeriodSetting{Kind: PeriodMonth, StartDay: 10,
+			StartMonth: time.January}, date: testDate(2023, time.December, 31), start: testDate(2023, time.December, 10),
+			end: testDate(2024, time.January, 10)},
+
+		{name: "calendar quarter", setting: PeriodSetting{Kind: PeriodQuarter, StartDay: 1, StartMonth: time.January},
+			date: testDate(2024, time.May, 15), start: testDate(2024, time.April, 1), end: testDate(2024, time.July, 1)},
+		{name: "fiscal quarter over new year", setting: PeriodSetting{Kind: PeriodQuarter, StartDay: 1,
+			StartMonth: time.February}, date: testDate(2024, time.January, 31), start: testDate(2023, time.November, 1),
+			end: testDate(2024, time.February, 1)},
+		{name: "fiscal quarter start", setting: PeriodSetting{Kind: PeriodQuarter, StartDay: 1, StartMonth: time.February},
+			date: testDate(2024, time.February, 1), start: testDate(2024, time.February, 1),
+			end: testDate(2024, time.May, 1)},
+		{name: "quarter day before start day", setting: PeriodSetting{Kind: PeriodQuarter, StartDay: 10,
+			StartMonth: time.April}, date: testDate(2024, time.April, 9), start: testDate(2024, time.January, 10),
+			end: testDate(2024, time.April, 10)},
+		{name: "quarter on start day", setting: PeriodSetting{Kind: PeriodQuarter, StartDay: 10, StartMonth: time.April},
+			date: testDate(2024, time.April, 10), start: testDate(2024, time.April, 10),
+			end: testDate(2024, time.July, 10)},
+
+		{name: "fiscal year last day", setting: PeriodSetting{Kind: PeriodYear, StartDay: 1, StartMonth: time.April},
+			date: testDate(2024, time.March, 31), start: testDate(2023, time.April, 1), end: testDate(2024, time.April, 1)},
+		{name: "fiscal year first day", setting: PeriodSetting{Kind: PeriodYear, StartDay: 1, StartMonth: time.April},
+			date: testDate(2024, time.April, 1), start: testDate(2024, time.April, 1), end: testDate(2025, time.April, 1)},
+		{name: "year day before start day", setting: PeriodSetting{Kind: PeriodYear, StartDay: 10,
+			StartMonth: time.January}, date: testDate(2024, time.January, 9), start: testDate(2023, time.January, 10),
+			end: testDate(2024, time.January, 10)},
+		{name: "year on start day", setting: PeriodSetting{Kind: PeriodYear, StartDay: 10, StartMonth: time.January},
+			date: testDate(2024, time.January, 10), start: testDate(2024, time.January, 10),
+			end: testDate(2025, time.January, 10)},
+
+		{name: "week from sunday", setting: PeriodSetting{Kind: PeriodWeek, StartDay: 10, StartMonth: time.January},
+			date: testDate(2024, time.March, 10), start: testDate(2024, time.March, 4), end: testDate(2024, time.March, 11)},
+		{name: "week from monday", setting: PeriodSetting{Kind: PeriodWeek, StartDay: 1, StartMonth: time.January},
+			date: testDate(2024, time.March, 11), start: testDate(2024, time.March, 11),
+			end: testDate(2024, time.March, 18)},
+		{name: "week over new year", setting: PeriodSetting{Kind: PeriodWeek, StartDay: 1, StartMonth: time.January},
+			date: testDate(2025, time.January, 1), start: testDate(2024, time.December, 30),
+			end: testDate(2025, time.January, 6)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.setting.PeriodOf(tt.date)
+			if !got.Start.Equal(tt.start) || !got.End.Equal(tt.end) || got.Kind != tt.setting.Kind {
+				t.Errorf("PeriodOf(%s) = %s - %s, want %s - %s", tt.date.Format(time.DateOnly),
+					got.Start.Format(time.DateOnly), got.End.Format(time.DateOnly),
+					tt.start.Format(time.DateOnly), tt.end.Format(time.DateOnly))
+			}
+
+			if !got.Contains(tt.date) {
+				t.Errorf("period %s - %s does not contain %s", got.Start.Format(time.DateOnly),
+					got.End.Format(time.DateOnly), tt.date.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestPeriodSettingPeriods(t *testing.T) {
+	setting := PeriodSetting{Kind: PeriodMonth, StartDay: 10, StartMonth: time.January}
+
+	got := setting.Periods(testDate(2024, time.January, 5), testDate(2024, time.March, 15))
+	want := []time.Time{
+		testDate(2023, time.December, 10),
+		testDate(2024, time.January, 10),
+		testDate(2024, time.February, 10),
+		testDate(2024, time.March, 10),
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("Periods() returned %d periods, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i]) || !got[i].End.Equal(want[i].AddDate(0, 1, 0)) {
+			t.Errorf("period %d = %s - %s", i, got[i].Start.Format(time.DateOnly), got[i].End.Format(time.DateOnly))
+		}
+	}
+}
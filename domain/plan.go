@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// Plan
+// плановая сумма категории на месяц; валюта плана - валюта ячейки или категории на момент ввода
+type Plan struct {
+	MainCategory string
+	Category     string
+	Month        time.Month
+	Year         int
+	Value        entity.Money
+	Currency     string
+}
+
+// Key
+// совпадает с ключом ячейки той же категории и месяца
+func (p Plan) Key() CellKey {
+	return NewCellKey(p.MainCategory, p.Category, p.Month, p.Year)
+}
+
+func (p Plan) Validate() error {
+	if len(p.MainCategory) == 0 || len(p.Category) == 0 {
+		return errors.New("category is empty")
+	}
+
+	if p.Month > 12 || p.Month < 1 {
+		return errors.New("invalid month")
+	}
+
+	return nil
+}
+
+// CategoryResult
+// итог категории за период: факт, план и отклонение факта от плана
+type CategoryResult struct {
+	Actual entity.Money
+	Plan   entity.Money
+}
+
+func (r CategoryResult) Variance() entity.Money {
+	return r.Actual - r.Plan
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// ExchangeRate
+// курс валюты к базовой валюте на месяц: сколько единиц базовой валюты стоит единица Currency
+type ExchangeRate struct {
+	Currency string
+	Month    time.Month
+	Year     int
+	Rate     float64
+}
+
+func (r ExchangeRate) Validate() error {
+	if len(r.Currency) == 0 {
+		return errors.New("currency is empty")
+	}
+
+	if r.Month > 12 || r.Month < 1 {
+		return errors.New("invalid month")
+	}
+
+	if r.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+
+	return nil
+}
+
+// Before
+// курс относится к месяцу не позже указанного
+func (r ExchangeRate) Before(month time.Month, year int) bool {
+	return r.Year < year || (r.Year == year && r.Month <= month)
+}
+
+// Converter
+// переводит сумму в указанной валюте в базовую валюту по курсу месяца
+type Converter func(value entity.Money, currency string, month time.Month, year int) (entity.Money, error)
+
+// ResolveCurrency
+// валюта ячейки: собственная, иначе валюта категории, иначе базовая
+func ResolveCurrency(cellCurrency, categoryCurrency, base string) string {
+	if len(cellCurrency) != 0 {
+		return cellCurrency
+	}
+
+	if len(categoryCurrency) != 0 {
+		return categoryCurrency
+	}
+
+	return base
+}
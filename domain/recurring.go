@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Recurring
+// регулярный платеж (зарплата, аренда, подписка), который проводится операцией в ячейку категории
+// каждые EveryMonths месяцев в день Day, начиная с StartDate и до EndDate включительно
+type Recurring struct {
+	Id           string
+	MainCategory string
+	Category     string
+	Amount       entity.Money
+	Note         string
+	Payee        string
+	AccountId    string
+
+	// Day - день месяца; если в месяце меньше дней, платеж проводится в последний день
+	Day         int
+	EveryMonths int
+	StartDate   time.Time
+	// EndDate - пустая дата означает бессрочный платеж
+	EndDate time.Time
+
+	// LastDate - дата последнего проведенного платежа
+	LastDate time.Time
+}
+
+func (r Recurring) Validate() error {
+	if len(r.MainCategory) == 0 || len(r.Category) == 0 {
+		return errors.New("category is empty")
+	}
+
+	if r.Amount == 0 {
+		return errors.New("recurring amount is zero")
+	}
+
+	if r.Day < 1 || r.Day > 31 {
+		return errors.New("invalid day of month")
+	}
+
+	if r.EveryMonths < 1 {
+		return errors.New("invalid recurring period")
+	}
+
+	if r.StartDate.IsZero() {
+		return errors.New("start date is empty")
+	}
+
+	if !r.EndDate.IsZero() && r.EndDate.Before(r.StartDate) {
+		return errors.New("end date is before start date")
+	}
+
+	return nil
+}
+
+// Due
+// даты платежей, которые наступили к now и еще не проведены
+func (r Recurring) Due(now time.Time) []time.Time {
+	result := make([]time.Time, 0)
+	if r.EveryMonths < 1 {
+		return result
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := 0; ; i += r.EveryMonths {
+		firstDay := time.Date(r.StartDate.Year(), r.StartDate.Month()+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+		day := min(r.Day, firstDay.AddDate(0, 1, -1).Day())
+		date := time.Date(firstDay.Year(), firstDay.Month(), day, 0, 0, 0, 0, time.UTC)
+
+		if date.After(today) || (!r.EndDate.IsZero() && date.After(r.EndDate)) {
+			break
+		}
+
+		if date.Before(r.StartDate) || (!r.LastDate.IsZero() && !date.After(r.LastDate)) {
+			continue
+		}
+
+		result = append(result, date)
+	}
+
+	return result
+}
+
+// TransactionId
+// id операции платежа зависит только от платежа и даты, поэтому повторное проведение не создаст дубль
+func (r Recurring) TransactionId(date time.Time) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(r.Id+date.Format("2006-01-02"))).String()
+}
+
+// Transaction
+// операция платежа на дату
+func (r Recurring) Transaction(date time.Time) Transaction {
+	return Transaction{
+		Id:        r.TransactionId(date),
+		AccountId: r.AccountId,
+		Date:      date,
+		Amount:    r.Amount,
+		Note:      r.Note,
+		Payee:     r.Payee,
+		IsUpdated: true,
+	}
+}
@@ -3,4 +3,6 @@ package domain
 const (
 	ColumnConsumption = "consumption"
 	ColumnBalance     = "balance"
+	ColumnDebt        = "debt"
+	ColumnNetWorth    = "netWorth"
 )
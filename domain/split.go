@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// percentTotal - 100% в сотых долях процента
+const percentTotal = 100 * 100
+
+// Split
+// операция, разделенная между несколькими категориями, например чек из супермаркета;
+// каждая часть - отдельная операция в ячейке своей категории, связанная с группой по TransactionId.
+// ByPercent - части заданы долями общей суммы, иначе суммами
+type Split struct {
+	Id        string
+	Date      time.Time
+	Amount    entity.Money
+	ByPercent bool
+	AccountId string
+	Note      string
+	Payee     string
+	Parts     []SplitPart
+}
+
+// SplitPart
+// часть разделенной операции; Percent - доля в сотых долях процента, используется при ByPercent
+type SplitPart struct {
+	TransactionId string
+	MainCategory  string
+	Category      string
+	Amount        entity.Money
+	Percent       int
+}
+
+func (s Split) Validate() error {
+	if s.Date.IsZero() {
+		return errors.New("split date is empty")
+	}
+
+	if len(s.Parts) < 2 {
+		return errors.New("split must have at least two parts")
+	}
+
+	var amount entity.Money
+	var percent int
+	for _, part := range s.Parts {
+		if len(part.MainCategory) == 0 || len(part.Category) == 0 {
+			return errors.New("split part category is empty")
+		}
+
+		amount += part.Amount
+		percent += part.Percent
+	}
+
+	if s.ByPercent && percent != percentTotal {
+		return errors.Errorf("split percents sum up to %s%%, not 100%%", entity.Money(percent).String())
+	}
+
+	if !s.ByPercent && amount != s.Amount {
+		return errors.Errorf("split parts sum up to %s, not %s", amount.String(), s.Amount.String())
+	}
+
+	return nil
+}
+
+// IsLinkedTo
+// проводится ли часть в категорию
+func (p SplitPart) IsLinkedTo(category Category) bool {
+	return p.MainCategory == category.MainCategory && p.Category == category.Name
+}
+
+// Rebalance
+// распределяет сумму amount по частям: по долям, если ByPercent, иначе пропорционально прежним суммам
+// частей; остаток от округления достается последней части, чтобы сумма частей совпадала с amount
+func (s Split) Rebalance(amount entity.Money) Split {
+	parts := make([]SplitPart, len(s.Parts))
+	copy(parts, s.Parts)
+
+	var prevAmount entity.Money
+	for _, part := range parts {
+		prevAmount += part.Amount
+	}
+
+	var distributed entity.Money
+	for i := range parts {
+		switch {
+		case s.ByPercent:
+			parts[i].Amount = entity.Money(int64(amount) * int64(parts[i].Percent) / percentTotal)
+		case prevAmount != 0:
+			parts[i].Amount = entity.Money(int64(amount) * int64(parts[i].Amount) / int64(prevAmount))
+		default:
+			parts[i].Amount = amount / entity.Money(len(parts))
+		}
+
+		distributed += parts[i].Amount
+	}
+
+	if len(parts) != 0 {
+		parts[len(parts)-1].Amount += amount - distributed
+	}
+
+	s.Amount = amount
+	s.Parts = parts
+	return s
+}
+
+// Transaction
+// операция части в ячейке категории
+func (s Split) Transaction(part SplitPart) Transaction {
+	return Transaction{
+		Id:        part.TransactionId,
+		AccountId: s.AccountId,
+		Date:      s.Date,
+		Amount:    part.Amount,
+		Note:      s.Note,
+		Payee:     s.Payee,
+		IsUpdated: true,
+	}
+}
+
+// CellKey
+// ячейка, в которую проводится часть
+func (s Split) CellKey(part SplitPart) CellKey {
+	return NewCellKey(part.MainCategory, part.Category, s.Date.Month(), s.Date.Year())
+}
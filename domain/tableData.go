@@ -1,33 +1,71 @@
 package domain
 
 import (
-	"strconv"
+	"time"
 
 	"table-app/conf"
+	"table-app/entity"
+
+	"github.com/pkg/errors"
 )
 
 type GuiTableData struct {
 	Categories        [][]Category
-	ValuesList        map[string]Cell
+	ValuesList        map[CellKey]Cell
 	MainCategoryOrder conf.Order
 }
 
-func (t GuiTableData) GetConsumptionSum(month, year int) int {
-	res := 0
-	idx := t.MainCategoryOrder["Расходы"]
+func (t GuiTableData) GetConsumptionSum(month, year int) (entity.Money, error) {
+	return SumByKind(t.Categories, t.ValuesList, t.MainCategoryOrder, conf.KindExpense, month, year, nil)
+}
+
+// SumByKind
+// сумма значений ячеек за месяц по всем категориям, основная категория которых имеет вид kind;
+// значения переводятся в базовую валюту через convert, nil - без перевода;
+// если какую-то ячейку перевести не удалось, возвращается сумма остальных и первая ошибка
+func SumByKind(categories [][]Category, valuesList map[CellKey]Cell, order conf.Order,
+	kind conf.CategoryKind, month, year int, convert Converter) (entity.Money, error) {
+	var res entity.Money
+	var firstErr error
 
-	for i, mainCategory := range t.Categories {
-		if i != idx {
-			continue
-		}
+	for _, mainCategory := range categories {
 		for _, categ := range mainCategory {
-			compositeId := categ.MainCategory + categ.Name + strconv.Itoa(month) + strconv.Itoa(year)
-			cell, ok := t.ValuesList[compositeId]
-			if ok {
-				res += cell.Value
+			if order.Kind(categ.MainCategory) != kind {
+				continue
+			}
+
+			cell, ok := valuesList[categ.CellKey(time.Month(month), year)]
+			if !ok {
+				continue
+			}
+
+			value, err := ConvertCell(cell, categ, convert)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
 			}
+
+			res += value
 		}
 	}
 
-	return res
+	return res, firstErr
+}
+
+// ConvertCell
+// значение ячейки в базовой валюте
+func ConvertCell(cell Cell, category Category, convert Converter) (entity.Money, error) {
+	if convert == nil {
+		return cell.Value, nil
+	}
+
+	currency := ResolveCurrency(cell.Currency, category.Currency, "")
+	value, err := convert(cell.Value, currency, cell.Month, cell.Year)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "convert cell %s %s", cell.Category, currency)
+	}
+
+	return value, nil
 }
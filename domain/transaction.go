@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// Transaction
+// отдельная операция, из которых складывается значение ячейки
+type Transaction struct {
+	Id        string
+	CellId    string
+	AccountId string
+	Date      time.Time
+	Amount    entity.Money
+	Note      string
+	Payee     string
+	IsUpdated bool
+	IsDeleted bool
+}
+
+func (t Transaction) Validate() error {
+	if t.Date.IsZero() {
+		return errors.New("transaction date is empty")
+	}
+
+	return nil
+}
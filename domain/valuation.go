@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"time"
+
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ValuationAsset     = "asset"
+	ValuationLiability = "liability"
+)
+
+// Valuation
+// оценка актива (вклад, брокерский счет, машина) или обязательства в базовой валюте на дату Date;
+// оценка действует до следующей оценки того же актива
+type Valuation struct {
+	Id    string
+	Asset string
+	Kind  string
+	Date  time.Time
+	Value entity.Money
+	Note  string
+}
+
+// NetWorthPoint
+// капитал на конец месяца: расчетный остаток с учетом сверки, оценки активов и обязательств
+// и остаток долга по кредитам
+type NetWorthPoint struct {
+	Date        entity.MonthYear
+	Balance     entity.Money
+	Assets      entity.Money
+	Liabilities entity.Money
+	Debt        entity.Money
+}
+
+func (v Valuation) Validate() error {
+	if len(v.Asset) == 0 {
+		return errors.New("valuation asset is empty")
+	}
+
+	if v.Kind != ValuationAsset && v.Kind != ValuationLiability {
+		return errors.Errorf("unknown valuation kind %s", v.Kind)
+	}
+
+	if v.Date.IsZero() {
+		return errors.New("valuation date is empty")
+	}
+
+	if v.Value < 0 {
+		return errors.New("valuation value is negative")
+	}
+
+	return nil
+}
+
+// NetWorth
+// капитал: остаток и активы за вычетом обязательств и долга по кредитам
+func (p NetWorthPoint) NetWorth() entity.Money {
+	return p.Balance + p.Assets - p.Liabilities - p.Debt
+}
+
+// ValuationsOn
+// суммы последних на конец дня date оценок каждого актива и каждого обязательства
+func ValuationsOn(valuations []Valuation, date time.Time) (entity.Money, entity.Money) {
+	type assetKey struct {
+		asset string
+		kind  string
+	}
+
+	latest := make(map[assetKey]Valuation)
+	for _, valuation := range valuations {
+		if valuation.Date.After(date) {
+			continue
+		}
+
+		key := assetKey{asset: valuation.Asset, kind: valuation.Kind}
+		prev, ok := latest[key]
+		if !ok || valuation.Date.After(prev.Date) {
+			latest[key] = valuation
+		}
+	}
+
+	var assets, liabilities entity.Money
+	for key, valuation := range latest {
+		if key.kind == ValuationLiability {
+			liabilities += valuation.Value
+		} else {
+			assets += valuation.Value
+		}
+	}
+
+	return assets, liabilities
+}
@@ -0,0 +1,216 @@
+package entity
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// minorUnits - количество копеек в рубле
+const minorUnits = 100
+
+// Money
+// денежная сумма в копейках
+type Money int64
+
+// NewMoney
+// сумма из целого числа рублей
+func NewMoney(major int64) Money {
+	return Money(major * minorUnits)
+}
+
+// ParseMoney
+// разбирает сумму вида "1 234,56", "1234.5", "-12"; разделитель дробной части - запятая или точка,
+// пробелы между разрядами игнорируются. Знак допускается только один и только в начале,
+// целая и дробная части состоят только из цифр
+func ParseMoney(str string) (Money, error) {
+	str = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', ' ', ' ', '\'', '\t':
+			return -1
+		default:
+			return r
+		}
+	}, str)
+
+	if len(str) == 0 {
+		return 0, errors.New("empty value")
+	}
+
+	source := str
+	negative := false
+	if str[0] == '-' || str[0] == '+' {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart, err := splitDecimal(str)
+	if err != nil {
+		return 0, err
+	}
+
+	if !isDigits(intPart) || (len(fracPart) != 0 && !isDigits(fracPart)) {
+		return 0, errors.Errorf("invalid number %s", source)
+	}
+
+	major, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("number %s is too large", source)
+	}
+
+	var minor int64
+	if len(fracPart) != 0 {
+		minor, err = strconv.ParseInt(fracPart, 10, 64)
+		if err != nil {
+			return 0, errors.Errorf("invalid number %s", source)
+		}
+
+		if len(fracPart) == 1 {
+			minor *= 10
+		}
+	}
+
+	if major > (math.MaxInt64-minor)/minorUnits {
+		return 0, errors.Errorf("number %s is too large", source)
+	}
+
+	result := Money(major*minorUnits + minor)
+	if negative {
+		result = -result
+	}
+
+	return result, nil
+}
+
+// splitDecimal
+// делит строку на целую и дробную части; при нескольких разделителях
+// дробной частью считается часть после последнего из них. Части между
+// разделителями не могут быть пустыми
+func splitDecimal(str string) (string, string, error) {
+	idx := strings.LastIndexAny(str, ",.")
+	if idx == -1 {
+		return str, "", nil
+	}
+
+	if strings.Count(str, str[idx:idx+1]) > 1 {
+		// "1.234.567" - разделители разрядов без дробной части
+		return joinGroups(str)
+	}
+
+	fracPart := str[idx+1:]
+	if len(fracPart) == 0 {
+		return "", "", errors.Errorf("empty decimal part in %s", str)
+	}
+
+	if len(fracPart) > 2 {
+		return "", "", errors.Errorf("more than two decimal places in %s", str)
+	}
+
+	intPart, _, err := joinGroups(str[:idx])
+	if err != nil {
+		return "", "", err
+	}
+
+	return intPart, fracPart, nil
+}
+
+// joinGroups
+// убирает разделители разрядов; пустые группы ("1..2", ",5") и группы после первой
+// не из трех цифр ("12.345.67", "1,2,3") - ошибка
+func joinGroups(str string) (string, string, error) {
+	groups := strings.Split(strings.ReplaceAll(str, ",", "."), ".")
+	for i, group := range groups {
+		if len(group) == 0 {
+			return "", "", errors.Errorf("empty digit group in %s", str)
+		}
+
+		if i > 0 && len(group) != 3 {
+			return "", "", errors.Errorf("digit group %s in %s is not three digits", group, str)
+		}
+	}
+
+	return strings.Join(groups, ""), "", nil
+}
+
+// isDigits
+// непустая строка только из цифр 0-9
+func isDigits(str string) bool {
+	if len(str) == 0 {
+		return false
+	}
+
+	for _, r := range str {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Major
+// целая часть суммы в рублях
+func (m Money) Major() int64 {
+	return int64(m) / minorUnits
+}
+
+// Minor
+// копейки суммы, всегда неотрицательные
+func (m Money) Minor() int64 {
+	minor := int64(m) % minorUnits
+	if minor < 0 {
+		return -minor
+	}
+
+	return minor
+}
+
+// String
+// представление суммы для хранения: "-1234.56"
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+	}
+
+	major := m.Major()
+	if major < 0 {
+		major = -major
+	}
+
+	return sign + strconv.FormatInt(major, 10) + "." + leftPad(strconv.FormatInt(m.Minor(), 10), 2)
+}
+
+// MarshalJSON
+// сумма в конфиге записывается числом в рублях
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON
+// принимает число в рублях (100000, 1234.56) или строку ("1 234,56")
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var str string
+	if json.Unmarshal(data, &str) != nil {
+		str = string(data)
+	}
+
+	money, err := ParseMoney(str)
+	if err != nil {
+		return errors.WithMessage(err, "parse money")
+	}
+
+	*m = money
+	return nil
+}
+
+func leftPad(str string, n int) string {
+	for len(str) < n {
+		str = "0" + str
+	}
+
+	return str
+}
@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		str     string
+		want    Money
+		wantErr bool
+	}{
+		{name: "integer", str: "12", want: 1200},
+		{name: "negative", str: "-12", want: -1200},
+		{name: "plus sign", str: "+12", want: 1200},
+		{name: "comma decimal", str: "1 234,56", want: 123456},
+		{name: "dot decimal", str: "1234.5", want: 123450},
+		{name: "thousands and decimal", str: "1,234.56", want: 123456},
+		{name: "thousands only", str: "1.234.567", want: 123456700},
+		{name: "nbsp thousands", str: "1 000", want: 100000},
+		{name: "negative fraction", str: "-0.05", want: -5},
+		{name: "max value", str: "92233720368547758.07", want: 9223372036854775807},
+
+		{name: "empty", str: "", wantErr: true},
+		{name: "only spaces", str: "  ", wantErr: true},
+		{name: "only minus", str: "-", wantErr: true},
+		{name: "only dot", str: ".", wantErr: true},
+		{name: "double minus", str: "--5", wantErr: true},
+		{name: "sign in fraction", str: "1.-5", wantErr: true},
+		{name: "plus in fraction", str: "1,+5", wantErr: true},
+		{name: "sign in integer part", str: "1-5", wantErr: true},
+		{name: "empty fraction", str: "5.", wantErr: true},
+		{name: "empty integer", str: ".5", wantErr: true},
+		{name: "empty group", str: "1..2", wantErr: true},
+		{name: "short last group", str: "12.345.67", wantErr: true},
+		{name: "one digit groups", str: "1,2,3", wantErr: true},
+		{name: "one digit last group", str: "1.234.5", wantErr: true},
+		{name: "short group before decimal", str: "1,23.45", wantErr: true},
+		{name: "three decimals", str: "1.234", wantErr: true},
+		{name: "letters", str: "12a", wantErr: true},
+		{name: "overflow on multiply", str: "9223372036854775807", wantErr: true},
+		{name: "overflow with fraction", str: "92233720368547758.08", wantErr: true},
+		{name: "overflow int64", str: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.str)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseMoney(%q) = %v, want error", tt.str, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseMoney(%q) error: %v", tt.str, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMoney(%q) = %d, want %d", tt.str, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: 0, want: "0.00"},
+		{money: 5, want: "0.05"},
+		{money: -5, want: "-0.05"},
+		{money: 123456, want: "1234.56"},
+		{money: -123400, want: "-1234.00"},
+	}
+
+	for _, tt := range tests {
+		got := tt.money.String()
+		if got != tt.want {
+			t.Errorf("Money(%d).String() = %s, want %s", tt.money, got, tt.want)
+		}
+
+		parsed, err := ParseMoney(got)
+		if err != nil || parsed != tt.money {
+			t.Errorf("ParseMoney(%s) = %d, %v, want %d", got, parsed, err, tt.money)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+	"github.com/google/uuid"
+)
+
+var accountKindTitles = map[string]string{
+	domain.AccountCash:    "Наличные",
+	domain.AccountDebit:   "Дебетовая карта",
+	domain.AccountSavings: "Накопительный счет",
+}
+
+// AccountWindow
+// окно редактирования счетов и переводов между ними
+type AccountWindow struct {
+	logger        log.Logger
+	appBody       *core.Body
+	accountDialog *core.Body
+	accountFrame  *core.Frame
+	transferFrame *core.Frame
+
+	controller TableController
+	sumUpdater *SumUpdater
+	accounts   []accountRow
+	transfers  []transferRow
+}
+
+// accountRow
+// строка списка счетов; deleted - строка удалена из окна
+type accountRow struct {
+	account domain.Account
+	deleted bool
+}
+
+// transferRow
+// строка списка переводов; deleted - строка удалена из окна
+type transferRow struct {
+	transfer domain.Transfer
+	deleted  bool
+}
+
+func NewAccountWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	sumUpdater *SumUpdater) *AccountWindow {
+	accountBody := core.NewBody("Accounts").SetTitle("Счета")
+	accountBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	accounts := make([]accountRow, 0)
+	for _, account := range controller.GetAccounts() {
+		accounts = append(accounts, accountRow{account: account})
+	}
+
+	transfers := make([]transferRow, 0)
+	for _, transfer := range controller.GetTransfers() {
+		transfers = append(transfers, transferRow{transfer: transfer})
+	}
+
+	accountWindow := &AccountWindow{
+		logger:        logger,
+		appBody:       appBody,
+		accountDialog: accountBody,
+		controller:    controller,
+		sumUpdater:    sumUpdater,
+		accounts:      accounts,
+		transfers:     transfers,
+	}
+
+	mainFrame := core.NewFrame(accountBody)
+	mainFrame.SetName("mainAccountFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Сумма начальных остатков счетов должна совпадать с начальной суммой из настроек")
+
+	accountWindow.addAccountList(mainFrame)
+
+	_ = core.NewSeparator(mainFrame)
+
+	core.NewText(mainFrame).SetType(core.TextTitleMedium).SetText("Переводы между счетами")
+	accountWindow.addTransferList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	accountWindow.addButtons(buttonsFrame)
+
+	return accountWindow
+}
+
+func (s *AccountWindow) addAccountList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Счет", "Тип", "Начальный остаток")
+
+	s.accountFrame = core.NewFrame(mainFrame)
+	s.accountFrame.SetName("accountFrame")
+	s.accountFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.accountFrame.Maker(func(p *tree.Plan) {
+		for i := range s.accounts {
+			if s.accounts[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "account_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addAccountRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить счет")
+	addButton.OnClick(func(e events.Event) {
+		// идентификатор нужен сразу, чтобы на новый счет можно было сослаться в переводе
+		s.accounts = append(s.accounts, accountRow{account: domain.Account{
+			Id:   uuid.New().String(),
+			Kind: domain.AccountDebit,
+		}})
+		s.accountFrame.Update()
+		s.transferFrame.Update()
+	})
+}
+
+func (s *AccountWindow) addAccountRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	account := s.accounts[idx].account
+
+	nameField := core.NewTextField(row).SetText(account.Name).SetPlaceholder("Название")
+	nameField.OnChange(func(e events.Event) {
+		s.accounts[idx].account.Name = strings.TrimSpace(nameField.Text())
+		s.transferFrame.Update()
+	})
+
+	kinds := make([]core.ChooserItem, 0, len(domain.AccountKinds))
+	for _, kind := range domain.AccountKinds {
+		kinds = append(kinds, core.ChooserItem{Value: kind, Text: accountKindTitles[kind]})
+	}
+
+	kindChooser := core.NewChooser(row).SetItems(kinds...).SetCurrentValue(account.Kind)
+	kindChooser.OnChange(func(e events.Event) {
+		value, ok := kindChooser.CurrentItem.Value.(string)
+		if ok {
+			s.accounts[idx].account.Kind = value
+		}
+	})
+
+	balanceField := core.NewTextField(row).SetPlaceholder("0")
+	if account.OpeningBalance != 0 {
+		balanceField.SetText(account.OpeningBalance.String())
+	}
+	balanceField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	balanceField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(balanceField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.accountDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.accounts[idx].account.OpeningBalance = value
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить счет")
+	deleteButton.OnClick(func(e events.Event) {
+		s.accounts[idx].deleted = true
+		s.accountFrame.Update()
+		s.transferFrame.Update()
+	})
+}
+
+func (s *AccountWindow) addTransferList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Дата", "Откуда", "Куда", "Сумма", "Комментарий")
+
+	s.transferFrame = core.NewFrame(mainFrame)
+	s.transferFrame.SetName("transferFrame")
+	s.transferFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.transferFrame.Maker(func(p *tree.Plan) {
+		for i := range s.transfers {
+			if s.transfers[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "transfer_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addTransferRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить перевод")
+	addButton.OnClick(func(e events.Event) {
+		s.transfers = append(s.transfers, transferRow{transfer: domain.Transfer{
+			Date: time.Now().UTC().Truncate(24 * time.Hour),
+		}})
+		s.transferFrame.Update()
+	})
+}
+
+func (s *AccountWindow) addTransferRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	transfer := s.transfers[idx].transfer
+
+	dateField := core.NewTextField(row).SetText(transfer.Date.Format(transactionDateLayout))
+	dateField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	dateField.OnChange(func(e events.Event) {
+		date, err := time.Parse(transactionDateLayout, strings.TrimSpace(dateField.Text()))
+		if err != nil {
+			core.MessageSnackbar(s.accountDialog, "Неверная дата, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.transfers[idx].transfer.Date = date
+	})
+
+	fromChooser := core.NewChooser(row)
+	fromChooser.Updater(func() {
+		fromChooser.SetItems(s.accountItems()...).SetCurrentValue(s.transfers[idx].transfer.FromAccountId)
+	})
+	fromChooser.OnChange(func(e events.Event) {
+		value, ok := fromChooser.CurrentItem.Value.(string)
+		if ok {
+			s.transfers[idx].transfer.FromAccountId = value
+		}
+	})
+
+	toChooser := core.NewChooser(row)
+	toChooser.Updater(func() {
+		toChooser.SetItems(s.accountItems()...).SetCurrentValue(s.transfers[idx].transfer.ToAccountId)
+	})
+	toChooser.OnChange(func(e events.Event) {
+		value, ok := toChooser.CurrentItem.Value.(string)
+		if ok {
+			s.transfers[idx].transfer.ToAccountId = value
+		}
+	})
+
+	amountField := core.NewTextField(row).SetPlaceholder("0")
+	if transfer.Amount != 0 {
+		amountField.SetText(transfer.Amount.String())
+	}
+	amountField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	amountField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(amountField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.accountDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.transfers[idx].transfer.Amount = value
+	})
+
+	noteField := core.NewTextField(row).SetText(transfer.Note)
+	noteField.OnChange(func(e events.Event) {
+		s.transfers[idx].transfer.Note = strings.TrimSpace(noteField.Text())
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить перевод")
+	deleteButton.OnClick(func(e events.Event) {
+		s.transfers[idx].deleted = true
+		s.transferFrame.Update()
+	})
+}
+
+// accountItems
+// варианты выбора счета, включая условный счет без названия
+func (s *AccountWindow) accountItems() []core.ChooserItem {
+	items := []core.ChooserItem{{Value: domain.UnassignedAccountId, Text: domain.UnassignedAccountName}}
+	for _, row := range s.accounts {
+		if !row.deleted {
+			items = append(items, core.ChooserItem{Value: row.account.Id, Text: row.account.Name})
+		}
+	}
+
+	return items
+}
+
+func (s *AccountWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		accounts := make([]domain.Account, 0, len(s.accounts))
+		for _, row := range s.accounts {
+			if !row.deleted {
+				accounts = append(accounts, row.account)
+			}
+		}
+
+		transfers := make([]domain.Transfer, 0, len(s.transfers))
+		for _, row := range s.transfers {
+			if !row.deleted {
+				transfers = append(transfers, row.transfer)
+			}
+		}
+
+		err := s.controller.UpdateAccounts(context.Background(), accounts, transfers)
+		if err != nil {
+			core.MessageSnackbar(s.accountDialog, "Ошибка сохранения счетов: "+err.Error())
+			s.logger.Error(context.Background(), "update accounts error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		s.sumUpdater.RefreshAll()
+	})
+}
+
+func (s *AccountWindow) Run() {
+	stage := s.accountDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *AccountWindow) close() {
+	s.accountDialog.Close()
+}
+
+// addHeader
+// строка заголовков колонок списка
+func addHeader(mainFrame *core.Frame, titles ...string) {
+	headFrame := core.NewFrame(mainFrame)
+	for _, title := range titles {
+		titleFrame := core.NewFrame(headFrame)
+		titleFrame.Styler(func(s *styles.Style) {
+			s.Min.X.Dp(120)
+		})
+		core.NewText(titleFrame).SetType(core.TextLabelLarge).SetText(title)
+	}
+}
@@ -3,7 +3,6 @@ package gui
 import (
 	"context"
 	"strconv"
-	"strings"
 	"time"
 
 	"table-app/conf"
@@ -14,6 +13,7 @@ import (
 
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
 	"cogentcore.org/core/styles"
 	"cogentcore.org/core/styles/units"
 	"cogentcore.org/core/tree"
@@ -27,6 +27,10 @@ type App struct {
 	settings   conf.Setting
 	updater    *Updater
 	sumUpdater *SumUpdater
+	data       *domain.GuiTableData
+
+	// collapsed - id родительских категорий, подкатегории которых скрыты
+	collapsed map[string]bool
 
 	frames    []*core.Frame
 	txtFields []*core.TextField
@@ -42,7 +46,7 @@ func NewApp(logger log.Logger, cfg Config, controller TableController, settings
 		s.Pos.X.Dp(0)
 	})
 
-	updater := NewUpdater(logger)
+	updater := NewUpdater(logger, settings.GetBaseCurrency(), controller)
 	sumUpdater := NewSumUpdater(logger, controller)
 
 	body.OnClose(func(e events.Event) {
@@ -61,20 +65,25 @@ func NewApp(logger log.Logger, cfg Config, controller TableController, settings
 		logger.Info(ctx, "close completed")
 	})
 
-	return &App{
+	a := &App{
 		logger:     logger,
 		appBody:    body,
 		controller: controller,
 		settings:   settings,
 		updater:    updater,
 		sumUpdater: sumUpdater,
+		collapsed:  make(map[string]bool),
 		frames:     []*core.Frame{},
 		txtFields:  []*core.TextField{},
 		texts:      []*core.Text{},
 	}
+	updater.OnUpdate(a.refreshRollUps)
+
+	return a
 }
 
 func (a *App) Upgrade(data *domain.GuiTableData) {
+	a.data = data
 	a.createToolbar(data.Categories)
 
 	mainFrame := a.withFrame(a.appBody)
@@ -94,6 +103,16 @@ func (a *App) Run() {
 	a.appBody.RunMainWindow()
 }
 
+// RefreshCell
+// обновляет ячейку и суммы ее месяца после изменения не из gui, например планировщиком платежей
+func (a *App) RefreshCell(cell domain.Cell) {
+	a.updater.Send(cell)
+	a.sumUpdater.Send(entity.MonthYear{
+		Month: int(cell.Month),
+		Year:  cell.Year,
+	})
+}
+
 func (a *App) Shutdown() {
 	a.appBody.Scene.RenderWindow().SystemWindow.CloseReq()
 	a.appBody.Close()
@@ -124,12 +143,12 @@ func (a *App) DrawYearTable(year int, frame *core.Frame, data *domain.GuiTableDa
 	})
 	_ = a.withText(yearFrame, strconv.Itoa(year)+" год")
 
-	_ = a.getTableHead(tableFrame, data)
+	_ = a.getTableHead(year, tableFrame, data)
 	_ = a.getMonthsColumn(year, tableFrame)
 	_ = a.getValuesFrame(year, tableFrame, data)
 }
 
-func (a *App) getTableHead(frame *core.Frame, data *domain.GuiTableData) *core.Frame {
+func (a *App) getTableHead(year int, frame *core.Frame, data *domain.GuiTableData) *core.Frame {
 	ctx := context.Background()
 	headFrame := a.withFrame(frame)
 	headFrame.SetName("headFrame")
@@ -172,11 +191,24 @@ func (a *App) getTableHead(frame *core.Frame, data *domain.GuiTableData) *core.F
 		})
 
 		bottomFrame.Maker(func(p *tree.Plan) {
+			categoryTree := domain.NewCategoryTree(data.Categories[i])
+
 			// проходим по категориям главной категории, добавляем ячейки
 			for j, category := range data.Categories[i] {
+				if a.isHidden(categoryTree, category, year) {
+					continue
+				}
+
 				nameLen := len([]rune(data.Categories[i][j].Name))
+				isParent := categoryTree.HasChildren(category.Id)
+
+				// у родительской категории другой набор виджетов, поэтому и другое имя узла
+				nodeName := "cat_" + data.Categories[i][j].Name
+				if isParent {
+					nodeName += "_parent"
+				}
 
-				tree.AddAt(p, "cat_"+data.Categories[i][j].Name, func(frame *core.Frame) {
+				tree.AddAt(p, nodeName, func(frame *core.Frame) {
 					frame.Styler(func(s *styles.Style) {
 						s.Gap.Zero()
 						s.Max.X.Dp(a.getCellSizeDpX(nameLen))
@@ -185,6 +217,10 @@ func (a *App) getTableHead(frame *core.Frame, data *domain.GuiTableData) *core.F
 						s.CenterAll()
 					})
 
+					if isParent {
+						a.addCollapseButton(frame, category)
+					}
+
 					tField := core.NewTextField(frame)
 					tField.Type = core.TextFieldOutlined
 					tField.Styler(func(s *styles.Style) {
@@ -193,8 +229,13 @@ func (a *App) getTableHead(frame *core.Frame, data *domain.GuiTableData) *core.F
 						s.Border.Offset.Zero()
 					})
 					core.Bind(&data.Categories[i][j].Name, tField.SetText(data.Categories[i][j].Name))
+					tField.AddContextMenu(func(m *core.Scene) {
+						a.categoryMenu(m, category)
+					})
 
-					if nameLen > 15 {
+					if ancestors := categoryTree.Ancestors(category); len(ancestors) != 0 {
+						tField.SetTooltip(ancestors[0].Name + " → " + data.Categories[i][j].Name)
+					} else if nameLen > 15 {
 						tField.SetTooltip(data.Categories[i][j].Name)
 					}
 
@@ -251,15 +292,8 @@ func (a *App) getMonthsColumn(year int, frame *core.Frame) *core.Frame {
 		s.Gap.Zero()
 	})
 
-	var lastMonth time.Month
-	if year != time.Now().Year() {
-		// если год не текущий
-		lastMonth = time.December
-	} else {
-		lastMonth = time.Now().Month()
-	}
-
-	for month := 1; month <= int(lastMonth); month++ {
+	// текущий год выводится целиком, чтобы будущие месяцы можно было планировать
+	for month := 1; month <= int(time.December); month++ {
 		monthFrame := a.withFrame(mainFrame)
 		monthFrame.SetName(time.Month(month).String() + "_frame")
 		monthFrame.Styler(func(s *styles.Style) {
@@ -270,7 +304,7 @@ func (a *App) getMonthsColumn(year int, frame *core.Frame) *core.Frame {
 			s.Border.Width.Bottom.Dp(1)
 			s.CenterAll()
 
-			if year == time.Now().Year() && month == int(lastMonth) {
+			if year == time.Now().Year() && month == int(time.Now().Month()) {
 				s.Background = ColorYellow
 			}
 		})
@@ -283,10 +317,6 @@ func (a *App) getMonthsColumn(year int, frame *core.Frame) *core.Frame {
 		core.NewText(monthFrame).SetText(monthName)
 	}
 
-	if year == time.Now().Year() {
-		return mainFrame
-	}
-
 	// строка итогов года
 	resultFrame := a.withFrame(mainFrame)
 	resultFrame.SetName("resultFrame")
@@ -319,15 +349,7 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 		s.Gap.Zero()
 	})
 
-	var lastMonth time.Month
-	if year != time.Now().Year() {
-		// если год не текущий
-		lastMonth = time.December
-	} else {
-		lastMonth = time.Now().Month()
-	}
-
-	for month := 1; month <= int(lastMonth); month++ {
+	for month := 1; month <= int(time.December); month++ {
 		monthFrame := a.withFrame(mainFrame)
 		monthFrame.SetName(time.Month(month).String() + "_frame")
 		monthFrame.Styler(func(s *styles.Style) {
@@ -350,17 +372,37 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 			})
 
 			mainCategFrame.Maker(func(p *tree.Plan) {
+				categoryTree := domain.NewCategoryTree(data.Categories[i])
+
 				for j, category := range data.Categories[i] {
+					if a.isHidden(categoryTree, category, year) {
+						continue
+					}
+
 					ctx := context.Background()
-					compositeId := category.MainCategory + category.Name + strconv.Itoa(month) + strconv.Itoa(year)
+					key := category.CellKey(time.Month(month), year)
+
+					if !category.IsActiveIn(time.Month(month), year) {
+						tree.AddAt(p, key.String()+"_archived", func(frame *core.Frame) {
+							a.addArchivedField(frame, category)
+						})
+						continue
+					}
+
+					if categoryTree.HasChildren(category.Id) {
+						tree.AddAt(p, key.String()+"_rollUp", func(frame *core.Frame) {
+							a.addRollUpField(frame, category, key, month, year)
+						})
+						continue
+					}
 
 					cellIsCreated := false
-					cell, ok := a.controller.GetCellById(compositeId)
+					cell, ok := a.controller.GetCellById(key)
 					if ok {
 						cellIsCreated = true
 					}
 
-					tree.AddAt(p, compositeId, func(frame *core.Frame) {
+					tree.AddAt(p, key.String(), func(frame *core.Frame) {
 						nameLen := len([]rune(data.Categories[i][j].Name))
 
 						frame.Styler(func(s *styles.Style) {
@@ -372,18 +414,18 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 						})
 
 						tField := core.NewTextField(frame)
-						tField.SetName(compositeId + "_tField")
+						tField.SetName(key.String() + "_tField")
 						tField.Type = core.TextFieldOutlined
 						tField.Styler(func(s *styles.Style) {
 							s.Border.Radius.Zero()
 							s.Border.Width.Zero()
 							s.Border.Offset.Zero()
 						})
-						a.updater.AddTextField(compositeId, tField)
+						a.updater.AddTextField(key, tField)
 
 						tField.OnDoubleClick(func(e events.Event) {
 							// проверка через кеш
-							cell, ok = a.controller.GetCellById(compositeId)
+							cell, ok = a.controller.GetCellById(key)
 							if ok {
 								cellIsCreated = true
 							}
@@ -391,63 +433,86 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 								cell = domain.Cell{
 									MainCategory: category.MainCategory,
 									Category:     category.Name,
+									Currency:     category.Currency,
 									Month:        time.Month(month),
 									Year:         year,
 								}
 							}
 
-							sumWindow := NewSumWindow(a.logger, frame, cell, a.controller,
-								a.updater.updateChan, a.sumUpdater.updateChan)
+							sumWindow := NewSumWindow(a.logger, frame, cell, a.controller, a.data.Categories,
+								a.settings, a.updater, a.sumUpdater)
 							sumWindow.Run(tField)
 						})
 
 						tField.OnChange(func(e events.Event) {
-							val, err := strconv.Atoi(strings.Join(strings.Fields(tField.Text()), ""))
+							input, err := parseCellInput(tField.Text())
 							if err != nil {
 								core.MessageSnackbar(mainFrame, "Неверный формат данных: "+err.Error())
-								a.logger.Error(ctx, "convert tField to int: "+err.Error())
+								a.logger.Error(ctx, "convert tField to money: "+err.Error())
 								return
 							}
 
-							if !cellIsCreated {
-								cell = domain.Cell{
+							if input.hasActual {
+								if !cellIsCreated {
+									cell = domain.Cell{
+										MainCategory: category.MainCategory,
+										Category:     category.Name,
+										Currency:     category.Currency,
+										Value:        input.actual,
+										Month:        time.Month(month),
+										Year:         year,
+									}
+								} else {
+									// при наличии операций разница записывается корректирующей операцией
+									cell.SetValue(input.actual, cell.DefaultDate())
+								}
+
+								err = a.controller.UpsertValue(ctx, cell)
+								if err != nil {
+									core.MessageSnackbar(mainFrame, "Ошибка сохранения данных: "+err.Error())
+									a.logger.Error(ctx, "save all data")
+									return
+								}
+								cellIsCreated = true
+								a.refreshRollUps(cell)
+
+								a.sumUpdater.Send(entity.MonthYear{
+									Month: month,
+									Year:  year,
+								})
+							}
+
+							if input.hasPlan {
+								currency := category.Currency
+								if cellIsCreated && len(cell.Currency) != 0 {
+									currency = cell.Currency
+								}
+
+								err = a.controller.UpsertPlan(ctx, domain.Plan{
 									MainCategory: category.MainCategory,
 									Category:     category.Name,
-									Value:        val,
 									Month:        time.Month(month),
 									Year:         year,
+									Value:        input.plan,
+									Currency:     currency,
+								})
+								if err != nil {
+									core.MessageSnackbar(mainFrame, "Ошибка сохранения плана: "+err.Error())
+									a.logger.Error(ctx, "upsert plan", log.Any("err", err.Error()))
+									return
 								}
-							} else {
-								cell.Value = val
-							}
-
-							err = a.controller.UpsertValue(ctx, cell)
-							if err != nil {
-								core.MessageSnackbar(mainFrame, "Ошибка сохранения данных: "+err.Error())
-								a.logger.Error(ctx, "save all data")
-								return
 							}
 
-							a.sumUpdater.updateChan <- entity.MonthYear{
-								Month: month,
-								Year:  year,
-							}
-
-							tField.SetText(FormatInt(val))
+							a.setCellText(tField, key, cell, cellIsCreated, category)
 							core.MessageSnackbar(mainFrame, "Введено: "+tField.Text())
 						})
 
-						if !cellIsCreated {
-							tField.SetText("")
-							return
-						}
-
-						if cell.Category != category.Name {
+						if cellIsCreated && cell.Category != category.Name {
 							a.logger.Error(context.Background(), "bad cell month value", log.String("category", cell.Category))
 							return
 						}
 
-						tField.SetText(FormatInt(cell.Value))
+						a.setCellText(tField, key, cell, cellIsCreated, category)
 					})
 				}
 			})
@@ -480,10 +545,6 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 		a.sumUpdater.AddBalanceText(month, year, balanceText)
 	}
 
-	if year == time.Now().Year() {
-		return mainFrame
-	}
-
 	// строка итогов года
 	resultFrame := a.withFrame(mainFrame)
 	resultFrame.SetName("resultFrame")
@@ -492,7 +553,10 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 		s.CenterAll()
 	})
 
-	resultByCategoryId := a.controller.GetAnnualResult(year)
+	resultByCategoryId, err := a.controller.GetAnnualResult(year)
+	if err != nil {
+		a.logger.Error(context.Background(), "get annual result", log.Any("err", err.Error()))
+	}
 
 	for i, categories := range data.Categories {
 		mainCategFrame := core.NewFrame(resultFrame)
@@ -506,7 +570,13 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 		})
 
 		mainCategFrame.Maker(func(p *tree.Plan) {
+			categoryTree := domain.NewCategoryTree(data.Categories[i])
+
 			for j, category := range data.Categories[i] {
+				if a.isHidden(categoryTree, category, year) {
+					continue
+				}
+
 				compositeCategory := utils.GetCompositeCategory(category.MainCategory, category.Name)
 
 				tree.AddAt(p, compositeCategory, func(frame *core.Frame) {
@@ -527,15 +597,7 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 						s.Font.Weight = styles.WeightBold
 					})
 
-					var result int
-					var ok bool
-
-					result, ok = resultByCategoryId[compositeCategory]
-					if !ok {
-						result = 0
-					}
-
-					textResult.SetText(FormatInt(result))
+					textResult.SetText(formatResult(resultByCategoryId[compositeCategory]))
 				})
 			}
 		})
@@ -552,8 +614,8 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 		s.CenterAll()
 	})
 
-	consumptionRes := resultByCategoryId[domain.ColumnConsumption]
-	core.NewText(consResFrame).SetText(FormatInt(consumptionRes, addMinus)).Styler(func(s *styles.Style) {
+	consumptionRes := resultByCategoryId[domain.ColumnConsumption].Actual
+	core.NewText(consResFrame).SetText(FormatMoney(consumptionRes, addMinus)).Styler(func(s *styles.Style) {
 		s.Font.Weight = styles.WeightBold
 	})
 
@@ -568,8 +630,17 @@ func (a *App) getValuesFrame(year int, frame *core.Frame, data *domain.GuiTableD
 		s.CenterAll()
 	})
 
-	balanceRes := resultByCategoryId[domain.ColumnBalance]
-	core.NewText(balanceResFrame).SetText(FormatInt(balanceRes)).Styler(func(s *styles.Style) {
+	balanceRes := resultByCategoryId[domain.ColumnBalance].Actual
+	balanceText := FormatMoney(balanceRes)
+	// остаток долга по кредитам на конец года выводится под остатком
+	if debtRes := resultByCategoryId[domain.ColumnDebt].Actual; debtRes != 0 {
+		balanceText += "\nДолг: " + FormatMoney(debtRes)
+	}
+	// капитал выводится, если есть оценки активов, долг или расхождение сверки
+	if netWorthRes := resultByCategoryId[domain.ColumnNetWorth].Actual; netWorthRes != balanceRes {
+		balanceText += "\nКапитал: " + FormatMoney(netWorthRes)
+	}
+	core.NewText(balanceResFrame).SetText(balanceText).Styler(func(s *styles.Style) {
 		s.Font.Weight = styles.WeightBold
 	})
 
@@ -594,18 +665,99 @@ func (a *App) createToolbar(categories [][]domain.Category) {
 				core.MessageSnackbar(a.appBody, "-Данные сохранены-")
 			})
 		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Резервная копия")
+			w.OnClick(func(e events.Event) {
+				backupWindow := NewBackupWindow(a.logger, a.appBody, a.controller)
+				backupWindow.Run()
+			})
+		})
 		tree.Add(p, func(w *core.Button) {
 			w.SetText("Новая категория")
 			w.OnClick(func(e events.Event) {
-				categoryWindow := NewCategoryWindow(a.logger, a.appBody, a.controller, categories)
+				categoryWindow := NewCategoryWindow(a.logger, a.appBody, a.controller, categories, a.settings.Currencies)
 				categoryWindow.Run()
 			})
 		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Курсы валют")
+			w.OnClick(func(e events.Event) {
+				rateWindow := NewRateWindow(a.logger, a.appBody, a.controller, a.settings, a.sumUpdater)
+				rateWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Регулярные платежи")
+			w.OnClick(func(e events.Event) {
+				recurringWindow := NewRecurringWindow(a.logger, a.appBody, a.controller, categories, a.RefreshCell)
+				recurringWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Кредиты")
+			w.OnClick(func(e events.Event) {
+				loanWindow := NewLoanWindow(a.logger, a.appBody, a.controller, categories, a.RefreshCell)
+				loanWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Капитал")
+			w.OnClick(func(e events.Event) {
+				netWorthWindow := NewNetWorthWindow(a.logger, a.appBody, a.controller, a.settings.StartYear)
+				netWorthWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Получатели")
+			w.OnClick(func(e events.Event) {
+				payeeWindow := NewPayeeWindow(a.logger, a.appBody, a.controller, categories, a.settings.StartYear)
+				payeeWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Счета")
+			w.OnClick(func(e events.Event) {
+				accountWindow := NewAccountWindow(a.logger, a.appBody, a.controller, a.sumUpdater)
+				accountWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Сверка остатка")
+			w.OnClick(func(e events.Event) {
+				checkpointWindow := NewCheckpointWindow(a.logger, a.appBody, a.controller, a.sumUpdater,
+					func(cell domain.Cell) {
+						// категория "Неучтенное" могла быть только что создана
+						a.appBody.Update()
+						a.RefreshCell(cell)
+					})
+				checkpointWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Цели")
+			w.OnClick(func(e events.Event) {
+				goalWindow := NewGoalWindow(a.logger, a.appBody, a.controller, categories)
+				goalWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Button) {
+			w.SetText("Итоги по периодам")
+			w.OnClick(func(e events.Event) {
+				periodWindow := NewPeriodWindow(a.logger, a.appBody, a.controller, categories, a.settings)
+				periodWindow.Run()
+			})
+		})
+		tree.Add(p, func(w *core.Switch) {
+			w.SetText("Остаток по счетам")
+			w.OnChange(func(e events.Event) {
+				a.sumUpdater.SetShowAccounts(w.IsChecked())
+			})
+		})
 		tree.Add(p, func(w *core.Stretch) {
 			w.SetName("stretch")
 		})
 		tree.Add(p, func(w *core.Text) {
-			w.SetText("Начальная сумма: " + FormatInt(a.settings.StartMoney))
+			w.SetText("Начальная сумма: " + FormatMoney(a.settings.StartMoney))
 
 			w.OnClick(func(e events.Event) {
 				core.MessageSnackbar(a.appBody, "Начальная сумма задается в настройках")
@@ -616,6 +768,238 @@ func (a *App) createToolbar(categories [][]domain.Category) {
 	a.toolBar = tbar
 }
 
+// isHidden
+// категория скрыта, если свернут любой из ее родителей
+// или она либо ее родитель архивированы до начала года
+func (a *App) isHidden(categoryTree domain.CategoryTree, category domain.Category, year int) bool {
+	if !category.IsShownInYear(year) {
+		return true
+	}
+
+	for _, parent := range categoryTree.Ancestors(category) {
+		if a.collapsed[parent.Id] || !parent.IsShownInYear(year) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// categoryMenu
+// контекстное меню категории: удаление и архивирование
+func (a *App) categoryMenu(m *core.Scene, category domain.Category) {
+	ctx := context.Background()
+
+	core.NewButton(m).SetText("Удалить категорию").SetIcon(icons.Delete).OnClick(func(e events.Event) {
+		deleteWindow := NewDeleteCategoryWindow(a.logger, a.appBody, a.controller, a.data.Categories, category,
+			func() {
+				a.sumUpdater.RefreshAll()
+				a.appBody.Update()
+			})
+		deleteWindow.Run()
+	})
+
+	core.NewButton(m).SetText("Переместить выше").SetIcon(icons.ArrowUpward).OnClick(func(e events.Event) {
+		a.reorderCategory(category, -1)
+	})
+	core.NewButton(m).SetText("Переместить ниже").SetIcon(icons.ArrowDownward).OnClick(func(e events.Event) {
+		a.reorderCategory(category, 1)
+	})
+
+	for _, categories := range a.data.Categories {
+		mainCategory := categories[0].MainCategory
+		if mainCategory == category.MainCategory {
+			continue
+		}
+
+		core.NewButton(m).SetText("Перенести в \"" + mainCategory + "\"").SetIcon(icons.DriveFileMove).
+			OnClick(func(e events.Event) {
+				err := a.controller.ChangeMainCategory(ctx, category, mainCategory)
+				if err != nil {
+					a.logger.Error(ctx, "change main category error", log.Any("err", err.Error()))
+					core.MessageSnackbar(a.appBody, "Ошибка переноса категории: "+err.Error())
+					return
+				}
+
+				a.sumUpdater.RefreshAll()
+				a.appBody.Update()
+			})
+	}
+
+	// в замыкании может быть устаревшая копия категории
+	current, ok := a.findCategory(category.Id)
+	if ok && current.IsArchived() {
+		core.NewButton(m).SetText("Вернуть из архива").SetIcon(icons.Unarchive).OnClick(func(e events.Event) {
+			err := a.controller.RestoreCategory(ctx, current)
+			if err != nil {
+				a.logger.Error(ctx, "restore category error", log.Any("err", err.Error()))
+				core.MessageSnackbar(a.appBody, "Ошибка возврата категории из архива")
+			}
+
+			a.appBody.Update()
+		})
+		return
+	}
+
+	core.NewButton(m).SetText("В архив со следующего месяца").SetIcon(icons.Archive).OnClick(func(e events.Event) {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+		err := a.controller.ArchiveCategory(ctx, category, next.Month(), next.Year())
+		if err != nil {
+			a.logger.Error(ctx, "archive category error", log.Any("err", err.Error()))
+			core.MessageSnackbar(a.appBody, "Ошибка архивирования категории")
+		}
+
+		a.appBody.Update()
+	})
+}
+
+// reorderCategory
+// сдвиг категории среди соседей с тем же родителем
+func (a *App) reorderCategory(category domain.Category, shift int) {
+	ctx := context.Background()
+
+	err := a.controller.ReorderCategory(ctx, category, shift)
+	if err != nil {
+		a.logger.Error(ctx, "reorder category error", log.Any("err", err.Error()))
+		core.MessageSnackbar(a.appBody, "Ошибка перемещения категории")
+		return
+	}
+
+	a.appBody.Update()
+}
+
+// findCategory
+// актуальная категория по id
+func (a *App) findCategory(id string) (domain.Category, bool) {
+	for _, categories := range a.data.Categories {
+		for _, category := range categories {
+			if category.Id == id {
+				return category, true
+			}
+		}
+	}
+
+	return domain.Category{}, false
+}
+
+// addArchivedField
+// пустая ячейка архивной категории в месяцах после архивации
+func (a *App) addArchivedField(frame *core.Frame, category domain.Category) {
+	nameLen := len([]rune(category.Name))
+
+	frame.Styler(func(s *styles.Style) {
+		s.Gap.Zero()
+		s.Min.X.Dp(a.getCellSizeDpX(nameLen))
+		s.Max.X.Dp(a.getCellSizeDpX(nameLen))
+		s.Min.Y.Dp(a.settings.Gui.CellSizeDpY)
+		s.Max.Y.Dp(a.settings.Gui.CellSizeDpY)
+		s.Border.Width.SetAll(units.Dp(1))
+		s.Background = ColorSoftGrey
+	})
+	frame.SetTooltip("Категория в архиве")
+}
+
+// addCollapseButton
+// кнопка сворачивания и разворачивания подкатегорий в заголовке родительской категории
+func (a *App) addCollapseButton(frame *core.Frame, category domain.Category) {
+	button := core.NewButton(frame).SetType(core.ButtonAction)
+	button.Updater(func() {
+		if a.collapsed[category.Id] {
+			button.SetIcon(icons.KeyboardArrowRight).SetTooltip("Развернуть подкатегории")
+		} else {
+			button.SetIcon(icons.KeyboardArrowDown).SetTooltip("Свернуть подкатегории")
+		}
+	})
+	button.OnClick(func(e events.Event) {
+		a.collapsed[category.Id] = !a.collapsed[category.Id]
+		a.appBody.Update()
+	})
+}
+
+// addRollUpField
+// ячейка родительской категории: сумма вместе с подкатегориями, изменяется только через подкатегории
+func (a *App) addRollUpField(frame *core.Frame, category domain.Category, key domain.CellKey, month, year int) {
+	nameLen := len([]rune(category.Name))
+
+	frame.Styler(func(s *styles.Style) {
+		s.Gap.Zero()
+		s.Max.X.Dp(a.getCellSizeDpX(nameLen))
+		s.Max.Y.Dp(a.settings.Gui.CellSizeDpY)
+		s.Border.Width.SetAll(units.Dp(1))
+		s.CenterAll()
+	})
+
+	tField := core.NewTextField(frame)
+	tField.SetName(key.String() + "_rollUp")
+	tField.Type = core.TextFieldOutlined
+	tField.SetReadOnly(true)
+	tField.SetTooltip("Сумма с подкатегориями")
+	tField.Styler(func(s *styles.Style) {
+		s.Border.Radius.Zero()
+		s.Border.Width.Zero()
+		s.Border.Offset.Zero()
+		s.Font.Weight = styles.WeightBold
+	})
+	a.updater.AddRollUpField(key, tField)
+
+	sum, err := a.controller.GetCategoryRollUp(category, month, year)
+	if err != nil {
+		a.logger.Error(context.Background(), "get category roll-up", log.Any("err", err.Error()))
+	}
+	tField.SetText(FormatMoney(sum))
+}
+
+// refreshRollUps
+// пересчитывает суммы родительских категорий после изменения ячейки
+func (a *App) refreshRollUps(cell domain.Cell) {
+	if a.data == nil {
+		return
+	}
+
+	categories := make([]domain.Category, 0)
+	for _, mainCategory := range a.data.Categories {
+		categories = append(categories, mainCategory...)
+	}
+
+	categoryTree := domain.NewCategoryTree(categories)
+	category, ok := categoryTree.FindByName(cell.MainCategory, cell.Category)
+	if !ok {
+		return
+	}
+
+	for _, parent := range categoryTree.Ancestors(category) {
+		sum, err := a.controller.GetCategoryRollUp(parent, int(cell.Month), cell.Year)
+		if err != nil {
+			a.logger.Error(context.Background(), "get category roll-up", log.Any("err", err.Error()))
+		}
+
+		a.updater.SetRollUpText(parent.CellKey(cell.Month, cell.Year), FormatMoney(sum))
+	}
+}
+
+// setCellText
+// выводит в ячейку факт и план, если он задан; отклонение от плана выводится в подсказке
+func (a *App) setCellText(tField *core.TextField, key domain.CellKey, cell domain.Cell, cellIsCreated bool,
+	category domain.Category) {
+	actual := ""
+	if cellIsCreated {
+		actual = FormatMoney(cell.Value, a.currencyOption(cell, category))
+	}
+
+	plan, hasPlan := a.controller.GetPlanById(key)
+	tField.SetText(formatCellText(actual, plan, hasPlan))
+	tField.SetTooltip(planTooltip(cell.Value, plan, hasPlan))
+}
+
+// currencyOption
+// подпись валюты для ячеек не в базовой валюте
+func (a *App) currencyOption(cell domain.Cell, category domain.Category) Option {
+	base := a.settings.GetBaseCurrency()
+	return withCurrency(domain.ResolveCurrency(cell.Currency, category.Currency, base), base)
+}
+
 func (a *App) getCellSizeDpX(nameLen int) float32 {
 	if nameLen < 8 {
 		return 80
@@ -0,0 +1,151 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+// attachmentExtensions - фильтр выбора файла вложения
+const attachmentExtensions = ".jpg,.jpeg,.png,.gif,.webp,.bmp,.pdf"
+
+// AttachmentList
+// список вложений ячейки и ее операций: открытие в программе просмотра, удаление и прикрепление;
+// файл копируется в хранилище сразу, удаление из хранилища происходит при сохранении данных
+type AttachmentList struct {
+	logger    log.Logger
+	dialog    *core.Body
+	listFrame *core.Frame
+
+	controller   TableController
+	key          domain.CellKey
+	transactions func() []domain.Transaction
+}
+
+func NewAttachmentList(logger log.Logger, dialog *core.Body, parent *core.Frame, controller TableController,
+	key domain.CellKey, transactions func() []domain.Transaction) *AttachmentList {
+	attachmentList := &AttachmentList{
+		logger:       logger,
+		dialog:       dialog,
+		controller:   controller,
+		key:          key,
+		transactions: transactions,
+	}
+
+	core.NewText(parent).SetType(core.TextLabelLarge).SetText("Вложения")
+
+	attachmentList.listFrame = core.NewFrame(parent)
+	attachmentList.listFrame.SetName("attachmentListFrame")
+	attachmentList.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	attachmentList.listFrame.Maker(func(p *tree.Plan) {
+		for _, item := range controller.GetAttachments(key) {
+			tree.AddAt(p, "attachment_"+item.Id, func(row *core.Frame) {
+				attachmentList.addAttachmentRow(row, item)
+			})
+		}
+	})
+
+	attachmentList.AddAttachButton(parent, "", "Прикрепить файл")
+
+	return attachmentList
+}
+
+func (s *AttachmentList) addAttachmentRow(row *core.Frame, item domain.Attachment) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	textFrame := core.NewFrame(row)
+	textFrame.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(180)
+		s.Direction = styles.Column
+	})
+	core.NewText(textFrame).SetText(item.Name)
+	core.NewText(textFrame).SetType(core.TextBodySmall).SetText(s.targetText(item))
+
+	openButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.OpenInNew)
+	openButton.SetTooltip("Открыть")
+	openButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		path, err := s.controller.AttachmentPath(ctx, item.Id)
+		if err != nil {
+			core.MessageSnackbar(s.dialog, "Ошибка открытия вложения: "+err.Error())
+			s.logger.Error(ctx, "attachment path error", log.Any("err", err.Error()))
+			return
+		}
+
+		// программа просмотра запускается синхронно, поэтому окно не должно ее ждать
+		go core.TheApp.OpenURL("file://" + path)
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить вложение")
+	deleteButton.OnClick(func(e events.Event) {
+		s.controller.DeleteAttachment(context.Background(), item.Id)
+		s.listFrame.Update()
+	})
+}
+
+// targetText
+// к чему относится вложение и его размер: ячейка или операция с датой и суммой
+func (s *AttachmentList) targetText(item domain.Attachment) string {
+	size := ", " + strconv.FormatInt((item.Size+1023)/1024, 10) + " КБ"
+	if len(item.TransactionId) == 0 {
+		return "Ячейка" + size
+	}
+
+	for _, transaction := range s.transactions() {
+		if transaction.Id == item.TransactionId && !transaction.IsDeleted {
+			return "Операция " + transaction.Date.Format(transactionDateLayout) + " " +
+				FormatMoney(transaction.Amount) + size
+		}
+	}
+
+	return "Удаленная операция" + size
+}
+
+// AddAttachButton
+// кнопка выбора файла; пустой transactionId - файл прикрепляется к ячейке
+func (s *AttachmentList) AddAttachButton(parent *core.Frame, transactionId, text string) *core.FileButton {
+	fileButton := core.NewFileButton(parent).SetExtensions(attachmentExtensions)
+	fileButton.SetIcon(icons.AttachFile)
+	fileButton.SetTooltip("Прикрепить чек или счет: изображение или pdf")
+	// кнопка показывает не выбранный файл, а действие
+	fileButton.Updater(func() {
+		fileButton.SetText(text)
+	})
+	if len(text) == 0 {
+		fileButton.SetType(core.ButtonAction)
+	}
+
+	fileButton.OnChange(func(e events.Event) {
+		ctx := context.Background()
+
+		if len(fileButton.Filename) == 0 {
+			return
+		}
+
+		_, err := s.controller.AddAttachment(ctx, s.key, transactionId, fileButton.Filename)
+		if err != nil {
+			core.MessageSnackbar(s.dialog, "Ошибка прикрепления файла: "+err.Error())
+			s.logger.Error(ctx, "add attachment error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.listFrame.Update()
+	})
+
+	return fileButton
+}
@@ -0,0 +1,103 @@
+package gui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+)
+
+// backupFileLayout - имя архива резервной копии по умолчанию, с датой
+const backupFileLayout = "backup_20060102.zip"
+
+// BackupWindow
+// окно резервной копии: данные сохраняются и архивируются в zip вместе с вложениями
+type BackupWindow struct {
+	logger       log.Logger
+	appBody      *core.Body
+	backupDialog *core.Body
+
+	controller TableController
+}
+
+func NewBackupWindow(logger log.Logger, appBody *core.Body, controller TableController) *BackupWindow {
+	backupBody := core.NewBody("Backup").SetTitle("Резервная копия")
+	backupBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	backupWindow := &BackupWindow{
+		logger:       logger,
+		appBody:      appBody,
+		backupDialog: backupBody,
+		controller:   controller,
+	}
+
+	mainFrame := core.NewFrame(backupBody)
+	mainFrame.SetName("mainBackupFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Перед архивированием все данные сохраняются; в архив попадают файлы данных и все вложения")
+
+	backupWindow.addBackup(mainFrame)
+
+	return backupWindow
+}
+
+func (s *BackupWindow) addBackup(mainFrame *core.Frame) {
+	settingFrame := core.NewFrame(mainFrame)
+	settingFrame.SetName("settingFrame")
+	settingFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	pathField := core.NewTextField(settingFrame).SetText(time.Now().Format(backupFileLayout))
+	pathField.SetTooltip("Файл архива")
+
+	cancelButton := core.NewButton(settingFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	backupButton := core.NewButton(settingFrame).SetType(core.ButtonFilled).SetIcon(icons.Backup).
+		SetText("Создать")
+	backupButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		filePath := strings.TrimSpace(pathField.Text())
+		if len(filePath) == 0 {
+			core.MessageSnackbar(s.backupDialog, "Не указан файл архива")
+			return
+		}
+
+		err := s.controller.Backup(ctx, filePath)
+		if err != nil {
+			core.MessageSnackbar(s.backupDialog, "Ошибка резервного копирования: "+err.Error())
+			s.logger.Error(ctx, "backup error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		core.MessageSnackbar(s.appBody, "Резервная копия сохранена в "+filePath)
+	})
+}
+
+func (s *BackupWindow) Run() {
+	stage := s.backupDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *BackupWindow) close() {
+	s.backupDialog.Close()
+}
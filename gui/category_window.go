@@ -19,11 +19,13 @@ type CategoryWindow struct {
 	catDialog      *core.Body
 	controller     TableController
 	mainCategories []string
+	categories     [][]domain.Category
+	currencies     []string
 	category       domain.Category
 }
 
 func NewCategoryWindow(logger log.Logger, appBody *core.Body, controller TableController,
-	categories [][]domain.Category) *CategoryWindow {
+	categories [][]domain.Category, currencies []string) *CategoryWindow {
 	catBody := core.NewBody("NewCategory").SetTitle("Добавление категории")
 	catBody.Styler(func(s *styles.Style) {
 		s.Align.Self = styles.Center
@@ -53,6 +55,8 @@ func NewCategoryWindow(logger log.Logger, appBody *core.Body, controller TableCo
 		catDialog:      catBody,
 		controller:     controller,
 		mainCategories: getMainCategories(categories),
+		categories:     categories,
+		currencies:     currencies,
 		category:       domain.Category{},
 	}
 
@@ -91,9 +95,15 @@ func (s *CategoryWindow) addInput(catFrame *core.Frame) {
 	switcher.Styler(func(s *styles.Style) {
 		s.Font.Size.Set(8, units.UnitPt)
 	})
+
+	var parentChooser *core.Chooser
 	switcher.OnChange(func(e events.Event) {
 		if switcher.SelectedItem() != nil {
 			s.category.MainCategory = switcher.SelectedItem().Value.(string)
+			// родитель выбирается только из категорий той же основной категории
+			s.category.ParentId = ""
+			parentChooser.SetItems(s.parentItems()...).SetCurrentValue("")
+			parentChooser.Update()
 		}
 	})
 
@@ -115,6 +125,61 @@ func (s *CategoryWindow) addInput(catFrame *core.Frame) {
 	tField.OnInput(func(e events.Event) {
 		s.category.Name = tField.Text()
 	})
+
+	core.NewSpace(catFrame).Styler(func(s *styles.Style) {
+		s.Min.Y.Dp(10)
+	})
+
+	parentFrame := core.NewFrame(catFrame)
+	parentFrame.SetName("parentFrame")
+	parentFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+	core.NewText(parentFrame).SetType(core.TextBodyLarge).SetText("Родительская категория")
+	parentChooser = core.NewChooser(parentFrame).SetItems(s.parentItems()...).SetCurrentValue("")
+	parentChooser.OnChange(func(e events.Event) {
+		value, ok := parentChooser.CurrentItem.Value.(string)
+		if ok {
+			s.category.ParentId = value
+		}
+	})
+
+	if len(s.currencies) < 2 {
+		return
+	}
+
+	core.NewSpace(catFrame).Styler(func(s *styles.Style) {
+		s.Min.Y.Dp(10)
+	})
+
+	currencyFrame := core.NewFrame(catFrame)
+	currencyFrame.SetName("currencyFrame")
+	currencyFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+	core.NewText(currencyFrame).SetType(core.TextBodyLarge).SetText("Валюта категории")
+	chooser := core.NewChooser(currencyFrame).SetStrings(s.currencies...)
+	chooser.OnChange(func(e events.Event) {
+		value, ok := chooser.CurrentItem.Value.(string)
+		if ok {
+			s.category.Currency = value
+		}
+	})
+}
+
+// parentItems
+// категории выбранной основной категории, которые могут стать родительскими
+func (s *CategoryWindow) parentItems() []core.ChooserItem {
+	items := []core.ChooserItem{{Value: "", Text: "Нет (верхний уровень)"}}
+	for _, categories := range s.categories {
+		for _, category := range categories {
+			if category.MainCategory == s.category.MainCategory && !category.IsArchived() {
+				items = append(items, core.ChooserItem{Value: category.Id, Text: category.Name})
+			}
+		}
+	}
+
+	return items
 }
 
 func (s *CategoryWindow) addButtons(buttonsFrame *core.Frame) {
@@ -0,0 +1,242 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+// CheckpointWindow
+// окно сверок с фактическим остатком: расхождение с расчетом можно провести как неучтенный расход
+type CheckpointWindow struct {
+	logger           log.Logger
+	appBody          *core.Body
+	checkpointDialog *core.Body
+	listFrame        *core.Frame
+
+	controller TableController
+	sumUpdater *SumUpdater
+	onBook     func(cell domain.Cell)
+	rows       []checkpointRow
+
+	// latest - сохраненные последние сверки месяцев, для них выводится расхождение
+	latest map[string]bool
+}
+
+// checkpointRow
+// строка списка сверок; deleted - строка удалена из окна
+type checkpointRow struct {
+	checkpoint domain.Checkpoint
+	deleted    bool
+}
+
+func NewCheckpointWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	sumUpdater *SumUpdater, onBook func(cell domain.Cell)) *CheckpointWindow {
+	checkpointBody := core.NewBody("Checkpoints").SetTitle("Сверка остатка")
+	checkpointBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	checkpoints := controller.GetCheckpoints()
+
+	rows := make([]checkpointRow, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		rows = append(rows, checkpointRow{checkpoint: checkpoint})
+	}
+
+	latest := make(map[string]bool)
+	for _, checkpoint := range domain.LatestCheckpoints(checkpoints) {
+		latest[checkpoint.Id] = true
+	}
+
+	checkpointWindow := &CheckpointWindow{
+		logger:           logger,
+		appBody:          appBody,
+		checkpointDialog: checkpointBody,
+		controller:       controller,
+		sumUpdater:       sumUpdater,
+		onBook:           onBook,
+		rows:             rows,
+		latest:           latest,
+	}
+
+	mainFrame := core.NewFrame(checkpointBody)
+	mainFrame.SetName("mainCheckpointFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Фактический остаток всех средств на конец дня; следующий месяц начинается с последней сверки месяца")
+
+	checkpointWindow.addCheckpointList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	checkpointWindow.addButtons(buttonsFrame)
+
+	return checkpointWindow
+}
+
+func (s *CheckpointWindow) addCheckpointList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Дата", "Остаток", "Комментарий", "Расхождение")
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.rows {
+			if s.rows[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "checkpoint_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addCheckpointRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить сверку")
+	addButton.OnClick(func(e events.Event) {
+		s.rows = append(s.rows, checkpointRow{checkpoint: domain.Checkpoint{
+			Date: time.Now().UTC().Truncate(24 * time.Hour),
+		}})
+		s.listFrame.Update()
+	})
+}
+
+func (s *CheckpointWindow) addCheckpointRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	checkpoint := s.rows[idx].checkpoint
+
+	dateField := core.NewTextField(row).SetText(checkpoint.Date.Format(transactionDateLayout))
+	dateField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	dateField.OnChange(func(e events.Event) {
+		date, err := time.Parse(transactionDateLayout, strings.TrimSpace(dateField.Text()))
+		if err != nil {
+			core.MessageSnackbar(s.checkpointDialog, "Неверная дата, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.rows[idx].checkpoint.Date = date
+	})
+
+	balanceField := core.NewTextField(row).SetPlaceholder("0")
+	if checkpoint.Balance != 0 {
+		balanceField.SetText(checkpoint.Balance.String())
+	}
+	balanceField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	balanceField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(balanceField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.checkpointDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.rows[idx].checkpoint.Balance = value
+	})
+
+	noteField := core.NewTextField(row).SetText(checkpoint.Note)
+	noteField.OnChange(func(e events.Event) {
+		s.rows[idx].checkpoint.Note = strings.TrimSpace(noteField.Text())
+	})
+
+	diffText := "-"
+	diff, ok := s.controller.GetCheckpointDiff(int(checkpoint.Date.Month()), checkpoint.Date.Year())
+	if ok && s.latest[checkpoint.Id] {
+		diffText = FormatMoney(diff)
+	}
+
+	diffFrame := core.NewFrame(row)
+	diffFrame.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(120)
+	})
+	core.NewText(diffFrame).SetText(diffText)
+
+	bookButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Balance)
+	bookButton.SetTooltip("Провести расхождение как \"" + domain.UnaccountedCategory + "\"")
+	bookButton.SetEnabled(ok && diff != 0 && s.latest[checkpoint.Id])
+	bookButton.OnClick(func(e events.Event) {
+		cell, err := s.controller.BookCheckpointDiff(context.Background(), checkpoint)
+		if err != nil {
+			core.MessageSnackbar(s.checkpointDialog, "Ошибка проведения расхождения: "+err.Error())
+			s.logger.Error(context.Background(), "book checkpoint diff error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		s.onBook(cell)
+		core.MessageSnackbar(s.appBody, "Расхождение проведено: "+FormatMoney(-diff))
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить сверку")
+	deleteButton.OnClick(func(e events.Event) {
+		s.rows[idx].deleted = true
+		s.listFrame.Update()
+	})
+}
+
+func (s *CheckpointWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		checkpoints := make([]domain.Checkpoint, 0, len(s.rows))
+		for _, row := range s.rows {
+			if !row.deleted {
+				checkpoints = append(checkpoints, row.checkpoint)
+			}
+		}
+
+		err := s.controller.UpdateCheckpoints(context.Background(), checkpoints)
+		if err != nil {
+			core.MessageSnackbar(s.checkpointDialog, "Ошибка сохранения сверок: "+err.Error())
+			s.logger.Error(context.Background(), "update checkpoints error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		s.sumUpdater.RefreshAll()
+	})
+}
+
+func (s *CheckpointWindow) Run() {
+	stage := s.checkpointDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *CheckpointWindow) close() {
+	s.checkpointDialog.Close()
+}
@@ -0,0 +1,159 @@
+package gui
+
+import (
+	"context"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/styles"
+)
+
+const (
+	deleteCells = "Удалить ячейки"
+	moveCells   = "Объединить с категорией"
+)
+
+// DeleteCategoryWindow
+// окно удаления категории с выбором: удалить ее ячейки или перенести в другую категорию
+type DeleteCategoryWindow struct {
+	logger       log.Logger
+	appBody      *core.Body
+	deleteDialog *core.Body
+
+	controller TableController
+	category   domain.Category
+	targets    []core.ChooserItem
+	moveTo     *domain.Category
+	onDelete   func()
+}
+
+func NewDeleteCategoryWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	categories [][]domain.Category, category domain.Category, onDelete func()) *DeleteCategoryWindow {
+	deleteBody := core.NewBody("DeleteCategory").SetTitle("Удаление категории")
+	deleteBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	// перенести ячейки можно только в категорию той же валюты
+	targets := make([]core.ChooserItem, 0)
+	for _, mainCategory := range categories {
+		for _, target := range mainCategory {
+			if target.Id == category.Id || target.Currency != category.Currency {
+				continue
+			}
+
+			targets = append(targets, core.ChooserItem{
+				Value: target,
+				Text:  target.MainCategory + " / " + target.Name,
+			})
+		}
+	}
+
+	deleteWindow := &DeleteCategoryWindow{
+		logger:       logger,
+		appBody:      appBody,
+		deleteDialog: deleteBody,
+		controller:   controller,
+		category:     category,
+		targets:      targets,
+		onDelete:     onDelete,
+	}
+
+	mainFrame := core.NewFrame(deleteBody)
+	mainFrame.SetName("mainDeleteFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextTitleMedium).
+		SetText("Удалить категорию \"" + category.MainCategory + " / " + category.Name + "\"?")
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("При объединении значения одного месяца складываются, операции переносятся, изменения сразу сохраняются")
+
+	deleteWindow.addChoice(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	deleteWindow.addButtons(buttonsFrame)
+
+	return deleteWindow
+}
+
+func (s *DeleteCategoryWindow) addChoice(mainFrame *core.Frame) {
+	choices := []string{deleteCells}
+	if len(s.targets) != 0 {
+		choices = append(choices, moveCells)
+	}
+
+	switcher := core.NewSwitches(mainFrame).SetType(core.SwitchRadioButton).SetMutex(true).SetStrings(choices...)
+	switcher.SetName("switcherCells")
+	_ = switcher.SelectValue(deleteCells)
+
+	if len(s.targets) == 0 {
+		return
+	}
+
+	chooser := core.NewChooser(mainFrame).SetItems(s.targets...)
+	chooser.SetEnabled(false)
+	chooser.OnChange(func(e events.Event) {
+		target, ok := chooser.CurrentItem.Value.(domain.Category)
+		if ok && s.moveTo != nil {
+			s.moveTo = &target
+		}
+	})
+
+	switcher.OnChange(func(e events.Event) {
+		if switcher.SelectedItem() == nil || switcher.SelectedItem().Value.(string) == deleteCells {
+			s.moveTo = nil
+			chooser.SetEnabled(false).Update()
+			return
+		}
+
+		target, ok := chooser.CurrentItem.Value.(domain.Category)
+		if !ok {
+			target = s.targets[0].Value.(domain.Category)
+		}
+		s.moveTo = &target
+		chooser.SetEnabled(true).Update()
+	})
+}
+
+func (s *DeleteCategoryWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	deleteButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Удалить")
+	deleteButton.OnClick(func(e events.Event) {
+		err := s.controller.DeleteCategory(context.Background(), s.category, s.moveTo)
+		if err != nil {
+			core.MessageSnackbar(s.deleteDialog, "Ошибка удаления категории: "+err.Error())
+			s.logger.Error(context.Background(), "delete category error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		s.onDelete()
+	})
+}
+
+func (s *DeleteCategoryWindow) Run() {
+	stage := s.deleteDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *DeleteCategoryWindow) close() {
+	s.deleteDialog.Close()
+}
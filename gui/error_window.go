@@ -0,0 +1,14 @@
+package gui
+
+import (
+	"cogentcore.org/core/core"
+)
+
+// RunErrorWindow
+// окно с ошибкой запуска, когда основное окно приложения открыть нельзя;
+// возвращает управление, когда пользователь закроет окно
+func RunErrorWindow(title, message string) {
+	body := core.NewBody(NewAppConfig().Title)
+	body.AddTitle(title).AddText(message).AddOKOnly()
+	body.RunMainWindow()
+}
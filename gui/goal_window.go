@@ -0,0 +1,316 @@
+package gui
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/utils"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+// GoalWindow
+// панель целей накопления: процент выполнения и ежемесячный взнос, нужный, чтобы успеть к сроку
+type GoalWindow struct {
+	logger     log.Logger
+	appBody    *core.Body
+	goalDialog *core.Body
+	listFrame  *core.Frame
+
+	controller TableController
+	links      []core.ChooserItem
+	linkTitles map[domain.GoalLink]string
+	progress   map[string]domain.GoalProgress
+	rows       []goalRow
+}
+
+// goalRow
+// строка списка целей; deleted - строка удалена из окна
+type goalRow struct {
+	goal    domain.Goal
+	deleted bool
+}
+
+func NewGoalWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	categories [][]domain.Category) *GoalWindow {
+	goalBody := core.NewBody("Goals").SetTitle("Цели накопления")
+	goalBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	links := make([]core.ChooserItem, 0)
+	linkTitles := make(map[domain.GoalLink]string)
+	for _, mainCategory := range categories {
+		for _, category := range mainCategory {
+			link := domain.GoalLink{
+				Kind:         domain.GoalLinkCategory,
+				MainCategory: category.MainCategory,
+				Category:     category.Name,
+			}
+			linkTitles[link] = category.MainCategory + " / " + category.Name
+			links = append(links, core.ChooserItem{Value: link, Text: linkTitles[link]})
+		}
+	}
+
+	for _, account := range controller.GetAccounts() {
+		link := domain.GoalLink{Kind: domain.GoalLinkAccount, AccountId: account.Id}
+		linkTitles[link] = "Счет " + account.Name
+		links = append(links, core.ChooserItem{Value: link, Text: linkTitles[link]})
+	}
+
+	progress := make(map[string]domain.GoalProgress)
+	progressList, err := controller.GetGoalProgress(time.Now())
+	if err != nil {
+		logger.Error(context.Background(), "get goal progress", log.Any("err", err.Error()))
+	}
+	for _, item := range progressList {
+		progress[item.Goal.Id] = item
+	}
+
+	rows := make([]goalRow, 0)
+	for _, goal := range controller.GetGoals() {
+		rows = append(rows, goalRow{goal: goal})
+	}
+
+	goalWindow := &GoalWindow{
+		logger:     logger,
+		appBody:    appBody,
+		goalDialog: goalBody,
+		controller: controller,
+		links:      links,
+		linkTitles: linkTitles,
+		progress:   progress,
+		rows:       rows,
+	}
+
+	mainFrame := core.NewFrame(goalBody)
+	mainFrame.SetName("mainGoalFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Накоплено - сумма ячеек привязанных категорий по текущий месяц и остатки привязанных счетов")
+
+	goalWindow.addGoalList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	goalWindow.addButtons(buttonsFrame)
+
+	return goalWindow
+}
+
+func (s *GoalWindow) addGoalList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Цель", "Сумма", "Срок", "Выполнено", "Взнос в месяц", "Категории и счета")
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.rows {
+			if s.rows[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "goal_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addGoalRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить цель")
+	addButton.OnClick(func(e events.Event) {
+		s.rows = append(s.rows, goalRow{goal: domain.Goal{
+			Deadline: time.Date(time.Now().Year(), time.December, 31, 0, 0, 0, 0, time.UTC),
+		}})
+		s.listFrame.Update()
+	})
+}
+
+func (s *GoalWindow) addGoalRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	goal := s.rows[idx].goal
+
+	nameField := core.NewTextField(row).SetText(goal.Name).SetPlaceholder("Название")
+	nameField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	nameField.OnChange(func(e events.Event) {
+		s.rows[idx].goal.Name = strings.TrimSpace(nameField.Text())
+	})
+
+	targetField := core.NewTextField(row).SetPlaceholder("0")
+	if goal.Target != 0 {
+		targetField.SetText(goal.Target.String())
+	}
+	targetField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	targetField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(targetField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.goalDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.rows[idx].goal.Target = value
+	})
+
+	deadlineField := core.NewTextField(row).SetText(goal.Deadline.Format(transactionDateLayout))
+	deadlineField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	deadlineField.OnChange(func(e events.Event) {
+		date, err := time.Parse(transactionDateLayout, strings.TrimSpace(deadlineField.Text()))
+		if err != nil {
+			core.MessageSnackbar(s.goalDialog, "Неверная дата, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.rows[idx].goal.Deadline = date
+	})
+
+	s.addProgress(row, goal)
+
+	linksFrame := core.NewFrame(row)
+	linksFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+	linksFrame.Maker(func(p *tree.Plan) {
+		for j, link := range s.rows[idx].goal.Links {
+			tree.AddAt(p, "link_"+strconv.Itoa(j)+"_"+link.Kind+"_"+
+				utils.GetCompositeCategory(link.MainCategory, link.Category)+"_"+strconv.Quote(link.AccountId),
+				func(w *core.Button) {
+					w.SetType(core.ButtonTonal).SetIcon(icons.Close).SetText(s.linkTitle(link))
+					w.SetTooltip("Отвязать")
+					w.OnClick(func(e events.Event) {
+						links := s.rows[idx].goal.Links
+						s.rows[idx].goal.Links = append(links[:j:j], links[j+1:]...)
+						linksFrame.Update()
+					})
+				})
+		}
+	})
+
+	linkChooser := core.NewChooser(row).SetItems(s.links...).SetPlaceholder("Привязать")
+	linkChooser.OnChange(func(e events.Event) {
+		link, ok := linkChooser.CurrentItem.Value.(domain.GoalLink)
+		if !ok || slices.Contains(s.rows[idx].goal.Links, link) {
+			return
+		}
+
+		s.rows[idx].goal.Links = append(s.rows[idx].goal.Links, link)
+		linksFrame.Update()
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить цель")
+	deleteButton.OnClick(func(e events.Event) {
+		s.rows[idx].deleted = true
+		s.listFrame.Update()
+	})
+}
+
+// addProgress
+// процент выполнения и нужный взнос; для новых, еще не сохраненных целей прогресс не считается
+func (s *GoalWindow) addProgress(row *core.Frame, goal domain.Goal) {
+	progressFrame := core.NewFrame(row)
+	progressFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.Min.X.Dp(120)
+		s.CenterAll()
+	})
+
+	monthlyFrame := core.NewFrame(row)
+	monthlyFrame.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(120)
+		s.CenterAll()
+	})
+
+	progress, ok := s.progress[goal.Id]
+	if !ok {
+		core.NewText(progressFrame).SetText("-")
+		core.NewText(monthlyFrame).SetText("-")
+		return
+	}
+
+	core.NewMeter(progressFrame).SetMax(100).SetValue(float32(min(max(progress.Percent, 0), 100)))
+	core.NewText(progressFrame).SetText(strconv.Itoa(progress.Percent) + "% (" + FormatMoney(progress.Saved) + ")")
+
+	monthly := core.NewText(monthlyFrame).SetText(FormatMoney(progress.Monthly))
+	monthly.SetTooltip("Осталось месяцев: " + strconv.Itoa(progress.MonthsLeft))
+}
+
+// linkTitle
+// название привязки; привязка к удаленному счету выводится по id
+func (s *GoalWindow) linkTitle(link domain.GoalLink) string {
+	title, ok := s.linkTitles[link]
+	if ok {
+		return title
+	}
+
+	if link.Kind == domain.GoalLinkAccount {
+		return "Счет " + link.AccountId
+	}
+
+	return link.MainCategory + " / " + link.Category
+}
+
+func (s *GoalWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		goals := make([]domain.Goal, 0, len(s.rows))
+		for _, row := range s.rows {
+			if !row.deleted {
+				goals = append(goals, row.goal)
+			}
+		}
+
+		err := s.controller.UpdateGoals(context.Background(), goals)
+		if err != nil {
+			core.MessageSnackbar(s.goalDialog, "Ошибка сохранения целей: "+err.Error())
+			s.logger.Error(context.Background(), "update goals error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+	})
+}
+
+func (s *GoalWindow) Run() {
+	stage := s.goalDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *GoalWindow) close() {
+	s.goalDialog.Close()
+}
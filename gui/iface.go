@@ -2,21 +2,78 @@ package gui
 
 import (
 	"context"
+	"time"
 
 	"table-app/domain"
+	"table-app/entity"
 )
 
 type TableController interface {
 	UpsertValue(ctx context.Context, cell domain.Cell) error
 	AddCategory(ctx context.Context, category domain.Category) error
 	UpdateCategoryName(ctx context.Context, old, new domain.Category) error
+	DeleteCategory(ctx context.Context, category domain.Category, moveTo *domain.Category) error
+	ArchiveCategory(ctx context.Context, category domain.Category, month time.Month, year int) error
+	RestoreCategory(ctx context.Context, category domain.Category) error
+	ReorderCategory(ctx context.Context, category domain.Category, shift int) error
+	ChangeMainCategory(ctx context.Context, category domain.Category, mainCategory string) error
 	CategoryIsExist(ctx context.Context, category domain.Category) bool
 	SaveAll(ctx context.Context) error
-	GetCellById(compositeId string) (domain.Cell, bool)
+	GetCellById(key domain.CellKey) (domain.Cell, bool)
 
-	GetConsumptionSum(month, year int) int
-	GetBalanceSum(month, year int) (int, error)
-	UpsertBalance(month, year int) (map[string]int, error)
+	GetConsumptionSum(month, year int) (entity.Money, error)
+	GetBalanceSum(month, year int) (entity.Money, error)
+	UpsertBalance(month, year int) (map[entity.MonthYear]entity.Money, error)
 
-	GetAnnualResult(year int) map[string]int
+	GetAnnualResult(year int) (map[string]domain.CategoryResult, error)
+	GetPeriodResults(setting domain.PeriodSetting, from, to time.Time) ([]domain.PeriodResult, error)
+	GetCategoryRollUp(category domain.Category, month, year int) (entity.Money, error)
+
+	UpsertPlan(ctx context.Context, plan domain.Plan) error
+	GetPlanById(key domain.CellKey) (domain.Plan, bool)
+
+	GetRates() []domain.ExchangeRate
+	UpdateRates(ctx context.Context, rates []domain.ExchangeRate) error
+
+	GetAccounts() []domain.Account
+	GetTransfers() []domain.Transfer
+	UpdateAccounts(ctx context.Context, accounts []domain.Account, transfers []domain.Transfer) error
+	GetAccountBalances(month, year int) map[string]entity.Money
+
+	GetRecurring() []domain.Recurring
+	UpdateRecurring(ctx context.Context, items []domain.Recurring) error
+	MaterializeRecurring(ctx context.Context, now time.Time) ([]domain.Cell, error)
+
+	GetCheckpoints() []domain.Checkpoint
+	UpdateCheckpoints(ctx context.Context, items []domain.Checkpoint) error
+	GetCheckpointDiff(month, year int) (entity.Money, bool)
+	BookCheckpointDiff(ctx context.Context, checkpoint domain.Checkpoint) (domain.Cell, error)
+
+	GetGoals() []domain.Goal
+	UpdateGoals(ctx context.Context, goals []domain.Goal) error
+	GetGoalProgress(now time.Time) ([]domain.GoalProgress, error)
+
+	GetLoans() []domain.Loan
+	UpdateLoans(ctx context.Context, loans []domain.Loan) error
+	MaterializeLoans(ctx context.Context, now time.Time) ([]domain.Cell, error)
+
+	GetValuations() []domain.Valuation
+	UpdateValuations(ctx context.Context, items []domain.Valuation) error
+	GetNetWorth(fromYear, toYear int) []domain.NetWorthPoint
+	ExportNetWorth(ctx context.Context, filePath string, fromYear, toYear int) error
+
+	GetSplitByTransaction(transactionId string) (domain.Split, bool)
+	UpdateSplit(ctx context.Context, split domain.Split) ([]domain.Cell, error)
+	DeleteSplit(ctx context.Context, id string) ([]domain.Cell, error)
+
+	GetAttachments(key domain.CellKey) []domain.Attachment
+	AddAttachment(ctx context.Context, key domain.CellKey, transactionId, sourcePath string) (domain.Attachment, error)
+	DeleteAttachment(ctx context.Context, id string)
+	AttachmentPath(ctx context.Context, id string) (string, error)
+	Backup(ctx context.Context, filePath string) error
+
+	GetPayees() []domain.Payee
+	GetPayee(name string) (domain.Payee, bool)
+	UpdatePayees(ctx context.Context, items []domain.Payee) error
+	GetPayeeSpending(fromYear, toYear int) ([]domain.PayeeSpending, error)
 }
@@ -0,0 +1,350 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+// LoanWindow
+// окно редактирования кредитов и просмотра графика платежей
+type LoanWindow struct {
+	logger     log.Logger
+	appBody    *core.Body
+	loanDialog *core.Body
+	listFrame  *core.Frame
+
+	controller TableController
+	onUpsert   func(cell domain.Cell)
+	categories []core.ChooserItem
+	rows       []loanRow
+}
+
+// loanRow
+// строка списка кредитов; deleted - строка удалена из окна
+type loanRow struct {
+	loan    domain.Loan
+	deleted bool
+}
+
+var loanTypeItems = []core.ChooserItem{
+	{Value: domain.LoanAnnuity, Text: "Аннуитетный"},
+	{Value: domain.LoanDifferentiated, Text: "Дифференцированный"},
+}
+
+func NewLoanWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	categories [][]domain.Category, onUpsert func(cell domain.Cell)) *LoanWindow {
+	loanBody := core.NewBody("Loans").SetTitle("Кредиты")
+	loanBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	categoryItems := make([]core.ChooserItem, 0)
+	for _, mainCategory := range categories {
+		for _, category := range mainCategory {
+			if category.IsArchived() {
+				continue
+			}
+
+			categoryItems = append(categoryItems, core.ChooserItem{
+				Value: categoryRef{mainCategory: category.MainCategory, name: category.Name},
+				Text:  category.MainCategory + " / " + category.Name,
+			})
+		}
+	}
+
+	rows := make([]loanRow, 0)
+	for _, loan := range controller.GetLoans() {
+		rows = append(rows, loanRow{loan: loan})
+	}
+
+	loanWindow := &LoanWindow{
+		logger:     logger,
+		appBody:    appBody,
+		loanDialog: loanBody,
+		controller: controller,
+		onUpsert:   onUpsert,
+		categories: categoryItems,
+		rows:       rows,
+	}
+
+	mainFrame := core.NewFrame(loanBody)
+	mainFrame.SetName("mainLoanFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Платежи проводятся по графику: проценты и погашение долга - операциями в ячейки своих категорий")
+
+	loanWindow.addLoanList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	loanWindow.addButtons(buttonsFrame)
+
+	return loanWindow
+}
+
+func (s *LoanWindow) addLoanList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Кредит", "Сумма", "Ставка, %", "Срок, мес.", "Тип", "Первый платеж",
+		"Проценты", "Погашение долга")
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.rows {
+			if s.rows[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "loan_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addLoanRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить кредит")
+	addButton.OnClick(func(e events.Event) {
+		loan := domain.Loan{
+			TermMonths: 12,
+			Type:       domain.LoanAnnuity,
+			StartDate:  time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 1, 0),
+		}
+
+		if len(s.categories) != 0 {
+			ref := s.categories[0].Value.(categoryRef)
+			loan.InterestMainCategory, loan.InterestCategory = ref.mainCategory, ref.name
+			loan.PrincipalMainCategory, loan.PrincipalCategory = ref.mainCategory, ref.name
+		}
+
+		s.rows = append(s.rows, loanRow{loan: loan})
+		s.listFrame.Update()
+	})
+}
+
+func (s *LoanWindow) addLoanRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	loan := s.rows[idx].loan
+
+	nameField := s.newRowField(row).SetText(loan.Name).SetPlaceholder("Название")
+	nameField.OnChange(func(e events.Event) {
+		s.rows[idx].loan.Name = strings.TrimSpace(nameField.Text())
+	})
+
+	principalField := s.newRowField(row).SetPlaceholder("0")
+	if loan.Principal != 0 {
+		principalField.SetText(FormatMoney(loan.Principal))
+	}
+	principalField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(principalField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.loanDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.rows[idx].loan.Principal = value
+	})
+
+	// ставка вводится в процентах с точностью до сотых, как денежная сумма
+	rateField := s.newRowField(row).SetPlaceholder("0")
+	if loan.Rate != 0 {
+		rateField.SetText(FormatMoney(entity.Money(loan.Rate)))
+	}
+	rateField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(strings.TrimSuffix(strings.TrimSpace(rateField.Text()), "%"))
+		if err != nil || value < 0 {
+			core.MessageSnackbar(s.loanDialog, "Неверная ставка")
+			return
+		}
+
+		s.rows[idx].loan.Rate = int(value)
+	})
+
+	termField := s.newRowField(row).SetText(strconv.Itoa(loan.TermMonths))
+	termField.OnChange(func(e events.Event) {
+		term, err := strconv.Atoi(strings.TrimSpace(termField.Text()))
+		if err != nil || term < 1 {
+			core.MessageSnackbar(s.loanDialog, "Срок должен быть целым числом месяцев")
+			return
+		}
+
+		s.rows[idx].loan.TermMonths = term
+	})
+
+	typeChooser := core.NewChooser(row).SetItems(loanTypeItems...).SetCurrentValue(loan.Type)
+	typeChooser.OnChange(func(e events.Event) {
+		loanType, ok := typeChooser.CurrentItem.Value.(string)
+		if ok {
+			s.rows[idx].loan.Type = loanType
+		}
+	})
+
+	startField := s.newRowField(row).SetText(loan.StartDate.Format(transactionDateLayout))
+	startField.OnChange(func(e events.Event) {
+		date, err := parseOptionalDate(startField.Text())
+		if err != nil || date.IsZero() {
+			core.MessageSnackbar(s.loanDialog, "Неверная дата первого платежа, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.rows[idx].loan.StartDate = date
+	})
+
+	interestChooser := core.NewChooser(row).SetItems(s.categories...).
+		SetCurrentValue(categoryRef{mainCategory: loan.InterestMainCategory, name: loan.InterestCategory})
+	interestChooser.OnChange(func(e events.Event) {
+		ref, ok := interestChooser.CurrentItem.Value.(categoryRef)
+		if ok {
+			s.rows[idx].loan.InterestMainCategory = ref.mainCategory
+			s.rows[idx].loan.InterestCategory = ref.name
+		}
+	})
+
+	principalChooser := core.NewChooser(row).SetItems(s.categories...).
+		SetCurrentValue(categoryRef{mainCategory: loan.PrincipalMainCategory, name: loan.PrincipalCategory})
+	principalChooser.OnChange(func(e events.Event) {
+		ref, ok := principalChooser.CurrentItem.Value.(categoryRef)
+		if ok {
+			s.rows[idx].loan.PrincipalMainCategory = ref.mainCategory
+			s.rows[idx].loan.PrincipalCategory = ref.name
+		}
+	})
+
+	scheduleButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.CalendarMonth)
+	scheduleButton.SetTooltip("График платежей")
+	scheduleButton.OnClick(func(e events.Event) {
+		s.showSchedule(s.rows[idx].loan)
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить кредит")
+	deleteButton.OnClick(func(e events.Event) {
+		s.rows[idx].deleted = true
+		s.listFrame.Update()
+	})
+}
+
+// showSchedule
+// график платежей по введенным в строке, еще не сохраненным параметрам
+func (s *LoanWindow) showSchedule(loan domain.Loan) {
+	err := loan.Validate()
+	if err != nil {
+		core.MessageSnackbar(s.loanDialog, "Неверные параметры кредита: "+err.Error())
+		return
+	}
+
+	scheduleBody := core.NewBody("LoanSchedule").SetTitle("График платежей: " + loan.Name)
+
+	scheduleFrame := core.NewFrame(scheduleBody)
+	scheduleFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	addHeader(scheduleFrame, "Дата", "Платеж", "Проценты", "Погашение долга", "Остаток долга")
+
+	for _, payment := range loan.Schedule() {
+		paymentFrame := core.NewFrame(scheduleFrame)
+		for _, text := range []string{
+			payment.Date.Format(transactionDateLayout),
+			FormatMoney(payment.Total()),
+			FormatMoney(payment.Interest),
+			FormatMoney(payment.Principal),
+			FormatMoney(payment.Balance),
+		} {
+			cellFrame := core.NewFrame(paymentFrame)
+			cellFrame.Styler(func(s *styles.Style) {
+				s.Min.X.Dp(120)
+			})
+			core.NewText(cellFrame).SetText(text)
+		}
+	}
+
+	scheduleBody.AddBottomBar(func(bar core.Widget) {
+		scheduleBody.AddOK(bar)
+	})
+	scheduleBody.NewDialog(s.loanDialog).Run()
+}
+
+func (s *LoanWindow) newRowField(row *core.Frame) *core.TextField {
+	tField := core.NewTextField(row)
+	tField.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(120)
+		s.Max.X.Dp(120)
+	})
+
+	return tField
+}
+
+func (s *LoanWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		loans := make([]domain.Loan, 0, len(s.rows))
+		for _, row := range s.rows {
+			if !row.deleted {
+				loans = append(loans, row.loan)
+			}
+		}
+
+		err := s.controller.UpdateLoans(ctx, loans)
+		if err != nil {
+			core.MessageSnackbar(s.loanDialog, "Ошибка сохранения кредитов: "+err.Error())
+			s.logger.Error(ctx, "update loans error", log.Any("err", err.Error()))
+			return
+		}
+
+		// наступившие платежи проводятся сразу, не дожидаясь планировщика
+		cells, err := s.controller.MaterializeLoans(ctx, time.Now())
+		if err != nil {
+			s.logger.Error(ctx, "materialize loans error", log.Any("err", err.Error()))
+		}
+
+		s.close()
+
+		for _, cell := range cells {
+			s.onUpsert(cell)
+		}
+	})
+}
+
+func (s *LoanWindow) Run() {
+	stage := s.loanDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *LoanWindow) close() {
+	s.loanDialog.Close()
+}
@@ -0,0 +1,317 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+// netWorthExportFile - файл выгрузки капитала по умолчанию
+const netWorthExportFile = "netWorth.csv"
+
+var valuationKindItems = []core.ChooserItem{
+	{Value: domain.ValuationAsset, Text: "Актив"},
+	{Value: domain.ValuationLiability, Text: "Обязательство"},
+}
+
+// NetWorthWindow
+// окно оценок активов и обязательств и капитала на конец каждого месяца года
+type NetWorthWindow struct {
+	logger          log.Logger
+	appBody         *core.Body
+	netWorthDialog  *core.Body
+	listFrame       *core.Frame
+	netWorthFrame   *core.Frame
+	exportPathField *core.TextField
+
+	controller TableController
+	startYear  int
+	year       int
+	rows       []valuationRow
+}
+
+// valuationRow
+// строка списка оценок; deleted - строка удалена из окна
+type valuationRow struct {
+	item    domain.Valuation
+	deleted bool
+}
+
+func NewNetWorthWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	startYear int) *NetWorthWindow {
+	netWorthBody := core.NewBody("NetWorth").SetTitle("Капитал")
+	netWorthBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	rows := make([]valuationRow, 0)
+	for _, item := range controller.GetValuations() {
+		rows = append(rows, valuationRow{item: item})
+	}
+
+	netWorthWindow := &NetWorthWindow{
+		logger:         logger,
+		appBody:        appBody,
+		netWorthDialog: netWorthBody,
+		controller:     controller,
+		startYear:      startYear,
+		year:           time.Now().Year(),
+		rows:           rows,
+	}
+
+	mainFrame := core.NewFrame(netWorthBody)
+	mainFrame.SetName("mainNetWorthFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Оценка действует до следующей оценки того же актива; долг по кредитам учитывается автоматически")
+
+	netWorthWindow.addValuationList(mainFrame)
+	netWorthWindow.addNetWorth(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	netWorthWindow.addButtons(buttonsFrame)
+
+	return netWorthWindow
+}
+
+func (s *NetWorthWindow) addValuationList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Актив", "Вид", "Дата", "Оценка", "Комментарий")
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.rows {
+			if s.rows[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "valuation_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addValuationRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить оценку")
+	addButton.OnClick(func(e events.Event) {
+		s.rows = append(s.rows, valuationRow{item: domain.Valuation{
+			Kind: domain.ValuationAsset,
+			Date: time.Now().UTC().Truncate(24 * time.Hour),
+		}})
+		s.listFrame.Update()
+	})
+}
+
+func (s *NetWorthWindow) addValuationRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	item := s.rows[idx].item
+
+	assetField := s.newRowField(row).SetText(item.Asset).SetPlaceholder("Название")
+	assetField.OnChange(func(e events.Event) {
+		s.rows[idx].item.Asset = strings.TrimSpace(assetField.Text())
+	})
+
+	kindChooser := core.NewChooser(row).SetItems(valuationKindItems...).SetCurrentValue(item.Kind)
+	kindChooser.OnChange(func(e events.Event) {
+		kind, ok := kindChooser.CurrentItem.Value.(string)
+		if ok {
+			s.rows[idx].item.Kind = kind
+		}
+	})
+
+	dateField := s.newRowField(row).SetText(item.Date.Format(transactionDateLayout))
+	dateField.OnChange(func(e events.Event) {
+		date, err := parseOptionalDate(dateField.Text())
+		if err != nil || date.IsZero() {
+			core.MessageSnackbar(s.netWorthDialog, "Неверная дата, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.rows[idx].item.Date = date
+	})
+
+	valueField := s.newRowField(row).SetPlaceholder("0")
+	if item.Value != 0 {
+		valueField.SetText(FormatMoney(item.Value))
+	}
+	valueField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(valueField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.netWorthDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.rows[idx].item.Value = value
+	})
+
+	noteField := s.newRowField(row).SetText(item.Note)
+	noteField.OnChange(func(e events.Event) {
+		s.rows[idx].item.Note = strings.TrimSpace(noteField.Text())
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить оценку")
+	deleteButton.OnClick(func(e events.Event) {
+		s.rows[idx].deleted = true
+		s.listFrame.Update()
+	})
+}
+
+// addNetWorth
+// капитал по месяцам выбранного года по сохраненным данным и выгрузка с первого года таблицы
+func (s *NetWorthWindow) addNetWorth(mainFrame *core.Frame) {
+	settingFrame := core.NewFrame(mainFrame)
+	settingFrame.SetName("settingFrame")
+	settingFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	yearItems := make([]core.ChooserItem, 0)
+	for year := s.startYear; year <= time.Now().Year(); year++ {
+		yearItems = append(yearItems, core.ChooserItem{Value: year, Text: strconv.Itoa(year)})
+	}
+
+	yearChooser := core.NewChooser(settingFrame).SetItems(yearItems...).SetCurrentValue(s.year)
+	yearChooser.OnChange(func(e events.Event) {
+		year, ok := yearChooser.CurrentItem.Value.(int)
+		if ok {
+			s.year = year
+			s.netWorthFrame.Update()
+		}
+	})
+
+	s.exportPathField = core.NewTextField(settingFrame).SetText(netWorthExportFile)
+	s.exportPathField.SetTooltip("Файл выгрузки")
+
+	exportButton := core.NewButton(settingFrame).SetType(core.ButtonTonal).SetIcon(icons.Download).
+		SetText("Выгрузить в CSV")
+	exportButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		filePath := strings.TrimSpace(s.exportPathField.Text())
+		if len(filePath) == 0 {
+			core.MessageSnackbar(s.netWorthDialog, "Не указан файл выгрузки")
+			return
+		}
+
+		err := s.controller.ExportNetWorth(ctx, filePath, s.startYear, time.Now().Year())
+		if err != nil {
+			core.MessageSnackbar(s.netWorthDialog, "Ошибка выгрузки: "+err.Error())
+			s.logger.Error(ctx, "export net worth error", log.Any("err", err.Error()))
+			return
+		}
+
+		core.MessageSnackbar(s.netWorthDialog, "Капитал выгружен в "+filePath)
+	})
+
+	addHeader(mainFrame, "Месяц", "Остаток", "Активы", "Обязательства", "Долг по кредитам", "Капитал")
+
+	s.netWorthFrame = core.NewFrame(mainFrame)
+	s.netWorthFrame.SetName("netWorthFrame")
+	s.netWorthFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.netWorthFrame.Maker(func(p *tree.Plan) {
+		for _, point := range s.controller.GetNetWorth(s.year, s.year) {
+			name := "netWorth_" + strconv.Itoa(point.Date.Year) + "_" + strconv.Itoa(point.Date.Month)
+			tree.AddAt(p, name, func(row *core.Frame) {
+				s.addNetWorthRow(row, point)
+			})
+		}
+	})
+}
+
+func (s *NetWorthWindow) addNetWorthRow(row *core.Frame, point domain.NetWorthPoint) {
+	for _, text := range []string{
+		domain.RusMonths[point.Date.Month] + " " + strconv.Itoa(point.Date.Year),
+		FormatMoney(point.Balance),
+		FormatMoney(point.Assets),
+		FormatMoney(point.Liabilities),
+		FormatMoney(point.Debt),
+		FormatMoney(point.NetWorth()),
+	} {
+		cellFrame := core.NewFrame(row)
+		cellFrame.Styler(func(s *styles.Style) {
+			s.Min.X.Dp(120)
+		})
+		core.NewText(cellFrame).SetText(text)
+	}
+}
+
+func (s *NetWorthWindow) newRowField(row *core.Frame) *core.TextField {
+	tField := core.NewTextField(row)
+	tField.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(120)
+		s.Max.X.Dp(120)
+	})
+
+	return tField
+}
+
+func (s *NetWorthWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		items := make([]domain.Valuation, 0, len(s.rows))
+		for _, row := range s.rows {
+			if !row.deleted {
+				items = append(items, row.item)
+			}
+		}
+
+		err := s.controller.UpdateValuations(ctx, items)
+		if err != nil {
+			core.MessageSnackbar(s.netWorthDialog, "Ошибка сохранения оценок: "+err.Error())
+			s.logger.Error(ctx, "update valuations error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		// капитал в итогах года зависит от оценок
+		s.appBody.Update()
+	})
+}
+
+func (s *NetWorthWindow) Run() {
+	stage := s.netWorthDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *NetWorthWindow) close() {
+	s.netWorthDialog.Close()
+}
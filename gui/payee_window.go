@@ -0,0 +1,274 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/parse/complete"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+// payeeSpendingLimit - сколько получателей с наибольшими расходами выводится в окне
+const payeeSpendingLimit = 30
+
+// addPayeeCompleter
+// подсказка названий получателей из справочника при вводе
+func addPayeeCompleter(field *core.TextField, controller TableController) {
+	field.SetCompleter(nil, func(data any, text string, posLine, posChar int) complete.Matches {
+		completions := make(complete.Completions, 0)
+		for _, payee := range controller.GetPayees() {
+			completions = append(completions, complete.Completion{Text: payee.Name, Desc: payee.Category})
+		}
+
+		// смещение в поле считается в символах, а длина затравки - в байтах,
+		// поэтому затравка заменяется строкой той же длины в символах
+		return complete.Matches{
+			Matches: complete.MatchSeedCompletion(completions, strings.TrimSpace(text)),
+			Seed:    strings.Repeat(" ", utf8.RuneCountInString(text)),
+		}
+	}, func(data any, text string, cursorPos int, comp complete.Completion, seed string) complete.Edit {
+		// текст поля заменяется выбранным названием целиком
+		return complete.Edit{
+			NewText:       comp.Text,
+			ForwardDelete: utf8.RuneCountInString(text),
+		}
+	})
+}
+
+// PayeeWindow
+// окно справочника получателей с категориями по умолчанию и расходов по получателям за годы
+type PayeeWindow struct {
+	logger      log.Logger
+	appBody     *core.Body
+	payeeDialog *core.Body
+	listFrame   *core.Frame
+
+	controller TableController
+	categories []core.ChooserItem
+	startYear  int
+	rows       []payeeRow
+}
+
+// payeeRow
+// строка справочника; deleted - строка удалена из окна
+type payeeRow struct {
+	item    domain.Payee
+	deleted bool
+}
+
+func NewPayeeWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	categories [][]domain.Category, startYear int) *PayeeWindow {
+	payeeBody := core.NewBody("Payees").SetTitle("Получатели")
+	payeeBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	categoryItems := []core.ChooserItem{{Value: categoryRef{}, Text: "Без категории"}}
+	for _, mainCategory := range categories {
+		for _, category := range mainCategory {
+			if category.IsArchived() {
+				continue
+			}
+
+			categoryItems = append(categoryItems, core.ChooserItem{
+				Value: categoryRef{mainCategory: category.MainCategory, name: category.Name},
+				Text:  category.MainCategory + " / " + category.Name,
+			})
+		}
+	}
+
+	rows := make([]payeeRow, 0)
+	for _, item := range controller.GetPayees() {
+		rows = append(rows, payeeRow{item: item})
+	}
+
+	payeeWindow := &PayeeWindow{
+		logger:      logger,
+		appBody:     appBody,
+		payeeDialog: payeeBody,
+		controller:  controller,
+		categories:  categoryItems,
+		startYear:   startYear,
+		rows:        rows,
+	}
+
+	mainFrame := core.NewFrame(payeeBody)
+	mainFrame.SetName("mainPayeeFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Новые получатели из операций добавляются с категорией ячейки; переименование меняет все операции")
+
+	payeeWindow.addPayeeList(mainFrame)
+	payeeWindow.addSpending(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	payeeWindow.addButtons(buttonsFrame)
+
+	return payeeWindow
+}
+
+func (s *PayeeWindow) addPayeeList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Получатель", "Категория по умолчанию")
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.rows {
+			if s.rows[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "payee_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addPayeeRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить получателя")
+	addButton.OnClick(func(e events.Event) {
+		s.rows = append(s.rows, payeeRow{})
+		s.listFrame.Update()
+	})
+}
+
+func (s *PayeeWindow) addPayeeRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	item := s.rows[idx].item
+
+	nameField := core.NewTextField(row).SetText(item.Name).SetPlaceholder("Название")
+	nameField.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(180)
+		s.Max.X.Dp(180)
+	})
+	nameField.OnChange(func(e events.Event) {
+		s.rows[idx].item.Name = strings.TrimSpace(nameField.Text())
+	})
+
+	categoryChooser := core.NewChooser(row).SetItems(s.categories...).
+		SetCurrentValue(categoryRef{mainCategory: item.MainCategory, name: item.Category})
+	categoryChooser.OnChange(func(e events.Event) {
+		ref, ok := categoryChooser.CurrentItem.Value.(categoryRef)
+		if ok {
+			s.rows[idx].item.MainCategory = ref.mainCategory
+			s.rows[idx].item.Category = ref.name
+		}
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить из справочника; в операциях получатель остается")
+	deleteButton.OnClick(func(e events.Event) {
+		s.rows[idx].deleted = true
+		s.listFrame.Update()
+	})
+}
+
+// addSpending
+// расходы по получателям по годам с первого года таблицы по введенным, в том числе несохраненным, данным
+func (s *PayeeWindow) addSpending(mainFrame *core.Frame) {
+	toYear := time.Now().Year()
+
+	spending, err := s.controller.GetPayeeSpending(s.startYear, toYear)
+	if err != nil {
+		s.logger.Error(context.Background(), "get payee spending error", log.Any("err", err.Error()))
+	}
+
+	titles := []string{"Расходы"}
+	for year := s.startYear; year <= toYear; year++ {
+		titles = append(titles, strconv.Itoa(year))
+	}
+	titles = append(titles, "Всего")
+	addHeader(mainFrame, titles...)
+
+	spendingFrame := core.NewFrame(mainFrame)
+	spendingFrame.SetName("spendingFrame")
+	spendingFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	for i, item := range spending {
+		if i == payeeSpendingLimit {
+			break
+		}
+
+		texts := []string{item.Payee}
+		for year := s.startYear; year <= toYear; year++ {
+			texts = append(texts, FormatMoney(item.ByYear[year]))
+		}
+		texts = append(texts, FormatMoney(item.Total))
+
+		rowFrame := core.NewFrame(spendingFrame)
+		for _, text := range texts {
+			cellFrame := core.NewFrame(rowFrame)
+			cellFrame.Styler(func(s *styles.Style) {
+				s.Min.X.Dp(120)
+			})
+			core.NewText(cellFrame).SetText(text)
+		}
+	}
+}
+
+func (s *PayeeWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		items := make([]domain.Payee, 0, len(s.rows))
+		for _, row := range s.rows {
+			if !row.deleted {
+				items = append(items, row.item)
+			}
+		}
+
+		err := s.controller.UpdatePayees(ctx, items)
+		if err != nil {
+			core.MessageSnackbar(s.payeeDialog, "Ошибка сохранения получателей: "+err.Error())
+			s.logger.Error(ctx, "update payees error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+	})
+}
+
+func (s *PayeeWindow) Run() {
+	stage := s.payeeDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *PayeeWindow) close() {
+	s.payeeDialog.Close()
+}
@@ -0,0 +1,251 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/conf"
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/utils"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+var periodKindTitles = map[domain.PeriodKind]string{
+	domain.PeriodWeek:    "Неделя",
+	domain.PeriodMonth:   "Месяц",
+	domain.PeriodQuarter: "Квартал",
+	domain.PeriodYear:    "Финансовый год",
+}
+
+// PeriodWindow
+// окно итогов по неделям, месяцам, кварталам или финансовым годам;
+// месяц может начинаться с любого дня, а год - с любого месяца
+type PeriodWindow struct {
+	logger       log.Logger
+	appBody      *core.Body
+	periodDialog *core.Body
+	listFrame    *core.Frame
+
+	controller TableController
+	categories []domain.Category
+	setting    domain.PeriodSetting
+	year       int
+}
+
+func NewPeriodWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	categories [][]domain.Category, settings conf.Setting) *PeriodWindow {
+	periodBody := core.NewBody("Periods").SetTitle("Итоги по периодам")
+	periodBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	setting := domain.PeriodSetting{
+		Kind:       domain.PeriodMonth,
+		StartDay:   settings.Period.StartDay,
+		StartMonth: time.Month(settings.Period.FiscalStartMonth),
+	}
+	if setting.StartDay == 0 {
+		setting.StartDay = 1
+	}
+	if setting.StartMonth == 0 {
+		setting.StartMonth = time.January
+	}
+
+	all := make([]domain.Category, 0)
+	for _, mainCategory := range categories {
+		all = append(all, mainCategory...)
+	}
+
+	periodWindow := &PeriodWindow{
+		logger:       logger,
+		appBody:      appBody,
+		periodDialog: periodBody,
+		controller:   controller,
+		categories:   all,
+		setting:      setting,
+		year:         time.Now().Year(),
+	}
+
+	mainFrame := core.NewFrame(periodBody)
+	mainFrame.SetName("mainPeriodFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Суммы распределяются по датам операций; значение ячейки без операций относится к первому числу месяца")
+
+	settingFrame := core.NewFrame(mainFrame)
+	settingFrame.SetName("settingFrame")
+	periodWindow.addSetting(settingFrame, settings.StartYear)
+
+	periodWindow.addPeriodList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	periodWindow.addButtons(buttonsFrame)
+
+	return periodWindow
+}
+
+func (s *PeriodWindow) addSetting(settingFrame *core.Frame, startYear int) {
+	settingFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	kindItems := make([]core.ChooserItem, 0, len(periodKindTitles))
+	for _, kind := range []domain.PeriodKind{domain.PeriodWeek, domain.PeriodMonth, domain.PeriodQuarter, domain.PeriodYear} {
+		kindItems = append(kindItems, core.ChooserItem{Value: kind, Text: periodKindTitles[kind]})
+	}
+
+	kindChooser := core.NewChooser(settingFrame).SetItems(kindItems...).SetCurrentValue(s.setting.Kind)
+	kindChooser.OnChange(func(e events.Event) {
+		kind, ok := kindChooser.CurrentItem.Value.(domain.PeriodKind)
+		if ok {
+			s.setting.Kind = kind
+			s.listFrame.Update()
+		}
+	})
+
+	core.NewText(settingFrame).SetText("Месяц с")
+	dayField := core.NewTextField(settingFrame).SetText(strconv.Itoa(s.setting.StartDay))
+	dayField.SetTooltip("День, с которого начинается месяц, от 1 до 28")
+	dayField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(60)
+	})
+	dayField.OnChange(func(e events.Event) {
+		day, err := strconv.Atoi(strings.TrimSpace(dayField.Text()))
+		if err != nil || day < 1 || day > 28 {
+			core.MessageSnackbar(s.periodDialog, "День начала месяца должен быть от 1 до 28")
+			return
+		}
+
+		s.setting.StartDay = day
+		s.listFrame.Update()
+	})
+
+	monthItems := make([]core.ChooserItem, 0, len(domain.RusMonths))
+	for month := time.January; month <= time.December; month++ {
+		monthItems = append(monthItems, core.ChooserItem{Value: month, Text: domain.RusMonths[int(month)]})
+	}
+
+	core.NewText(settingFrame).SetText("Год с")
+	monthChooser := core.NewChooser(settingFrame).SetItems(monthItems...).SetCurrentValue(s.setting.StartMonth)
+	monthChooser.SetTooltip("Первый месяц финансового года, от него же отсчитываются кварталы")
+	monthChooser.OnChange(func(e events.Event) {
+		month, ok := monthChooser.CurrentItem.Value.(time.Month)
+		if ok {
+			s.setting.StartMonth = month
+			s.listFrame.Update()
+		}
+	})
+
+	yearItems := make([]core.ChooserItem, 0)
+	for year := startYear; year <= time.Now().Year(); year++ {
+		yearItems = append(yearItems, core.ChooserItem{Value: year, Text: strconv.Itoa(year)})
+	}
+
+	yearChooser := core.NewChooser(settingFrame).SetItems(yearItems...).SetCurrentValue(s.year)
+	yearChooser.OnChange(func(e events.Event) {
+		year, ok := yearChooser.CurrentItem.Value.(int)
+		if ok {
+			s.year = year
+			s.listFrame.Update()
+		}
+	})
+}
+
+func (s *PeriodWindow) addPeriodList(mainFrame *core.Frame) {
+	titles := []string{"Период", "Доходы", "Расходы", "Итог"}
+	for _, category := range s.categories {
+		titles = append(titles, category.MainCategory+" / "+category.Name)
+	}
+
+	tableFrame := core.NewFrame(mainFrame)
+	tableFrame.SetName("tableFrame")
+	tableFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.Overflow.Set(styles.OverflowAuto)
+		s.Max.X.Dp(1200)
+		s.Max.Y.Dp(600)
+	})
+
+	addHeader(tableFrame, titles...)
+
+	s.listFrame = core.NewFrame(tableFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		// в пределы выбранного года попадают все периоды, которые его пересекают
+		from := s.setting.PeriodOf(time.Date(s.year, time.January, 1, 0, 0, 0, 0, time.UTC)).Start
+		to := s.setting.PeriodOf(time.Date(s.year, time.December, 31, 0, 0, 0, 0, time.UTC)).End
+
+		results, err := s.controller.GetPeriodResults(s.setting, from, to)
+		if err != nil {
+			s.logger.Error(context.Background(), "get period results", log.Any("err", err.Error()))
+			if results == nil {
+				return
+			}
+		}
+
+		for _, result := range results {
+			// в имени строки - границы периода, чтобы при смене настроек строки создавались заново
+			name := "period_" + result.Period.Start.Format(time.DateOnly) + "_" + result.Period.End.Format(time.DateOnly)
+			tree.AddAt(p, name, func(row *core.Frame) {
+				s.addPeriodRow(row, result)
+			})
+		}
+	})
+}
+
+func (s *PeriodWindow) addPeriodRow(row *core.Frame, result domain.PeriodResult) {
+	values := []string{
+		result.Period.Title(),
+		FormatMoney(result.Income),
+		FormatMoney(result.Consumption),
+		FormatMoney(result.Income - result.Consumption),
+	}
+	for _, category := range s.categories {
+		values = append(values, FormatMoney(result.ByCategory[utils.GetCompositeCategory(category.MainCategory, category.Name)]))
+	}
+
+	for _, value := range values {
+		cellFrame := core.NewFrame(row)
+		cellFrame.Styler(func(s *styles.Style) {
+			s.Min.X.Dp(120)
+		})
+		core.NewText(cellFrame).SetText(value)
+	}
+}
+
+func (s *PeriodWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	closeButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Закрыть")
+	closeButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+}
+
+func (s *PeriodWindow) Run() {
+	stage := s.periodDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *PeriodWindow) close() {
+	s.periodDialog.Close()
+}
@@ -0,0 +1,246 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/conf"
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+	"github.com/pkg/errors"
+)
+
+const rateMonthLayout = "01.2006"
+
+// RateWindow
+// окно редактирования таблицы курсов валют по месяцам
+type RateWindow struct {
+	logger     log.Logger
+	appBody    *core.Body
+	rateDialog *core.Body
+	listFrame  *core.Frame
+
+	controller TableController
+	sumUpdater *SumUpdater
+	settings   conf.Setting
+	rows       []rateRow
+}
+
+// rateRow
+// строка таблицы курсов; deleted - строка удалена из окна
+type rateRow struct {
+	rate    domain.ExchangeRate
+	deleted bool
+}
+
+func NewRateWindow(logger log.Logger, appBody *core.Body, controller TableController, settings conf.Setting,
+	sumUpdater *SumUpdater) *RateWindow {
+	rateBody := core.NewBody("Rates").SetTitle("Курсы валют")
+	rateBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	rows := make([]rateRow, 0)
+	for _, rate := range controller.GetRates() {
+		rows = append(rows, rateRow{rate: rate})
+	}
+
+	rateWindow := &RateWindow{
+		logger:     logger,
+		appBody:    appBody,
+		rateDialog: rateBody,
+		controller: controller,
+		sumUpdater: sumUpdater,
+		settings:   settings,
+		rows:       rows,
+	}
+
+	mainFrame := core.NewFrame(rateBody)
+	mainFrame.SetName("mainRateFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Стоимость единицы валюты в " + settings.GetBaseCurrency() + "; курс действует до следующего указанного месяца")
+
+	rateWindow.addRateList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	rateWindow.addButtons(buttonsFrame)
+
+	return rateWindow
+}
+
+func (s *RateWindow) addRateList(mainFrame *core.Frame) {
+	headFrame := core.NewFrame(mainFrame)
+	headFrame.SetName("headFrame")
+	for _, title := range []string{"Валюта", "Месяц", "Курс"} {
+		titleFrame := core.NewFrame(headFrame)
+		titleFrame.Styler(func(s *styles.Style) {
+			s.Min.X.Dp(120)
+		})
+		core.NewText(titleFrame).SetType(core.TextLabelLarge).SetText(title)
+	}
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.rows {
+			if s.rows[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "rate_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addRateRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить")
+	addButton.OnClick(func(e events.Event) {
+		now := time.Now()
+		s.rows = append(s.rows, rateRow{rate: domain.ExchangeRate{
+			Month: now.Month(),
+			Year:  now.Year(),
+		}})
+		s.listFrame.Update()
+	})
+}
+
+func (s *RateWindow) addRateRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	rate := s.rows[idx].rate
+
+	currencies := make([]string, 0, len(s.settings.Currencies))
+	for _, currency := range s.settings.Currencies {
+		if currency != s.settings.GetBaseCurrency() {
+			currencies = append(currencies, currency)
+		}
+	}
+
+	chooser := core.NewChooser(row).SetStrings(currencies...).SetEditable(true)
+	if len(rate.Currency) != 0 {
+		chooser.SetCurrentValue(rate.Currency)
+	}
+	chooser.OnChange(func(e events.Event) {
+		value, ok := chooser.CurrentItem.Value.(string)
+		if ok {
+			s.rows[idx].rate.Currency = strings.ToUpper(strings.TrimSpace(value))
+		}
+	})
+
+	monthField := core.NewTextField(row).SetText(time.Date(rate.Year, rate.Month, 1, 0, 0, 0, 0, time.UTC).Format(rateMonthLayout))
+	monthField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	monthField.OnChange(func(e events.Event) {
+		date, err := time.Parse(rateMonthLayout, strings.TrimSpace(monthField.Text()))
+		if err != nil {
+			core.MessageSnackbar(s.rateDialog, "Неверный месяц, ожидается формат ММ.ГГГГ")
+			return
+		}
+
+		s.rows[idx].rate.Month = date.Month()
+		s.rows[idx].rate.Year = date.Year()
+	})
+
+	rateField := core.NewTextField(row).SetPlaceholder("0")
+	if rate.Rate != 0 {
+		rateField.SetText(strconv.FormatFloat(rate.Rate, 'f', -1, 64))
+	}
+	rateField.Styler(func(s *styles.Style) {
+		s.Max.X.Dp(120)
+	})
+	rateField.OnChange(func(e events.Event) {
+		value, err := parseRate(rateField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.rateDialog, "Неверный курс: "+err.Error())
+			return
+		}
+
+		s.rows[idx].rate.Rate = value
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить курс")
+	deleteButton.OnClick(func(e events.Event) {
+		s.rows[idx].deleted = true
+		s.listFrame.Update()
+	})
+}
+
+func (s *RateWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		rates := make([]domain.ExchangeRate, 0, len(s.rows))
+		for _, row := range s.rows {
+			if !row.deleted {
+				rates = append(rates, row.rate)
+			}
+		}
+
+		err := s.controller.UpdateRates(context.Background(), rates)
+		if err != nil {
+			core.MessageSnackbar(s.rateDialog, "Ошибка сохранения курсов: "+err.Error())
+			s.logger.Error(context.Background(), "update rates error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		s.sumUpdater.RefreshAll()
+	})
+}
+
+func (s *RateWindow) Run() {
+	stage := s.rateDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *RateWindow) close() {
+	s.rateDialog.Close()
+}
+
+// parseRate
+// курс может быть введен как с точкой, так и с запятой
+func parseRate(text string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(text), ",", "."), 64)
+	if err != nil {
+		return 0, errors.New("ожидается число")
+	}
+
+	if value <= 0 {
+		return 0, errors.New("курс должен быть больше нуля")
+	}
+
+	return value, nil
+}
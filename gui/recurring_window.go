@@ -0,0 +1,313 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+	"github.com/pkg/errors"
+)
+
+// RecurringWindow
+// окно редактирования регулярных платежей
+type RecurringWindow struct {
+	logger          log.Logger
+	appBody         *core.Body
+	recurringDialog *core.Body
+	listFrame       *core.Frame
+
+	controller TableController
+	onUpsert   func(cell domain.Cell)
+	categories []core.ChooserItem
+	rows       []recurringRow
+}
+
+// recurringRow
+// строка списка платежей; deleted - строка удалена из окна
+type recurringRow struct {
+	item    domain.Recurring
+	deleted bool
+}
+
+// categoryRef
+// категория платежа в списке выбора
+type categoryRef struct {
+	mainCategory string
+	name         string
+}
+
+func NewRecurringWindow(logger log.Logger, appBody *core.Body, controller TableController,
+	categories [][]domain.Category, onUpsert func(cell domain.Cell)) *RecurringWindow {
+	recurringBody := core.NewBody("Recurring").SetTitle("Регулярные платежи")
+	recurringBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	categoryItems := make([]core.ChooserItem, 0)
+	for _, mainCategory := range categories {
+		for _, category := range mainCategory {
+			if category.IsArchived() {
+				continue
+			}
+
+			categoryItems = append(categoryItems, core.ChooserItem{
+				Value: categoryRef{mainCategory: category.MainCategory, name: category.Name},
+				Text:  category.MainCategory + " / " + category.Name,
+			})
+		}
+	}
+
+	rows := make([]recurringRow, 0)
+	for _, item := range controller.GetRecurring() {
+		rows = append(rows, recurringRow{item: item})
+	}
+
+	recurringWindow := &RecurringWindow{
+		logger:          logger,
+		appBody:         appBody,
+		recurringDialog: recurringBody,
+		controller:      controller,
+		onUpsert:        onUpsert,
+		categories:      categoryItems,
+		rows:            rows,
+	}
+
+	mainFrame := core.NewFrame(recurringBody)
+	mainFrame.SetName("mainRecurringFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	core.NewText(mainFrame).SetType(core.TextBodyMedium).
+		SetText("Платеж проводится операцией в ячейку категории в указанный день каждые N месяцев")
+
+	recurringWindow.addRecurringList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	recurringWindow.addButtons(buttonsFrame)
+
+	return recurringWindow
+}
+
+func (s *RecurringWindow) addRecurringList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Категория", "Сумма", "День", "Раз в N мес.", "Начало", "Окончание", "Комментарий")
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.rows {
+			if s.rows[i].deleted {
+				continue
+			}
+
+			tree.AddAt(p, "recurring_"+strconv.Itoa(i), func(row *core.Frame) {
+				s.addRecurringRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить")
+	addButton.OnClick(func(e events.Event) {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		item := domain.Recurring{
+			Day:         today.Day(),
+			EveryMonths: 1,
+			StartDate:   today,
+		}
+
+		if len(s.categories) != 0 {
+			ref := s.categories[0].Value.(categoryRef)
+			item.MainCategory = ref.mainCategory
+			item.Category = ref.name
+		}
+
+		s.rows = append(s.rows, recurringRow{item: item})
+		s.listFrame.Update()
+	})
+}
+
+func (s *RecurringWindow) addRecurringRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	item := s.rows[idx].item
+
+	categoryChooser := core.NewChooser(row).SetItems(s.categories...).
+		SetCurrentValue(categoryRef{mainCategory: item.MainCategory, name: item.Category})
+	categoryChooser.OnChange(func(e events.Event) {
+		ref, ok := categoryChooser.CurrentItem.Value.(categoryRef)
+		if ok {
+			s.rows[idx].item.MainCategory = ref.mainCategory
+			s.rows[idx].item.Category = ref.name
+		}
+	})
+
+	amountField := s.newRowField(row).SetPlaceholder("0")
+	if item.Amount != 0 {
+		amountField.SetText(FormatMoney(item.Amount))
+	}
+	amountField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(amountField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.recurringDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.rows[idx].item.Amount = value
+	})
+
+	dayField := s.newRowField(row).SetText(strconv.Itoa(item.Day))
+	dayField.OnChange(func(e events.Event) {
+		day, err := strconv.Atoi(strings.TrimSpace(dayField.Text()))
+		if err != nil || day < 1 || day > 31 {
+			core.MessageSnackbar(s.recurringDialog, "День месяца должен быть от 1 до 31")
+			return
+		}
+
+		s.rows[idx].item.Day = day
+	})
+
+	periodField := s.newRowField(row).SetText(strconv.Itoa(item.EveryMonths))
+	periodField.OnChange(func(e events.Event) {
+		everyMonths, err := strconv.Atoi(strings.TrimSpace(periodField.Text()))
+		if err != nil || everyMonths < 1 {
+			core.MessageSnackbar(s.recurringDialog, "Период должен быть целым числом месяцев")
+			return
+		}
+
+		s.rows[idx].item.EveryMonths = everyMonths
+	})
+
+	startField := s.newRowField(row).SetText(item.StartDate.Format(transactionDateLayout))
+	startField.OnChange(func(e events.Event) {
+		date, err := parseOptionalDate(startField.Text())
+		if err != nil || date.IsZero() {
+			core.MessageSnackbar(s.recurringDialog, "Неверная дата начала, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.rows[idx].item.StartDate = date
+	})
+
+	endField := s.newRowField(row).SetPlaceholder("бессрочно")
+	if !item.EndDate.IsZero() {
+		endField.SetText(item.EndDate.Format(transactionDateLayout))
+	}
+	endField.OnChange(func(e events.Event) {
+		date, err := parseOptionalDate(endField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.recurringDialog, "Неверная дата окончания, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.rows[idx].item.EndDate = date
+	})
+
+	noteField := s.newRowField(row).SetText(item.Note)
+	noteField.OnChange(func(e events.Event) {
+		s.rows[idx].item.Note = strings.TrimSpace(noteField.Text())
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить платеж")
+	deleteButton.OnClick(func(e events.Event) {
+		s.rows[idx].deleted = true
+		s.listFrame.Update()
+	})
+}
+
+func (s *RecurringWindow) newRowField(row *core.Frame) *core.TextField {
+	tField := core.NewTextField(row)
+	tField.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(120)
+		s.Max.X.Dp(120)
+	})
+
+	return tField
+}
+
+func (s *RecurringWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		items := make([]domain.Recurring, 0, len(s.rows))
+		for _, row := range s.rows {
+			if !row.deleted {
+				items = append(items, row.item)
+			}
+		}
+
+		err := s.controller.UpdateRecurring(ctx, items)
+		if err != nil {
+			core.MessageSnackbar(s.recurringDialog, "Ошибка сохранения платежей: "+err.Error())
+			s.logger.Error(ctx, "update recurring error", log.Any("err", err.Error()))
+			return
+		}
+
+		// наступившие платежи проводятся сразу, не дожидаясь планировщика
+		cells, err := s.controller.MaterializeRecurring(ctx, time.Now())
+		if err != nil {
+			s.logger.Error(ctx, "materialize recurring error", log.Any("err", err.Error()))
+		}
+
+		s.close()
+
+		for _, cell := range cells {
+			s.onUpsert(cell)
+		}
+	})
+}
+
+func (s *RecurringWindow) Run() {
+	stage := s.recurringDialog.NewDialog(s.appBody)
+	stage.Run()
+}
+
+func (s *RecurringWindow) close() {
+	s.recurringDialog.Close()
+}
+
+// parseOptionalDate
+// пустая строка - пустая дата
+func parseOptionalDate(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(transactionDateLayout, text)
+	if err != nil {
+		return time.Time{}, errors.New("ожидается формат ДД.ММ.ГГГГ")
+	}
+
+	return date, nil
+}
@@ -0,0 +1,368 @@
+package gui
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/log"
+	"table-app/utils"
+
+	"cogentcore.org/core/core"
+	"cogentcore.org/core/events"
+	"cogentcore.org/core/icons"
+	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+)
+
+// SplitWindow
+// окно разделения одной суммы между несколькими категориями, суммами или долями;
+// при изменении общей суммы части пересчитываются
+type SplitWindow struct {
+	logger      log.Logger
+	splitDialog *core.Body
+	listFrame   *core.Frame
+	textRest    *core.Text
+
+	controller TableController
+	onSave     func(cells []domain.Cell)
+	categories []core.ChooserItem
+	split      domain.Split
+}
+
+func NewSplitWindow(logger log.Logger, controller TableController, categories [][]domain.Category,
+	split domain.Split, onSave func(cells []domain.Cell)) *SplitWindow {
+	splitBody := core.NewBody("Split").SetTitle("Разделение операции")
+	splitBody.Styler(func(s *styles.Style) {
+		s.Align.Self = styles.Center
+		s.CenterAll()
+	})
+
+	categoryItems := make([]core.ChooserItem, 0)
+	for _, mainCategory := range categories {
+		for _, category := range mainCategory {
+			if category.IsArchived() {
+				continue
+			}
+
+			categoryItems = append(categoryItems, core.ChooserItem{
+				Value: categoryRef{mainCategory: category.MainCategory, name: category.Name},
+				Text:  category.MainCategory + " / " + category.Name,
+			})
+		}
+	}
+
+	splitWindow := &SplitWindow{
+		logger:      logger,
+		splitDialog: splitBody,
+		controller:  controller,
+		onSave:      onSave,
+		categories:  categoryItems,
+		split:       split,
+	}
+
+	mainFrame := core.NewFrame(splitBody)
+	mainFrame.SetName("mainSplitFrame")
+	mainFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+
+	settingFrame := core.NewFrame(mainFrame)
+	settingFrame.SetName("settingFrame")
+	splitWindow.addSetting(settingFrame)
+
+	splitWindow.addPartList(mainFrame)
+
+	buttonsFrame := core.NewFrame(mainFrame)
+	buttonsFrame.SetName("buttonsFrame")
+	splitWindow.addButtons(buttonsFrame)
+
+	return splitWindow
+}
+
+func (s *SplitWindow) addSetting(settingFrame *core.Frame) {
+	settingFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	dateField := s.newField(settingFrame).SetText(s.split.Date.Format(transactionDateLayout))
+	dateField.SetTooltip("Дата")
+	dateField.OnChange(func(e events.Event) {
+		date, err := parseOptionalDate(dateField.Text())
+		if err != nil || date.IsZero() {
+			core.MessageSnackbar(s.splitDialog, "Неверная дата, ожидается формат ДД.ММ.ГГГГ")
+			return
+		}
+
+		s.split.Date = date
+	})
+
+	amountField := s.newField(settingFrame).SetPlaceholder("Сумма")
+	if s.split.Amount != 0 {
+		amountField.SetText(FormatMoney(s.split.Amount))
+	}
+	amountField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(amountField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.splitDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.split = s.split.Rebalance(value)
+		s.listFrame.Update()
+		s.updateRest()
+	})
+
+	noteField := s.newField(settingFrame).SetText(s.split.Note).SetPlaceholder("Комментарий")
+	noteField.OnChange(func(e events.Event) {
+		s.split.Note = strings.TrimSpace(noteField.Text())
+	})
+
+	payeeField := s.newField(settingFrame).SetText(s.split.Payee).SetPlaceholder("Получатель")
+	addPayeeCompleter(payeeField, s.controller)
+	payeeField.OnChange(func(e events.Event) {
+		s.split.Payee = strings.TrimSpace(payeeField.Text())
+		s.fillPayeeCategory()
+	})
+
+	percentSwitch := core.NewSwitch(settingFrame).SetText("Доли в %")
+	percentSwitch.SetChecked(s.split.ByPercent)
+	percentSwitch.OnChange(func(e events.Event) {
+		s.split.ByPercent = percentSwitch.IsChecked()
+		if s.split.ByPercent {
+			s.fillPercents()
+		}
+		s.listFrame.Update()
+		s.updateRest()
+	})
+}
+
+// fillPayeeCategory
+// незаполненные части получают категорию получателя по умолчанию
+func (s *SplitWindow) fillPayeeCategory() {
+	payee, ok := s.controller.GetPayee(s.split.Payee)
+	if !ok || !payee.HasCategory() {
+		return
+	}
+
+	changed := false
+	for i, part := range s.split.Parts {
+		if part.Amount == 0 && part.Percent == 0 {
+			s.split.Parts[i].MainCategory = payee.MainCategory
+			s.split.Parts[i].Category = payee.Category
+			changed = true
+		}
+	}
+
+	if changed {
+		s.listFrame.Update()
+	}
+}
+
+// fillPercents
+// при переходе на доли они считаются из текущих сумм частей
+func (s *SplitWindow) fillPercents() {
+	if s.split.Amount == 0 {
+		return
+	}
+
+	for i := range s.split.Parts {
+		s.split.Parts[i].Percent = int(int64(s.split.Parts[i].Amount) * 100 * 100 / int64(s.split.Amount))
+	}
+}
+
+func (s *SplitWindow) addPartList(mainFrame *core.Frame) {
+	addHeader(mainFrame, "Категория", "Сумма", "Доля, %")
+
+	s.listFrame = core.NewFrame(mainFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i, part := range s.split.Parts {
+			// суммы и категория в имени строки, чтобы после пересчета строки создавались заново
+			name := "part_" + strconv.Itoa(i) + "_" + part.Amount.String() + "_" + strconv.Itoa(part.Percent) +
+				"_" + strconv.FormatBool(s.split.ByPercent) + "_" + utils.GetCompositeCategory(part.MainCategory, part.Category)
+			tree.AddAt(p, name, func(row *core.Frame) {
+				s.addPartRow(row, i)
+			})
+		}
+	})
+
+	restFrame := core.NewFrame(mainFrame)
+	restFrame.SetName("restFrame")
+	s.textRest = core.NewText(restFrame)
+	s.updateRest()
+
+	addButton := core.NewButton(mainFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить часть")
+	addButton.OnClick(func(e events.Event) {
+		part := domain.SplitPart{}
+		if len(s.categories) != 0 {
+			ref := s.categories[0].Value.(categoryRef)
+			part.MainCategory, part.Category = ref.mainCategory, ref.name
+		}
+
+		payee, ok := s.controller.GetPayee(s.split.Payee)
+		if ok && payee.HasCategory() {
+			part.MainCategory, part.Category = payee.MainCategory, payee.Category
+		}
+
+		s.split.Parts = append(s.split.Parts, part)
+		s.listFrame.Update()
+		s.updateRest()
+	})
+}
+
+func (s *SplitWindow) addPartRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	part := s.split.Parts[idx]
+
+	categoryChooser := core.NewChooser(row).SetItems(s.categories...).
+		SetCurrentValue(categoryRef{mainCategory: part.MainCategory, name: part.Category})
+	categoryChooser.OnChange(func(e events.Event) {
+		ref, ok := categoryChooser.CurrentItem.Value.(categoryRef)
+		if ok {
+			s.split.Parts[idx].MainCategory = ref.mainCategory
+			s.split.Parts[idx].Category = ref.name
+		}
+	})
+
+	amountField := s.newField(row).SetPlaceholder("0")
+	if part.Amount != 0 {
+		amountField.SetText(FormatMoney(part.Amount))
+	}
+	amountField.SetReadOnly(s.split.ByPercent)
+	amountField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(amountField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.splitDialog, "Неверная сумма: "+err.Error())
+			return
+		}
+
+		s.split.Parts[idx].Amount = value
+		s.updateRest()
+	})
+
+	percentField := s.newField(row).SetPlaceholder("0")
+	if part.Percent != 0 {
+		percentField.SetText(FormatMoney(entity.Money(part.Percent)))
+	}
+	percentField.SetReadOnly(!s.split.ByPercent)
+	percentField.OnChange(func(e events.Event) {
+		value, err := parseMoneyInput(strings.TrimSuffix(strings.TrimSpace(percentField.Text()), "%"))
+		if err != nil || value < 0 {
+			core.MessageSnackbar(s.splitDialog, "Неверная доля")
+			return
+		}
+
+		s.split.Parts[idx].Percent = int(value)
+		s.split = s.split.Rebalance(s.split.Amount)
+		s.listFrame.Update()
+		s.updateRest()
+	})
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить часть")
+	deleteButton.OnClick(func(e events.Event) {
+		parts := s.split.Parts
+		s.split.Parts = append(parts[:idx:idx], parts[idx+1:]...)
+		s.listFrame.Update()
+		s.updateRest()
+	})
+}
+
+// updateRest
+// нераспределенный остаток суммы или долей
+func (s *SplitWindow) updateRest() {
+	if s.textRest == nil {
+		return
+	}
+
+	var amount entity.Money
+	var percent int
+	for _, part := range s.split.Parts {
+		amount += part.Amount
+		percent += part.Percent
+	}
+
+	text := "Не распределено: " + FormatMoney(s.split.Amount-amount)
+	if s.split.ByPercent {
+		text = "Не распределено: " + FormatMoney(entity.Money(100*100-percent)) + "%"
+	}
+
+	s.textRest.SetText(text)
+	s.textRest.Update()
+}
+
+func (s *SplitWindow) newField(parent *core.Frame) *core.TextField {
+	tField := core.NewTextField(parent)
+	tField.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(120)
+		s.Max.X.Dp(120)
+	})
+
+	return tField
+}
+
+func (s *SplitWindow) addButtons(buttonsFrame *core.Frame) {
+	buttonsFrame.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
+	cancelButton.OnClick(func(e events.Event) {
+		s.close()
+	})
+
+	if len(s.split.Id) != 0 {
+		deleteButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetIcon(icons.Delete).
+			SetText("Удалить")
+		deleteButton.OnClick(func(e events.Event) {
+			ctx := context.Background()
+
+			cells, err := s.controller.DeleteSplit(ctx, s.split.Id)
+			if err != nil {
+				core.MessageSnackbar(s.splitDialog, "Ошибка удаления: "+err.Error())
+				s.logger.Error(ctx, "delete split error", log.Any("err", err.Error()))
+				return
+			}
+
+			s.close()
+			s.onSave(cells)
+		})
+	}
+
+	core.NewStretch(buttonsFrame)
+
+	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
+	saveButton.OnClick(func(e events.Event) {
+		ctx := context.Background()
+
+		cells, err := s.controller.UpdateSplit(ctx, s.split)
+		if err != nil {
+			core.MessageSnackbar(s.splitDialog, "Ошибка сохранения: "+err.Error())
+			s.logger.Error(ctx, "update split error", log.Any("err", err.Error()))
+			return
+		}
+
+		s.close()
+		s.onSave(cells)
+	})
+}
+
+func (s *SplitWindow) Run(ctx core.Widget) {
+	stage := s.splitDialog.NewDialog(ctx)
+	stage.Run()
+}
+
+func (s *SplitWindow) close() {
+	s.splitDialog.Close()
+}
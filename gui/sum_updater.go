@@ -2,35 +2,43 @@ package gui
 
 import (
 	"context"
+	"strings"
 	"sync"
 
+	"table-app/domain"
 	"table-app/entity"
 	"table-app/internal/log"
-	"table-app/utils"
 
 	"cogentcore.org/core/core"
 )
 
 type SumUpdater struct {
 	logger            log.Logger
-	consumptionFields map[string]*core.Text
-	balanceFields     map[string]*core.Text
+	consumptionFields map[entity.MonthYear]*core.Text
+	balanceFields     map[entity.MonthYear]*core.Text
 	controller        TableController
 
+	// showAccounts - остаток выводится с разбивкой по счетам
+	showAccounts bool
+
 	lock       sync.Mutex
 	wgGroup    sync.WaitGroup
 	updateChan chan entity.MonthYear
+	// done закрывается при закрытии окна: канал обновлений не закрывается, чтобы отправка
+	// из фоновой горутины, например планировщика, не паниковала на закрытом канале
+	done chan struct{}
 }
 
 func NewSumUpdater(logger log.Logger, controller TableController) *SumUpdater {
 	return &SumUpdater{
 		logger:            logger,
-		consumptionFields: make(map[string]*core.Text),
-		balanceFields:     make(map[string]*core.Text),
+		consumptionFields: make(map[entity.MonthYear]*core.Text),
+		balanceFields:     make(map[entity.MonthYear]*core.Text),
 		controller:        controller,
 		lock:              sync.Mutex{},
 		wgGroup:           sync.WaitGroup{},
 		updateChan:        make(chan entity.MonthYear),
+		done:              make(chan struct{}),
 	}
 }
 
@@ -41,47 +49,50 @@ func (u *SumUpdater) Start() {
 func (u *SumUpdater) start() {
 	for {
 		select {
-		case date, isOpen := <-u.updateChan:
+		case <-u.done:
+			return
+		case date := <-u.updateChan:
 			{
-				if !isOpen {
-					return
-				}
-
 				// сначала изменяются расходы, затем остаток, так как он пересчитывается с учетом расходов
-				consumption := u.controller.GetConsumptionSum(date.Month, date.Year)
+				consumption, err := u.controller.GetConsumptionSum(date.Month, date.Year)
+				if err != nil {
+					u.logger.Error(context.Background(), "get consumption sum", log.Any("err", err))
+				}
 
-				balanceById, err := u.controller.UpsertBalance(date.Month, date.Year)
+				balanceByDate, err := u.controller.UpsertBalance(date.Month, date.Year)
 				if err != nil {
 					u.logger.Error(context.Background(), "get balance sum", log.Any("err", err))
-					continue
+					if balanceByDate == nil {
+						continue
+					}
 				}
 
-				compositeDate := utils.GetCompositeDate(date.Month, date.Year)
 				u.lock.Lock()
 
-				consumptionField, ok := u.consumptionFields[compositeDate]
+				consumptionField, ok := u.consumptionFields[date]
 				if !ok {
 					u.logger.Error(context.Background(), "not found consumption field",
-						log.String("compositeDate", compositeDate))
+						log.Int("month", date.Month), log.Int("year", date.Year))
+					u.lock.Unlock()
 					continue
 				}
 
-				consumptionField.SetText(FormatInt(consumption, addMinus))
+				consumptionField.SetText(FormatMoney(consumption, addMinus))
 				consumptionField.Update()
 
-				for id, balance := range balanceById {
-					balanceField, ok := u.balanceFields[id]
+				for balanceDate, balance := range balanceByDate {
+					balanceField, ok := u.balanceFields[balanceDate]
 					if !ok {
 						u.logger.Error(context.Background(), "not found balance field",
-							log.String("compositeDate", compositeDate))
+							log.Int("month", balanceDate.Month), log.Int("year", balanceDate.Year))
 						continue
 					}
-					balanceField.SetText(FormatInt(balance))
+					balanceField.SetText(u.formatBalance(balanceDate, balance))
 					balanceField.Update()
-					u.balanceFields[id] = balanceField
+					u.balanceFields[balanceDate] = balanceField
 				}
 
-				u.consumptionFields[compositeDate] = consumptionField
+				u.consumptionFields[date] = consumptionField
 
 				u.lock.Unlock()
 			}
@@ -89,18 +100,99 @@ func (u *SumUpdater) start() {
 	}
 }
 
+// RefreshAll
+// обновляет расходы и остатки всех отображаемых месяцев, например после изменения курсов валют
+func (u *SumUpdater) RefreshAll() {
+	u.lock.Lock()
+	dates := make([]entity.MonthYear, 0, len(u.consumptionFields))
+	for date := range u.consumptionFields {
+		dates = append(dates, date)
+	}
+	u.lock.Unlock()
+
+	for _, date := range dates {
+		u.Send(date)
+	}
+}
+
+// SetShowAccounts
+// переключает вывод остатка с разбивкой по счетам и обновляет все остатки
+func (u *SumUpdater) SetShowAccounts(show bool) {
+	u.lock.Lock()
+	u.showAccounts = show
+	u.lock.Unlock()
+
+	u.RefreshAll()
+}
+
+// formatBalance
+// текст остатка месяца; если в месяце была сверка, ниже выводится ее расхождение с расчетом
+func (u *SumUpdater) formatBalance(date entity.MonthYear, total entity.Money) string {
+	text := u.formatAccounts(date, total)
+
+	diff, ok := u.controller.GetCheckpointDiff(date.Month, date.Year)
+	if !ok || diff == 0 {
+		return text
+	}
+
+	sign := ""
+	if diff > 0 {
+		sign = "+"
+	}
+
+	return text + "\nСверка: " + sign + FormatMoney(diff)
+}
+
+// formatAccounts
+// расчетный остаток; в режиме разбивки по счетам - построчно "счет: остаток",
+// условный счет без названия выводится, только если его остаток не нулевой
+func (u *SumUpdater) formatAccounts(date entity.MonthYear, total entity.Money) string {
+	if !u.showAccounts {
+		return FormatMoney(total)
+	}
+
+	accounts := u.controller.GetAccounts()
+	if len(accounts) == 0 {
+		return FormatMoney(total)
+	}
+
+	balances := u.controller.GetAccountBalances(date.Month, date.Year)
+
+	lines := make([]string, 0, len(accounts)+1)
+	for _, account := range accounts {
+		lines = append(lines, account.Name+": "+FormatMoney(balances[account.Id]))
+	}
+
+	if unassigned := balances[domain.UnassignedAccountId]; unassigned != 0 {
+		lines = append(lines, domain.UnassignedAccountName+": "+FormatMoney(unassigned))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Send
+// передает обновление в горутину отрисовки; после закрытия окна обновление отбрасывается
+func (u *SumUpdater) Send(date entity.MonthYear) {
+	select {
+	case u.updateChan <- date:
+	case <-u.done:
+	}
+}
+
 func (u *SumUpdater) Close() {
-	close(u.updateChan)
+	close(u.done)
 }
 
 func (u *SumUpdater) AddConsumptionText(month, year int, tField *core.Text) {
 	u.lock.Lock()
 
-	sum := u.controller.GetConsumptionSum(month, year)
-	tField.SetText(FormatInt(sum, addMinus))
+	sum, err := u.controller.GetConsumptionSum(month, year)
+	if err != nil {
+		u.logger.Error(context.Background(), "get consumption sum", log.Any("err", err))
+	}
+	tField.SetText(FormatMoney(sum, addMinus))
 
-	compositeDate := utils.GetCompositeDate(month, year)
-	u.consumptionFields[compositeDate] = tField
+	u.consumptionFields[entity.MonthYear{Month: month, Year: year}] = tField
 
 	u.lock.Unlock()
 }
@@ -108,7 +200,7 @@ func (u *SumUpdater) AddConsumptionText(month, year int, tField *core.Text) {
 func (u *SumUpdater) AddBalanceText(month, year int, tField *core.Text) {
 	u.lock.Lock()
 
-	var sum int
+	var sum entity.Money
 	var err error
 
 	sum, err = u.controller.GetBalanceSum(month, year)
@@ -117,10 +209,9 @@ func (u *SumUpdater) AddBalanceText(month, year int, tField *core.Text) {
 		sum = 0
 	}
 
-	tField.SetText(FormatInt(sum))
-
-	compositeDate := utils.GetCompositeDate(month, year)
-	u.balanceFields[compositeDate] = tField
+	date := entity.MonthYear{Month: month, Year: year}
+	tField.SetText(u.formatBalance(date, sum))
+	u.balanceFields[date] = tField
 
 	u.lock.Unlock()
 }
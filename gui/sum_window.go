@@ -2,8 +2,10 @@ package gui
 
 import (
 	"context"
-	"strconv"
+	"strings"
+	"time"
 
+	"table-app/conf"
 	"table-app/domain"
 	"table-app/entity"
 	"table-app/internal/log"
@@ -11,25 +13,36 @@ import (
 	"cogentcore.org/core/core"
 	"cogentcore.org/core/events"
 	"cogentcore.org/core/events/key"
+	"cogentcore.org/core/icons"
 	"cogentcore.org/core/styles"
+	"cogentcore.org/core/tree"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
+const transactionDateLayout = "02.01.2006"
+
 type SumWindow struct {
 	logger    log.Logger
 	mainFrame *core.Frame
 	sumDialog *core.Body
-	sumFrame  *core.Frame
-	tFields   []*core.TextField
-
-	controller    TableController
-	cell          domain.Cell
-	sum           int
-	updateChan    chan domain.Cell
-	updateSumChan chan entity.MonthYear
+	listFrame *core.Frame
+	textSum   *core.Text
+
+	attachmentList *AttachmentList
+
+	controller   TableController
+	cell         domain.Cell
+	categories   [][]domain.Category
+	accounts     []domain.Account
+	transactions []domain.Transaction
+	sum          entity.Money
+	updater      *Updater
+	sumUpdater   *SumUpdater
 }
 
 func NewSumWindow(logger log.Logger, mainFrame *core.Frame, cell domain.Cell, controller TableController,
-	updateChan chan domain.Cell, updateSumChan chan entity.MonthYear) *SumWindow {
+	categories [][]domain.Category, settings conf.Setting, updater *Updater, sumUpdater *SumUpdater) *SumWindow {
 	sumBody := core.NewBody("Sum").SetTitle(cell.Category)
 	sumBody.Styler(func(s *styles.Style) {
 		s.Align.Self = styles.Center
@@ -49,10 +62,6 @@ func NewSumWindow(logger log.Logger, mainFrame *core.Frame, cell domain.Cell, co
 		s.Direction = styles.Column
 		s.CenterAll()
 	})
-	firstTField := core.NewTextField(sumFrame)
-	secondTField := core.NewTextField(sumFrame)
-
-	tFields := []*core.TextField{firstTField, secondTField}
 
 	rightFrame := core.NewFrame(mainSumFrame)
 	rightFrame.SetName("rightFrame")
@@ -64,53 +73,305 @@ func NewSumWindow(logger log.Logger, mainFrame *core.Frame, cell domain.Cell, co
 	textSumFrame := core.NewFrame(rightFrame)
 	textSumFrame.SetName("textSumFrame")
 
+	currencyFrame := core.NewFrame(rightFrame)
+	currencyFrame.SetName("currencyFrame")
+
 	_ = core.NewSeparator(rightFrame)
 
 	buttonsFrame := core.NewFrame(rightFrame)
 	buttonsFrame.SetName("buttonsFrame")
 
 	sumWindow := &SumWindow{
-		logger:        logger,
-		mainFrame:     mainFrame,
-		sumDialog:     sumBody,
-		sumFrame:      sumFrame,
-		controller:    controller,
-		cell:          cell,
-		tFields:       tFields,
-		updateChan:    updateChan,
-		updateSumChan: updateSumChan,
-		sum:           0,
+		logger:       logger,
+		mainFrame:    mainFrame,
+		sumDialog:    sumBody,
+		controller:   controller,
+		cell:         cell,
+		categories:   categories,
+		accounts:     controller.GetAccounts(),
+		transactions: initTransactions(cell),
+		updater:      updater,
+		sumUpdater:   sumUpdater,
+		sum:          0,
 	}
-	sumWindow.addButtons(buttonsFrame)
-	textSum := sumWindow.addTextSum(textSumFrame)
+	sumWindow.recalculateSum()
 
-	sumWindow.addInputFunction(textSum, tFields...)
+	_ = core.NewSeparator(rightFrame)
+
+	attachmentFrame := core.NewFrame(rightFrame)
+	attachmentFrame.SetName("attachmentFrame")
+	attachmentFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+		s.CenterAll()
+	})
+	sumWindow.attachmentList = NewAttachmentList(logger, sumBody, attachmentFrame, controller, cell.Key(),
+		func() []domain.Transaction {
+			return sumWindow.transactions
+		})
+
+	sumWindow.addButtons(buttonsFrame)
+	sumWindow.textSum = sumWindow.addTextSum(textSumFrame)
+	sumWindow.addCurrencyChooser(currencyFrame, settings)
+	sumWindow.addAccountChooser(currencyFrame)
+	sumWindow.addTransactionList(sumFrame)
 
 	return sumWindow
 }
 
-func (s *SumWindow) addInputFunction(textSum *core.Text, tFields ...*core.TextField) {
-	for i := range tFields {
-		tFields[i].Styler(func(s *styles.Style) {
+// initTransactions
+// копия операций ячейки для редактирования; значение ячейки, введенное до появления
+// операций, превращается в первую операцию, чтобы не потеряться при добавлении новых
+func initTransactions(cell domain.Cell) []domain.Transaction {
+	transactions := make([]domain.Transaction, 0, len(cell.Transactions)+1)
+	transactions = append(transactions, cell.Transactions...)
+
+	if len(cell.ActiveTransactions()) == 0 && cell.Value != 0 {
+		transactions = append(transactions, domain.Transaction{
+			Id:        uuid.New().String(),
+			Date:      cell.DefaultDate(),
+			Amount:    cell.Value,
+			IsUpdated: true,
+		})
+	}
+
+	return transactions
+}
+
+// addCurrencyChooser
+// выбор валюты ячейки; без выбора используется валюта категории или базовая
+func (s *SumWindow) addCurrencyChooser(currencyFrame *core.Frame, settings conf.Setting) {
+	currencies := settings.Currencies
+	if len(currencies) == 0 {
+		currencies = []string{settings.GetBaseCurrency()}
+	}
+
+	currency := domain.ResolveCurrency(s.cell.Currency, "", settings.GetBaseCurrency())
+
+	chooser := core.NewChooser(currencyFrame).SetStrings(currencies...).SetCurrentValue(currency)
+	chooser.OnChange(func(e events.Event) {
+		value, ok := chooser.CurrentItem.Value.(string)
+		if ok {
+			s.cell.Currency = value
+		}
+	})
+}
+
+// addAccountChooser
+// выбор счета ячейки; операции без своего счета относятся к нему
+func (s *SumWindow) addAccountChooser(currencyFrame *core.Frame) {
+	if len(s.accounts) == 0 {
+		return
+	}
+
+	items := []core.ChooserItem{{Value: domain.UnassignedAccountId, Text: domain.UnassignedAccountName}}
+	items = append(items, s.accountItems()...)
+
+	chooser := core.NewChooser(currencyFrame).SetItems(items...).SetCurrentValue(s.cell.AccountId)
+	chooser.OnChange(func(e events.Event) {
+		value, ok := chooser.CurrentItem.Value.(string)
+		if ok {
+			s.cell.AccountId = value
+		}
+	})
+}
+
+func (s *SumWindow) accountItems() []core.ChooserItem {
+	items := make([]core.ChooserItem, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		items = append(items, core.ChooserItem{Value: account.Id, Text: account.Name})
+	}
+
+	return items
+}
+
+func (s *SumWindow) addTransactionList(sumFrame *core.Frame) {
+	titles := []string{"Дата", "Сумма", "Комментарий", "Получатель"}
+	if len(s.accounts) != 0 {
+		titles = append(titles, "Счет")
+	}
+
+	headFrame := core.NewFrame(sumFrame)
+	headFrame.SetName("headFrame")
+	for _, title := range titles {
+		titleFrame := core.NewFrame(headFrame)
+		titleFrame.Styler(func(s *styles.Style) {
 			s.Min.X.Dp(120)
 		})
+		core.NewText(titleFrame).SetType(core.TextLabelLarge).SetText(title)
+	}
+
+	s.listFrame = core.NewFrame(sumFrame)
+	s.listFrame.SetName("listFrame")
+	s.listFrame.Styler(func(s *styles.Style) {
+		s.Direction = styles.Column
+	})
+
+	s.listFrame.Maker(func(p *tree.Plan) {
+		for i := range s.transactions {
+			if s.transactions[i].IsDeleted {
+				continue
+			}
+
+			tree.AddAt(p, "transaction_"+s.transactions[i].Id, func(row *core.Frame) {
+				s.addTransactionRow(row, i)
+			})
+		}
+	})
+
+	addButton := core.NewButton(sumFrame).SetType(core.ButtonTonal).SetIcon(icons.Add).SetText("Добавить")
+	addButton.OnClick(func(e events.Event) {
+		s.transactions = append(s.transactions, domain.Transaction{
+			Id:        uuid.New().String(),
+			Date:      s.cell.DefaultDate(),
+			IsUpdated: true,
+		})
+		s.listFrame.Update()
+	})
+}
+
+func (s *SumWindow) addTransactionRow(row *core.Frame, idx int) {
+	row.Styler(func(s *styles.Style) {
+		s.CenterAll()
+	})
+
+	transaction := s.transactions[idx]
+
+	// часть разделенной операции меняется только вместе с группой
+	split, isSplit := s.controller.GetSplitByTransaction(transaction.Id)
+
+	dateField := s.newRowField(row).SetText(transaction.Date.Format(transactionDateLayout))
+	dateField.OnChange(func(e events.Event) {
+		date, err := s.parseDate(dateField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.sumDialog, "Неверная дата: "+err.Error())
+			return
+		}
 
-		tFields[i].OnChange(func(e events.Event) {
-			val, err := strconv.Atoi(tFields[i].Text())
-			if err != nil {
-				core.MessageSnackbar(s.sumDialog, "Неверный формат данных: "+err.Error())
-				return
+		s.transactions[idx].Date = date
+		s.transactions[idx].IsUpdated = true
+	})
+
+	amountField := s.newRowField(row).SetPlaceholder("0")
+	if transaction.Amount != 0 {
+		amountField.SetText(FormatMoney(transaction.Amount))
+	}
+	amountField.SetReadOnly(isSplit)
+	amountField.OnChange(func(e events.Event) {
+		val, err := entity.ParseMoney(amountField.Text())
+		if err != nil {
+			core.MessageSnackbar(s.sumDialog, "Неверный формат данных: "+err.Error())
+			return
+		}
+
+		s.transactions[idx].Amount = val
+		s.transactions[idx].IsUpdated = true
+		amountField.SetText(FormatMoney(val))
+		s.recalculateSum()
+	})
+
+	noteField := s.newRowField(row).SetText(transaction.Note)
+	noteField.OnChange(func(e events.Event) {
+		s.transactions[idx].Note = noteField.Text()
+		s.transactions[idx].IsUpdated = true
+	})
+
+	payeeField := s.newRowField(row).SetText(transaction.Payee)
+	addPayeeCompleter(payeeField, s.controller)
+	payeeField.OnChange(func(e events.Event) {
+		s.transactions[idx].Payee = payeeField.Text()
+		s.transactions[idx].IsUpdated = true
+		s.hintPayeeCategory(payeeField.Text())
+	})
+
+	if len(s.accounts) != 0 {
+		items := []core.ChooserItem{{Value: domain.UnassignedAccountId, Text: "Счет ячейки"}}
+		items = append(items, s.accountItems()...)
+
+		accountChooser := core.NewChooser(row).SetItems(items...).SetCurrentValue(transaction.AccountId)
+		accountChooser.OnChange(func(e events.Event) {
+			value, ok := accountChooser.CurrentItem.Value.(string)
+			if ok {
+				s.transactions[idx].AccountId = value
+				s.transactions[idx].IsUpdated = true
 			}
+		})
+	}
+
+	attachButton := s.attachmentList.AddAttachButton(row, transaction.Id, "")
+	attachButton.SetTooltip("Прикрепить чек к операции")
 
-			s.cell.Value += val
-			s.sum += val
-			textSum.Update()
-			tFields[i].SetText(FormatInt(val))
+	if isSplit {
+		dateField.SetReadOnly(true)
 
-			newTField := core.NewTextField(s.sumFrame)
-			s.addInputFunction(textSum, newTField)
-			s.sumFrame.Update()
+		splitButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.AltRoute)
+		splitButton.SetTooltip("Разделенная операция: " + FormatMoney(split.Amount))
+		splitButton.OnClick(func(e events.Event) {
+			s.openSplit(split, row)
 		})
+		return
+	}
+
+	deleteButton := core.NewButton(row).SetType(core.ButtonAction).SetIcon(icons.Delete)
+	deleteButton.SetTooltip("Удалить операцию")
+	deleteButton.OnClick(func(e events.Event) {
+		s.transactions[idx].IsDeleted = true
+		s.recalculateSum()
+		s.listFrame.Update()
+	})
+}
+
+// hintPayeeCategory
+// подсказывает категорию получателя по умолчанию, если она отличается от категории ячейки
+func (s *SumWindow) hintPayeeCategory(name string) {
+	payee, ok := s.controller.GetPayee(name)
+	if !ok || !payee.HasCategory() {
+		return
+	}
+
+	if payee.MainCategory == s.cell.MainCategory && payee.Category == s.cell.Category {
+		return
+	}
+
+	core.MessageSnackbar(s.sumDialog, "Обычная категория получателя "+payee.Name+": "+
+		payee.MainCategory+" / "+payee.Category)
+}
+
+func (s *SumWindow) newRowField(row *core.Frame) *core.TextField {
+	tField := core.NewTextField(row)
+	tField.Styler(func(s *styles.Style) {
+		s.Min.X.Dp(120)
+		s.Max.X.Dp(120)
+	})
+
+	return tField
+}
+
+// parseDate
+// разбирает дату операции, дата должна относиться к месяцу ячейки
+func (s *SumWindow) parseDate(text string) (time.Time, error) {
+	date, err := time.Parse(transactionDateLayout, strings.TrimSpace(text))
+	if err != nil {
+		return time.Time{}, errors.New("ожидается формат ДД.ММ.ГГГГ")
+	}
+
+	if date.Month() != s.cell.Month || date.Year() != s.cell.Year {
+		return time.Time{}, errors.New("дата вне месяца ячейки")
+	}
+
+	return date, nil
+}
+
+func (s *SumWindow) recalculateSum() {
+	s.sum = 0
+	for _, transaction := range s.transactions {
+		if !transaction.IsDeleted {
+			s.sum += transaction.Amount
+		}
+	}
+
+	if s.textSum != nil {
+		s.textSum.SetText(FormatMoney(s.sum))
+		s.textSum.Update()
 	}
 }
 
@@ -122,6 +383,16 @@ func (s *SumWindow) addButtons(buttonsFrame *core.Frame) {
 
 	saveButton := core.NewButton(buttonsFrame).SetType(core.ButtonFilled).SetText("Сохранить")
 	saveButton.OnClick(func(e events.Event) {
+		// пустые строки, добавленные, но не заполненные, не сохраняются
+		for i, transaction := range s.transactions {
+			if transaction.Amount == 0 && len(transaction.Note) == 0 && len(transaction.Payee) == 0 {
+				s.transactions[i].IsDeleted = true
+			}
+		}
+
+		s.cell.Transactions = s.transactions
+		s.cell.Value = s.sum
+
 		err := s.controller.UpsertValue(context.Background(), s.cell)
 		if err != nil {
 			core.MessageSnackbar(s.sumDialog, "Ошибка сохранения данных: "+err.Error())
@@ -129,20 +400,34 @@ func (s *SumWindow) addButtons(buttonsFrame *core.Frame) {
 			return
 		}
 
-		if s.updateChan != nil {
-			s.updateChan <- s.cell
+		if s.updater != nil {
+			s.updater.Send(s.cell)
 		}
 
-		if s.updateSumChan != nil {
-			s.updateSumChan <- entity.MonthYear{
+		if s.sumUpdater != nil {
+			s.sumUpdater.Send(entity.MonthYear{
 				Month: int(s.cell.Month),
 				Year:  s.cell.Year,
-			}
+			})
 		}
 
 		s.close()
 
-		core.MessageSnackbar(s.mainFrame, "Введено: "+strconv.Itoa(s.sum))
+		core.MessageSnackbar(s.mainFrame, "Введено: "+FormatMoney(s.sum))
+	})
+
+	splitButton := core.NewButton(buttonsFrame).SetType(core.ButtonTonal).SetIcon(icons.AltRoute).
+		SetText("Разделить")
+	splitButton.SetTooltip("Разделить одну сумму между несколькими категориями")
+	splitButton.OnClick(func(e events.Event) {
+		s.openSplit(domain.Split{
+			Date:      s.cell.DefaultDate(),
+			AccountId: s.cell.AccountId,
+			Parts: []domain.SplitPart{{
+				MainCategory: s.cell.MainCategory,
+				Category:     s.cell.Category,
+			}},
+		}, splitButton)
 	})
 
 	cancelButton := core.NewButton(buttonsFrame).SetType(core.ButtonElevated).SetText("Отмена")
@@ -151,6 +436,31 @@ func (s *SumWindow) addButtons(buttonsFrame *core.Frame) {
 	})
 }
 
+// openSplit
+// окно разделенной операции; после сохранения операции ячейки меняются, поэтому окно суммы закрывается
+// без сохранения, а все затронутые ячейки и итоги их месяцев обновляются
+func (s *SumWindow) openSplit(split domain.Split, ctx core.Widget) {
+	splitWindow := NewSplitWindow(s.logger, s.controller, s.categories, split, func(cells []domain.Cell) {
+		s.close()
+
+		months := make(map[entity.MonthYear]bool)
+		for _, cell := range cells {
+			if s.updater != nil {
+				s.updater.Send(cell)
+			}
+
+			months[entity.MonthYear{Month: int(cell.Month), Year: cell.Year}] = true
+		}
+
+		if s.sumUpdater != nil {
+			for month := range months {
+				s.sumUpdater.Send(month)
+			}
+		}
+	})
+	splitWindow.Run(ctx)
+}
+
 func (s *SumWindow) addTextSum(textSumFrame *core.Frame) *core.Text {
 	textSumFrame.Styler(func(s *styles.Style) {
 		s.Direction = styles.Column
@@ -170,8 +480,7 @@ func (s *SumWindow) addTextSum(textSumFrame *core.Frame) *core.Text {
 	textValueFrame := core.NewFrame(textSumFrame)
 	textValue := core.NewText(textValueFrame).
 		SetType(core.TextHeadlineSmall).
-		SetText(FormatInt(s.sum))
-	core.Bind(&s.sum, textValue)
+		SetText(FormatMoney(s.sum))
 	return textValue
 }
 
@@ -2,7 +2,6 @@ package gui
 
 import (
 	"context"
-	"strconv"
 	"sync"
 
 	"table-app/domain"
@@ -12,21 +11,34 @@ import (
 )
 
 type Updater struct {
-	logger   log.Logger
-	guiCells map[string]*core.TextField
+	logger       log.Logger
+	guiCells     map[domain.CellKey]*core.TextField
+	rollUpFields map[domain.CellKey]*core.TextField
+	baseCurrency string
+	controller   TableController
+
+	// onUpdate - вызывается после обновления ячейки, например для пересчета сумм родительских категорий
+	onUpdate func(cell domain.Cell)
 
 	lock       sync.Mutex
 	wgGroup    sync.WaitGroup
 	updateChan chan domain.Cell
+	// done закрывается при закрытии окна: канал обновлений не закрывается, чтобы отправка
+	// из фоновой горутины, например планировщика, не паниковала на закрытом канале
+	done chan struct{}
 }
 
-func NewUpdater(logger log.Logger) *Updater {
+func NewUpdater(logger log.Logger, baseCurrency string, controller TableController) *Updater {
 	return &Updater{
-		logger:     logger,
-		guiCells:   make(map[string]*core.TextField),
-		lock:       sync.Mutex{},
-		wgGroup:    sync.WaitGroup{},
-		updateChan: make(chan domain.Cell),
+		logger:       logger,
+		guiCells:     make(map[domain.CellKey]*core.TextField),
+		rollUpFields: make(map[domain.CellKey]*core.TextField),
+		baseCurrency: baseCurrency,
+		controller:   controller,
+		lock:         sync.Mutex{},
+		wgGroup:      sync.WaitGroup{},
+		updateChan:   make(chan domain.Cell),
+		done:         make(chan struct{}),
 	}
 }
 
@@ -37,35 +49,76 @@ func (u *Updater) Start() {
 func (u *Updater) start() {
 	for {
 		select {
-		case cell, isOpen := <-u.updateChan:
+		case <-u.done:
+			return
+		case cell := <-u.updateChan:
 			{
-				if !isOpen {
-					return
-				}
-
-				compositeId := cell.MainCategory + cell.Category + strconv.Itoa(int(cell.Month)) + strconv.Itoa(cell.Year)
+				key := cell.Key()
 				u.lock.Lock()
-				tField, ok := u.guiCells[compositeId]
+				tField, ok := u.guiCells[key]
 				if !ok {
-					u.logger.Error(context.Background(), "cell not found by id",
-						log.String("compositeId", compositeId))
+					u.logger.Error(context.Background(), "cell not found by key",
+						log.String("key", key.String()))
+					u.lock.Unlock()
 					continue
 				}
 
-				tField.SetText(FormatInt(cell.Value))
-				u.guiCells[compositeId] = tField
+				// план ячейки не меняется в окне суммы, но выводится рядом с фактом
+				plan, hasPlan := u.controller.GetPlanById(key)
+				tField.SetText(formatCellText(FormatMoney(cell.Value, withCurrency(cell.Currency, u.baseCurrency)),
+					plan, hasPlan))
+				tField.SetTooltip(planTooltip(cell.Value, plan, hasPlan))
+				u.guiCells[key] = tField
 				u.lock.Unlock()
+
+				if u.onUpdate != nil {
+					u.onUpdate(cell)
+				}
 			}
 		}
 	}
 }
 
+// Send
+// передает обновление в горутину отрисовки; после закрытия окна обновление отбрасывается
+func (u *Updater) Send(cell domain.Cell) {
+	select {
+	case u.updateChan <- cell:
+	case <-u.done:
+	}
+}
+
 func (u *Updater) Close() {
-	close(u.updateChan)
+	close(u.done)
+}
+
+func (u *Updater) AddTextField(key domain.CellKey, tField *core.TextField) {
+	u.lock.Lock()
+	u.guiCells[key] = tField
+	u.lock.Unlock()
 }
 
-func (u *Updater) AddTextField(compositeId string, tField *core.TextField) {
+func (u *Updater) OnUpdate(onUpdate func(cell domain.Cell)) {
+	u.onUpdate = onUpdate
+}
+
+// AddRollUpField
+// поле родительской категории, в котором выводится сумма вместе с подкатегориями
+func (u *Updater) AddRollUpField(key domain.CellKey, tField *core.TextField) {
 	u.lock.Lock()
-	u.guiCells[compositeId] = tField
+	u.rollUpFields[key] = tField
 	u.lock.Unlock()
 }
+
+func (u *Updater) SetRollUpText(key domain.CellKey, text string) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	tField, ok := u.rollUpFields[key]
+	if !ok {
+		return
+	}
+
+	tField.SetText(text)
+	tField.Update()
+}
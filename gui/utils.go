@@ -4,14 +4,32 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"table-app/domain"
+	"table-app/entity"
+
+	"github.com/pkg/errors"
 )
 
 type Option func(string) string
 
-// FormatInt форматирует число для вывода в графический интерфейс
-func FormatInt(i int, opts ...Option) string {
-	str := strconv.Itoa(i)
-	str = addSpaces(str, 3)
+// FormatMoney форматирует сумму для вывода в графический интерфейс: "1 234,56",
+// копейки выводятся, только если они есть
+func FormatMoney(m entity.Money, opts ...Option) string {
+	major := m.Major()
+	if major < 0 {
+		major = -major
+	}
+
+	str := addSpaces(strconv.FormatInt(major, 10), 3)
+	if minor := m.Minor(); minor != 0 {
+		str += "," + strconv.FormatInt(minor/10, 10) + strconv.FormatInt(minor%10, 10)
+	}
+
+	if m < 0 {
+		str = "-" + str
+	}
 
 	for _, opt := range opts {
 		str = opt(str)
@@ -20,6 +38,110 @@ func FormatInt(i int, opts ...Option) string {
 	return str
 }
 
+// withCurrency добавляет код валюты, если она отличается от базовой
+func withCurrency(currency, base string) Option {
+	return func(str string) string {
+		if len(currency) == 0 || currency == base {
+			return str
+		}
+
+		return str + " " + currency
+	}
+}
+
+// parseMoneyInput разбирает введенную сумму, отбрасывая код валюты после числа
+func parseMoneyInput(text string) (entity.Money, error) {
+	text = strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsSpace(r)
+	})
+
+	money, err := entity.ParseMoney(text)
+	if err != nil {
+		return 0, errors.WithMessage(err, "parse money")
+	}
+
+	return money, nil
+}
+
+// cellInput ввод в ячейку таблицы в виде "факт / план"; любая из частей может отсутствовать
+type cellInput struct {
+	actual    entity.Money
+	hasActual bool
+	plan      entity.Money
+	hasPlan   bool
+}
+
+// parseCellInput разбирает ввод в ячейку: без "/" вводится только факт,
+// "/ 5000" - только план, пустой план после "/" удаляет его
+func parseCellInput(text string) (cellInput, error) {
+	actualText, planText, hasPlan := strings.Cut(text, "/")
+
+	var input cellInput
+	var err error
+
+	if !hasPlan || len(strings.TrimSpace(actualText)) != 0 {
+		input.actual, err = parseMoneyInput(actualText)
+		if err != nil {
+			return cellInput{}, errors.WithMessage(err, "parse actual")
+		}
+		input.hasActual = true
+	}
+
+	if hasPlan {
+		input.hasPlan = true
+		if len(strings.TrimSpace(planText)) != 0 {
+			input.plan, err = parseMoneyInput(planText)
+			if err != nil {
+				return cellInput{}, errors.WithMessage(err, "parse plan")
+			}
+		}
+	}
+
+	return input, nil
+}
+
+// formatCellText текст ячейки таблицы: факт, а при наличии плана "факт / план"
+func formatCellText(actual string, plan domain.Plan, hasPlan bool) string {
+	if !hasPlan {
+		return actual
+	}
+
+	return strings.TrimSpace(actual + " / " + FormatMoney(plan.Value))
+}
+
+// planTooltip подсказка ячейки с планом, фактом и отклонением; без плана пустая
+func planTooltip(actual entity.Money, plan domain.Plan, hasPlan bool) string {
+	if !hasPlan {
+		return ""
+	}
+
+	result := domain.CategoryResult{Actual: actual, Plan: plan.Value}
+	return "План: " + FormatMoney(result.Plan) +
+		"\nФакт: " + FormatMoney(result.Actual) +
+		"\nОтклонение: " + FormatMoney(result.Variance(), withPlus(result.Variance()))
+}
+
+// formatResult итог категории за год: факт, а при наличии плана - план и отклонение
+func formatResult(result domain.CategoryResult) string {
+	if result.Plan == 0 {
+		return FormatMoney(result.Actual)
+	}
+
+	return FormatMoney(result.Actual) + "\nплан " + FormatMoney(result.Plan) +
+		" (" + FormatMoney(result.Variance(), withPlus(result.Variance())) + ")"
+}
+
+// withPlus добавляет "+" к положительной сумме, чтобы отклонение читалось однозначно
+func withPlus(m entity.Money) Option {
+	return func(str string) string {
+		if m <= 0 {
+			return str
+		}
+
+		return "+" + str
+	}
+}
+
 func addMinus(str string) string {
 	if str == "0" {
 		return str
@@ -59,6 +59,12 @@ func (c *Client) Upgrade(ctx context.Context, cfg StorageConfig) error {
 		return errors.WithMessage(err, "can't connect to database")
 	}
 
+	// новая бд получает схему, существующая - только недостающие изменения
+	err = c.migrate(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "migrate database")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,396 @@
+package db
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"table-app/internal/log"
+	"table-app/migrations"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// migrationSchema - схема таблиц приложения и таблицы версий
+const migrationSchema = "table_app"
+
+// migrationLockId - ключ advisory-блокировки: две копии приложения не применяют миграции одновременно
+const migrationLockId = 20240731085038
+
+const (
+	gooseUp   = "-- +goose Up"
+	gooseDown = "-- +goose Down"
+)
+
+// migration
+// версия и имя берутся из имени файла goose 20240731085038_init.sql, up - часть файла до Down
+type migration struct {
+	version int64
+	name    string
+	up      string
+}
+
+// migrate
+// применяет встроенные миграции, которых еще нет в таблице версий, каждую в своей транзакции
+func (c *Client) migrate(ctx context.Context) error {
+	items, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return errors.WithMessage(err, "load migrations")
+	}
+
+	err = c.createVersionTable(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "create version table")
+	}
+
+	err = c.baseline(ctx, items)
+	if err != nil {
+		return errors.WithMessage(err, "baseline existing schema")
+	}
+
+	for _, item := range items {
+		applied, err := c.applyMigration(ctx, item)
+		if err != nil {
+			return errors.WithMessagef(err, "apply migration %d_%s", item.version, item.name)
+		}
+
+		if applied {
+			c.logger.Info(ctx, "migration applied", log.Int64("version", item.version), log.String("name", item.name))
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) createVersionTable(ctx context.Context) error {
+	tx, err := c.cli.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin version table transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, migrationLockId)
+	if err != nil {
+		return errors.WithMessage(err, "lock migrations")
+	}
+
+	_, err = tx.Exec(ctx, `
+	CREATE SCHEMA IF NOT EXISTS `+migrationSchema+`;
+	CREATE TABLE IF NOT EXISTS `+migrationSchema+`.schema_version
+	(
+		version    BIGINT NOT NULL PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);`)
+	if err != nil {
+		return errors.WithMessage(err, "create schema")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit version table transaction")
+	}
+
+	return nil
+}
+
+// baseline
+// база, созданная до встроенных миграций: таблицы приложения уже есть, а таблица версий пуста.
+// Версии переносятся из goose_db_version, если миграции применялись goose, иначе примененными
+// считаются миграции до последней, чьи таблицы и колонки уже есть в схеме
+func (c *Client) baseline(ctx context.Context, items []migration) error {
+	tx, err := c.cli.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin baseline transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, migrationLockId)
+	if err != nil {
+		return errors.WithMessage(err, "lock migrations")
+	}
+
+	var versioned, existing bool
+	err = tx.QueryRow(ctx, `
+	SELECT EXISTS (SELECT 1 FROM `+migrationSchema+`.schema_version),
+	       to_regclass('`+migrationSchema+`.finances') IS NOT NULL;`).Scan(&versioned, &existing)
+	if err != nil {
+		return errors.WithMessage(err, "check existing schema")
+	}
+
+	if versioned || !existing {
+		return nil
+	}
+
+	applied, err := gooseVersions(ctx, tx)
+	if err != nil {
+		return errors.WithMessage(err, "read goose versions")
+	}
+
+	if len(applied) == 0 {
+		columns, err := schemaColumns(ctx, tx)
+		if err != nil {
+			return errors.WithMessage(err, "read schema columns")
+		}
+
+		applied = make(map[int64]bool)
+		for _, item := range items[:reflectedMigrations(items, columns)] {
+			applied[item.version] = true
+		}
+	}
+
+	for _, item := range items {
+		if !applied[item.version] {
+			continue
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO `+migrationSchema+`.schema_version (version, name) VALUES ($1, $2);`,
+			item.version, item.name)
+		if err != nil {
+			return errors.WithMessage(err, "insert version")
+		}
+
+		c.logger.Info(ctx, "migration baselined", log.Int64("version", item.version), log.String("name", item.name))
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit baseline transaction")
+	}
+
+	return nil
+}
+
+// gooseVersions
+// версии, примененные goose: для каждой версии берется последняя запись; пусто, если таблицы goose нет
+func gooseVersions(ctx context.Context, tx pgx.Tx) (map[int64]bool, error) {
+	var table *string
+	err := tx.QueryRow(ctx, `
+	SELECT COALESCE(to_regclass('`+migrationSchema+`.goose_db_version'), to_regclass('public.goose_db_version'))::TEXT;`).
+		Scan(&table)
+	if err != nil {
+		return nil, errors.WithMessage(err, "find goose table")
+	}
+
+	if table == nil {
+		return nil, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+	SELECT version_id FROM (
+		SELECT DISTINCT ON (version_id) version_id, is_applied
+		FROM `+*table+`
+		ORDER BY version_id, id DESC
+	) v
+	WHERE is_applied;`)
+	if err != nil {
+		return nil, errors.WithMessage(err, "select goose versions")
+	}
+	defer rows.Close()
+
+	result := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan goose version")
+		}
+		result[version] = true
+	}
+
+	return result, rows.Err()
+}
+
+// schemaColumns
+// колонки таблиц схемы приложения в виде "таблица.колонка"
+func schemaColumns(ctx context.Context, tx pgx.Tx) (map[string]bool, error) {
+	rows, err := tx.Query(ctx, `
+	SELECT table_name, column_name
+	FROM information_schema.columns
+	WHERE table_schema = $1;`, migrationSchema)
+	if err != nil {
+		return nil, errors.WithMessage(err, "select columns")
+	}
+	defer rows.Close()
+
+	result := make(map[string]bool)
+	for rows.Next() {
+		var table, column string
+		err = rows.Scan(&table, &column)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan column")
+		}
+		result[table] = true
+		result[table+"."+column] = true
+	}
+
+	return result, rows.Err()
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?i)CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:\w+\.)?(\w+)`)
+	addColumnPattern   = regexp.MustCompile(
+		`(?i)ALTER\s+TABLE\s+(?:\w+\.)?(\w+)\s+ADD\s+COLUMN\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+)
+
+// migrationObjects
+// таблицы и колонки, которые создает миграция: "таблица" и "таблица.колонка"
+func migrationObjects(up string) []string {
+	result := make([]string, 0)
+	for _, match := range createTablePattern.FindAllStringSubmatch(up, -1) {
+		result = append(result, strings.ToLower(match[1]))
+	}
+
+	for _, match := range addColumnPattern.FindAllStringSubmatch(up, -1) {
+		result = append(result, strings.ToLower(match[1]+"."+match[2]))
+	}
+
+	return result
+}
+
+// reflectedMigrations
+// количество первых миграций, уже отраженных в схеме: последняя миграция, все таблицы и колонки
+// которой есть в схеме, и все до нее; миграции без таблиц и колонок (изменение типов, ограничений)
+// своего решения не дают
+func reflectedMigrations(items []migration, columns map[string]bool) int {
+	count := 0
+	for idx, item := range items {
+		objects := migrationObjects(item.up)
+		if len(objects) == 0 {
+			continue
+		}
+
+		reflected := true
+		for _, object := range objects {
+			if !columns[object] {
+				reflected = false
+				break
+			}
+		}
+
+		if reflected {
+			count = idx + 1
+		}
+	}
+
+	return count
+}
+
+// applyMigration
+// таблицы миграции создаются в схеме приложения; false - миграция уже была применена
+func (c *Client) applyMigration(ctx context.Context, item migration) (bool, error) {
+	tx, err := c.cli.Begin(ctx)
+	if err != nil {
+		return false, errors.WithMessage(err, "begin migration transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, migrationLockId)
+	if err != nil {
+		return false, errors.WithMessage(err, "lock migrations")
+	}
+
+	var applied bool
+	err = tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM `+migrationSchema+`.schema_version WHERE version = $1);`,
+		item.version).Scan(&applied)
+	if err != nil {
+		return false, errors.WithMessage(err, "check version")
+	}
+
+	if applied {
+		return false, nil
+	}
+
+	_, err = tx.Exec(ctx, `SET LOCAL search_path TO `+migrationSchema+`;`)
+	if err != nil {
+		return false, errors.WithMessage(err, "set search path")
+	}
+
+	_, err = tx.Exec(ctx, item.up)
+	if err != nil {
+		return false, errors.WithMessage(err, "exec migration")
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO `+migrationSchema+`.schema_version (version, name) VALUES ($1, $2);`,
+		item.version, item.name)
+	if err != nil {
+		return false, errors.WithMessage(err, "insert version")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return false, errors.WithMessage(err, "commit migration transaction")
+	}
+
+	return true, nil
+}
+
+// loadMigrations
+// миграции по возрастанию версии
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, errors.WithMessage(err, "list migrations")
+	}
+
+	result := make([]migration, 0, len(names))
+	for _, fileName := range names {
+		content, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "read migration %s", fileName)
+		}
+
+		item, err := parseMigration(fileName, string(content))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "parse migration %s", fileName)
+		}
+
+		result = append(result, item)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].version < result[j].version
+	})
+
+	for i := 1; i < len(result); i++ {
+		if result[i].version == result[i-1].version {
+			return nil, errors.Errorf("duplicate migration version %d", result[i].version)
+		}
+	}
+
+	return result, nil
+}
+
+func parseMigration(fileName, content string) (migration, error) {
+	base := strings.TrimSuffix(path.Base(fileName), ".sql")
+	versionText, name, ok := strings.Cut(base, "_")
+	if !ok {
+		return migration{}, errors.New("file name must be <version>_<name>.sql")
+	}
+
+	version, err := strconv.ParseInt(versionText, 10, 64)
+	if err != nil {
+		return migration{}, errors.WithMessage(err, "parse version")
+	}
+
+	_, up, ok := strings.Cut(content, gooseUp)
+	if !ok {
+		return migration{}, errors.Errorf("%s section not found", gooseUp)
+	}
+	up, _, _ = strings.Cut(up, gooseDown)
+
+	if len(strings.TrimSpace(up)) == 0 {
+		return migration{}, errors.Errorf("%s section is empty", gooseUp)
+	}
+
+	return migration{
+		version: version,
+		name:    name,
+		up:      up,
+	}, nil
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"table-app/migrations"
+)
+
+const testMigration = `-- +goose Up
+CREATE TABLE item (id INT);
+
+-- +goose Down
+DROP TABLE item;
+`
+
+func TestParseMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		version  int64
+		title    string
+		wantErr  bool
+	}{
+		{name: "valid", fileName: "20240731085038_init.sql", content: testMigration, version: 20240731085038,
+			title: "init"},
+		{name: "name with underscores", fileName: "dir/20241015120000_add_item.sql", content: testMigration,
+			version: 20241015120000, title: "add_item"},
+		{name: "no name", fileName: "20240731085038.sql", content: testMigration, wantErr: true},
+		{name: "version not a number", fileName: "v1_init.sql", content: testMigration, wantErr: true},
+		{name: "no up section", fileName: "1_init.sql", content: "CREATE TABLE item (id INT);", wantErr: true},
+		{name: "empty up section", fileName: "1_init.sql", content: "-- +goose Up\n\n-- +goose Down\nDROP TABLE item;",
+			wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := parseMigration(tt.fileName, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseMigration(%s) = %+v, want error", tt.fileName, item)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseMigration(%s) error: %v", tt.fileName, err)
+			}
+			if item.version != tt.version || item.name != tt.title {
+				t.Errorf("parseMigration(%s) = %d %s, want %d %s", tt.fileName, item.version, item.name,
+					tt.version, tt.title)
+			}
+		})
+	}
+}
+
+func TestParseMigrationSkipsDown(t *testing.T) {
+	item, err := parseMigration("1_init.sql", testMigration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	objects := migrationObjects(item.up)
+	if len(objects) != 1 || objects[0] != "item" {
+		t.Errorf("up section objects = %v, want [item]", objects)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("ordered by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"3_third.sql":  {Data: []byte(testMigration)},
+			"10_tenth.sql": {Data: []byte(testMigration)},
+			"1_first.sql":  {Data: []byte(testMigration)},
+			"readme.txt":   {Data: []byte("not a migration")},
+		}
+
+		items, err := loadMigrations(fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []int64{1, 3, 10}
+		if len(items) != len(want) {
+			t.Fatalf("loaded %d migrations, want %d", len(items), len(want))
+		}
+		for i, version := range want {
+			if items[i].version != version {
+				t.Errorf("migration %d version = %d, want %d", i, items[i].version, version)
+			}
+		}
+	})
+
+	t.Run("duplicate version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"1_first.sql":  {Data: []byte(testMigration)},
+			"1_second.sql": {Data: []byte(testMigration)},
+		}
+
+		_, err := loadMigrations(fsys)
+		if err == nil {
+			t.Fatal("want duplicate version error")
+		}
+	})
+
+	t.Run("malformed name", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"1_first.sql": {Data: []byte(testMigration)},
+			"second.sql":  {Data: []byte(testMigration)},
+		}
+
+		_, err := loadMigrations(fsys)
+		if err == nil {
+			t.Fatal("want malformed name error")
+		}
+	})
+
+	t.Run("embedded migrations", func(t *testing.T) {
+		items, err := loadMigrations(migrations.FS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) == 0 {
+			t.Fatal("no embedded migrations")
+		}
+	})
+}
+
+func TestMigrationObjects(t *testing.T) {
+	up := `
+	CREATE TABLE exchange_rate (currency TEXT);
+	create table if not exists table_app.plan (id UUID);
+	ALTER TABLE finances ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+	ALTER TABLE finances ALTER COLUMN value TYPE BIGINT;
+	CREATE INDEX plan_idx ON plan (id);`
+
+	got := migrationObjects(up)
+	want := []string{"exchange_rate", "plan", "finances.currency"}
+	if len(got) != len(want) {
+		t.Fatalf("migrationObjects = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migrationObjects[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestReflectedMigrations(t *testing.T) {
+	items := []migration{
+		{version: 1, up: "CREATE TABLE category (id INT); CREATE TABLE finances (id INT);"},
+		{version: 2, up: "ALTER TABLE finances ALTER COLUMN value TYPE BIGINT;"},
+		{version: 3, up: "ALTER TABLE finances ADD COLUMN currency TEXT;"},
+		{version: 4, up: "CREATE TABLE plan (id INT);"},
+	}
+
+	tests := []struct {
+		name    string
+		columns map[string]bool
+		want    int
+	}{
+		{name: "empty schema", columns: map[string]bool{}, want: 0},
+		{name: "only init", columns: map[string]bool{"category": true, "finances": true}, want: 1},
+		{name: "type change between reflected", columns: map[string]bool{"category": true, "finances": true,
+			"finances.currency": true}, want: 3},
+		{name: "all", columns: map[string]bool{"category": true, "finances": true, "finances.currency": true,
+			"plan": true}, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reflectedMigrations(items, tt.columns)
+			if got != tt.want {
+				t.Errorf("reflectedMigrations = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"table-app/internal/log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// busyTimeoutMs - сколько ждать снятия блокировки файла бд другим процессом
+const busyTimeoutMs = 5000
+
+//go:embed schema.sql
+var schema string
+
+var (
+	// schemaPrefix - схема postgres в запросах репозиториев; в sqlite таблицы лежат в одной бд,
+	// а имя таблицы берется в кавычки, так как transaction - ключевое слово
+	schemaPrefix = regexp.MustCompile(`table_app\.(\w+)`)
+	// placeholder - параметры postgres $1, $2; в sqlite нумерованный параметр записывается как ?1,
+	// это сохраняет привязку по номеру, когда параметр повторяется в запросе
+	placeholder = regexp.MustCompile(`\$(\d+)`)
+)
+
+// Client
+// бд в одном локальном файле; реализует тот же интерфейс db.DB, что и клиент postgres,
+// поэтому репозитории работают с ним без изменений
+type Client struct {
+	logger log.Logger
+	cli    *sql.DB
+}
+
+func NewClient(logger log.Logger) *Client {
+	return &Client{
+		logger: logger,
+	}
+}
+
+// Upgrade
+// открывает файл бд, создавая его и недостающие таблицы
+func (c *Client) Upgrade(ctx context.Context, cfg Config) error {
+	if len(cfg.Path) == 0 {
+		return errors.New("invalid sqlite configuration: path is required")
+	}
+
+	err := os.MkdirAll(filepath.Dir(cfg.Path), 0775)
+	if err != nil {
+		return errors.WithMessage(err, "create sqlite dir")
+	}
+
+	dsn := cfg.Path + "?_foreign_keys=1&_busy_timeout=" + strconv.Itoa(busyTimeoutMs)
+	cli, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return errors.WithMessage(err, "open sqlite")
+	}
+
+	// запись в файл все равно последовательная, а одно соединение исключает
+	// ошибки блокировки между транзакциями приложения
+	cli.SetMaxOpenConns(1)
+
+	_, err = cli.ExecContext(ctx, schema)
+	if err != nil {
+		_ = cli.Close()
+		c.logger.Error(ctx, "create sqlite schema")
+		return errors.WithMessage(err, "create sqlite schema")
+	}
+
+	c.cli = cli
+	return nil
+}
+
+func (c *Client) Close() error {
+	if c.cli == nil {
+		return nil
+	}
+
+	return c.cli.Close()
+}
+
+func (c *Client) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
+	return exec(ctx, c.cli, query, args...)
+}
+
+func (c *Client) Select(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	return selectRows(ctx, c.cli, query, args...)
+}
+
+func (c *Client) SelectRow(ctx context.Context, query string, args ...any) pgx.Row {
+	return selectRow(ctx, c.cli, query, args...)
+}
+
+func (c *Client) Begin(ctx context.Context) (pgx.Tx, error) {
+	return c.BeginTx(ctx, pgx.TxOptions{})
+}
+
+// BeginTx
+// из настроек транзакции учитывается только режим только для чтения:
+// транзакции sqlite всегда сериализуемы
+func (c *Client) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	tx, err := c.cli.BeginTx(ctx, &sql.TxOptions{ReadOnly: txOptions.AccessMode == pgx.ReadOnly})
+	if err != nil {
+		return nil, errors.WithMessage(err, "begin sqlite transaction")
+	}
+
+	return &Tx{tx: tx}, nil
+}
+
+// queryer
+// общие методы sql.DB и sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func exec(ctx context.Context, q queryer, query string, args ...any) (pgconn.CommandTag, error) {
+	result, err := q.ExecContext(ctx, translate(query), args...)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	return commandTag(query, affected), nil
+}
+
+func selectRows(ctx context.Context, q queryer, query string, args ...any) (pgx.Rows, error) {
+	rows, err := q.QueryContext(ctx, translate(query), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rows{rows: rows}, nil
+}
+
+func selectRow(ctx context.Context, q queryer, query string, args ...any) pgx.Row {
+	return Row{row: q.QueryRowContext(ctx, translate(query), args...)}
+}
+
+// translate
+// переводит запрос репозитория с диалекта postgres; строки и имена в кавычках не меняются
+func translate(query string) string {
+	var b strings.Builder
+	for len(query) != 0 {
+		idx := strings.IndexAny(query, `'"`)
+		if idx == -1 {
+			b.WriteString(translateCode(query))
+			break
+		}
+
+		b.WriteString(translateCode(query[:idx]))
+		end := idx + quotedLen(query[idx:])
+		b.WriteString(query[idx:end])
+		query = query[end:]
+	}
+
+	return b.String()
+}
+
+func translateCode(code string) string {
+	code = schemaPrefix.ReplaceAllString(code, `"$1"`)
+	return placeholder.ReplaceAllString(code, `?$1`)
+}
+
+// quotedLen
+// длина строки или имени в кавычках в начале str вместе с кавычками; удвоенная кавычка
+// внутри строки ее не закрывает. Незакрытая строка длится до конца запроса
+func quotedLen(str string) int {
+	quote := str[0]
+	for i := 1; i < len(str); i++ {
+		if str[i] != quote {
+			continue
+		}
+
+		if i+1 < len(str) && str[i+1] == quote {
+			i++
+			continue
+		}
+
+		return i + 1
+	}
+
+	return len(str)
+}
+
+// commandTag
+// тег в формате postgres: команда и число затронутых строк, чтобы работал RowsAffected
+func commandTag(query string, affected int64) pgconn.CommandTag {
+	command := "EXEC"
+	fields := strings.Fields(query)
+	if len(fields) != 0 {
+		command = strings.ToUpper(fields[0])
+	}
+
+	if command == "INSERT" {
+		command += " 0"
+	}
+
+	return pgconn.NewCommandTag(command + " " + strconv.FormatInt(affected, 10))
+}
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"table-app/internal/log"
+)
+
+// testLogger
+// логгер без вывода
+type testLogger struct{}
+
+func (testLogger) Error(_ context.Context, _ any, _ ...log.Field) {}
+
+func (testLogger) Warn(_ context.Context, _ any, _ ...log.Field) {}
+
+func (testLogger) Info(_ context.Context, _ any, _ ...log.Field) {}
+
+func (testLogger) Debug(_ context.Context, _ any, _ ...log.Field) {}
+
+func TestClientUpgradeExistingFile(t *testing.T) {
+	ctx := context.Background()
+	cfg := Config{Path: filepath.Join(t.TempDir(), "data", "table.db")}
+
+	client := NewClient(testLogger{})
+	err := client.Upgrade(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.Exec(ctx, `
+	INSERT INTO table_app.category (id, name, main_category, priority)
+	VALUES ($1, $2, $3, $4);`, "1", "Еда", "Расходы", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// схема создается при каждом открытии и не трогает существующие данные
+	client = NewClient(testLogger{})
+	err = client.Upgrade(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var name string
+	err = client.SelectRow(ctx, `SELECT name FROM table_app.category WHERE id = $1;`, "1").Scan(&name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "Еда" {
+		t.Errorf("category name = %s, want Еда", name)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "schema prefix", query: "SELECT id FROM table_app.finances",
+			want: `SELECT id FROM "finances"`},
+		{name: "keyword table", query: "DELETE FROM table_app.transaction WHERE id = $1",
+			want: `DELETE FROM "transaction" WHERE id = ?1`},
+		{name: "two digit placeholder", query: "VALUES ($1, $2, $10, $11)",
+			want: "VALUES (?1, ?2, ?10, ?11)"},
+		{name: "repeated placeholder", query: "WHERE a = $1 OR b = $1",
+			want: "WHERE a = ?1 OR b = ?1"},
+		{name: "prefix in string literal", query: "SELECT 'table_app.finances' FROM table_app.finances",
+			want: `SELECT 'table_app.finances' FROM "finances"`},
+		{name: "placeholder in string literal", query: "SELECT '$1' WHERE id = $2",
+			want: "SELECT '$1' WHERE id = ?2"},
+		{name: "escaped quote in literal", query: "SELECT 'it''s table_app.x $1', $1",
+			want: "SELECT 'it''s table_app.x $1', ?1"},
+		{name: "quoted identifier", query: `SELECT "table_app.x" FROM table_app.x`,
+			want: `SELECT "table_app.x" FROM "x"`},
+		{name: "unterminated literal", query: "SELECT $1, 'table_app.x",
+			want: "SELECT ?1, 'table_app.x"},
+		{name: "no rewrite", query: "SELECT 1", want: "SELECT 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translate(tt.query)
+			if got != tt.want {
+				t.Errorf("translate(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+// errUnsupported - возможности pgx, которыми репозитории не пользуются
+var errUnsupported = errors.New("not supported by sqlite client")
+
+var (
+	_ pgx.Rows = (*Rows)(nil)
+	_ pgx.Row  = Row{}
+	_ pgx.Tx   = (*Tx)(nil)
+)
+
+// Rows
+// строки database/sql под интерфейсом pgx.Rows
+type Rows struct {
+	rows *sql.Rows
+	err  error
+}
+
+func (r *Rows) Close() {
+	_ = r.rows.Close()
+}
+
+func (r *Rows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.rows.Err()
+}
+
+func (r *Rows) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag("SELECT")
+}
+
+func (r *Rows) FieldDescriptions() []pgconn.FieldDescription {
+	return nil
+}
+
+func (r *Rows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *Rows) Scan(dest ...any) error {
+	err := r.rows.Scan(dest...)
+	if err != nil {
+		r.err = err
+	}
+
+	return err
+}
+
+func (r *Rows) Values() ([]any, error) {
+	columns, err := r.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	err = r.rows.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (r *Rows) RawValues() [][]byte {
+	return nil
+}
+
+func (r *Rows) Conn() *pgx.Conn {
+	return nil
+}
+
+// Row
+// одна строка результата; отсутствие строки возвращается как pgx.ErrNoRows
+type Row struct {
+	row *sql.Row
+}
+
+func (r Row) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return pgx.ErrNoRows
+	}
+
+	return err
+}
+
+// Tx
+// транзакция database/sql под интерфейсом pgx.Tx;
+// вложенные транзакции, copy, batch и большие объекты не поддерживаются
+type Tx struct {
+	tx *sql.Tx
+}
+
+func (t *Tx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, errUnsupported
+}
+
+func (t *Tx) Commit(ctx context.Context) error {
+	return t.tx.Commit()
+}
+
+// Rollback
+// как в pgx, откат завершенной транзакции не считается ошибкой
+func (t *Tx) Rollback(ctx context.Context) error {
+	err := t.tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+
+	return err
+}
+
+func (t *Tx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string,
+	rowSrc pgx.CopyFromSource) (int64, error) {
+	return 0, errUnsupported
+}
+
+func (t *Tx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return nil
+}
+
+func (t *Tx) LargeObjects() pgx.LargeObjects {
+	return pgx.LargeObjects{}
+}
+
+func (t *Tx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	return nil, errUnsupported
+}
+
+func (t *Tx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return exec(ctx, t.tx, sql, arguments...)
+}
+
+func (t *Tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return selectRows(ctx, t.tx, sql, args...)
+}
+
+func (t *Tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return selectRow(ctx, t.tx, sql, args...)
+}
+
+func (t *Tx) Conn() *pgx.Conn {
+	return nil
+}
@@ -0,0 +1,205 @@
+-- схема повторяет итоговую схему postgres из migrations;
+-- таблицы создаются при каждом открытии файла, если их еще нет
+
+CREATE TABLE IF NOT EXISTS category
+(
+    id              TEXT NOT NULL PRIMARY KEY,
+    name            TEXT NOT NULL UNIQUE,
+    main_category   TEXT NOT NULL,
+    priority        INTEGER NOT NULL,
+    currency        TEXT NOT NULL DEFAULT '',
+    parent_id       TEXT NOT NULL DEFAULT '',
+    archived_month  INTEGER NOT NULL DEFAULT 0,
+    archived_year   INTEGER NOT NULL DEFAULT 0
+);
+
+-- в sqlite нет отложенной проверки уникальности, а при обмене приоритетами внутри транзакции
+-- пара (main_category, priority) временно повторяется, поэтому индекс не уникальный
+CREATE INDEX IF NOT EXISTS category_priority_idx ON category (main_category, priority);
+
+CREATE TABLE IF NOT EXISTS finances
+(
+    id              TEXT NOT NULL PRIMARY KEY,
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL REFERENCES category (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    value           INTEGER NOT NULL,
+    month           INTEGER NOT NULL,
+    year            INTEGER NOT NULL,
+    currency        TEXT NOT NULL DEFAULT '',
+    account_id      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS finances_category_idx ON finances (category);
+
+CREATE TABLE IF NOT EXISTS "transaction"
+(
+    id              TEXT NOT NULL PRIMARY KEY,
+    cell_id         TEXT NOT NULL REFERENCES finances (id) ON DELETE CASCADE,
+    date            DATE NOT NULL,
+    amount          INTEGER NOT NULL,
+    note            TEXT NOT NULL DEFAULT '',
+    payee           TEXT NOT NULL DEFAULT '',
+    account_id      TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS transaction_cell_id_idx ON "transaction" (cell_id);
+
+CREATE TABLE IF NOT EXISTS exchange_rate
+(
+    currency        TEXT NOT NULL,
+    month           INTEGER NOT NULL,
+    year            INTEGER NOT NULL,
+    rate            REAL NOT NULL,
+
+    PRIMARY KEY (currency, year, month)
+);
+
+CREATE TABLE IF NOT EXISTS account
+(
+    id              TEXT NOT NULL PRIMARY KEY,
+    name            TEXT NOT NULL,
+    kind            TEXT NOT NULL,
+    opening_balance INTEGER NOT NULL DEFAULT 0,
+    priority        INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transfer
+(
+    id              TEXT NOT NULL PRIMARY KEY,
+    from_account_id TEXT NOT NULL,
+    to_account_id   TEXT NOT NULL,
+    amount          INTEGER NOT NULL,
+    date            DATE NOT NULL,
+    note            TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS plan
+(
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL,
+    month           INTEGER NOT NULL,
+    year            INTEGER NOT NULL,
+    value           INTEGER NOT NULL,
+    currency        TEXT NOT NULL DEFAULT '',
+
+    PRIMARY KEY (main_category, category, year, month)
+);
+
+CREATE TABLE IF NOT EXISTS recurring
+(
+    id              TEXT NOT NULL PRIMARY KEY,
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL,
+    amount          INTEGER NOT NULL,
+    note            TEXT NOT NULL DEFAULT '',
+    payee           TEXT NOT NULL DEFAULT '',
+    account_id      TEXT NOT NULL DEFAULT '',
+    day             INTEGER NOT NULL,
+    every_months    INTEGER NOT NULL DEFAULT 1,
+    start_date      DATE NOT NULL,
+    end_date        DATE,
+    last_date       DATE
+);
+
+CREATE TABLE IF NOT EXISTS checkpoint
+(
+    id          TEXT NOT NULL PRIMARY KEY,
+    date        DATE NOT NULL,
+    balance     INTEGER NOT NULL,
+    note        TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS goal
+(
+    id          TEXT NOT NULL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    target      INTEGER NOT NULL,
+    deadline    DATE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS goal_link
+(
+    goal_id         TEXT NOT NULL REFERENCES goal (id) ON DELETE CASCADE,
+    position        INTEGER NOT NULL,
+    kind            TEXT NOT NULL,
+    main_category   TEXT NOT NULL DEFAULT '',
+    category        TEXT NOT NULL DEFAULT '',
+    account_id      TEXT NOT NULL DEFAULT '',
+
+    PRIMARY KEY (goal_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS loan
+(
+    id                      TEXT NOT NULL PRIMARY KEY,
+    name                    TEXT NOT NULL,
+    principal               INTEGER NOT NULL,
+    rate                    INTEGER NOT NULL DEFAULT 0,
+    term_months             INTEGER NOT NULL,
+    type                    TEXT NOT NULL,
+    start_date              DATE NOT NULL,
+    account_id              TEXT NOT NULL DEFAULT '',
+    interest_main_category  TEXT NOT NULL,
+    interest_category       TEXT NOT NULL,
+    principal_main_category TEXT NOT NULL,
+    principal_category      TEXT NOT NULL,
+    last_date               DATE
+);
+
+CREATE TABLE IF NOT EXISTS valuation
+(
+    id          TEXT NOT NULL PRIMARY KEY,
+    asset       TEXT NOT NULL,
+    kind        TEXT NOT NULL,
+    date        DATE NOT NULL,
+    value       INTEGER NOT NULL,
+    note        TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS split
+(
+    id          TEXT NOT NULL PRIMARY KEY,
+    date        DATE NOT NULL,
+    amount      INTEGER NOT NULL,
+    by_percent  BOOLEAN NOT NULL DEFAULT FALSE,
+    account_id  TEXT NOT NULL DEFAULT '',
+    note        TEXT NOT NULL DEFAULT '',
+    payee       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS split_part
+(
+    split_id        TEXT NOT NULL REFERENCES split (id) ON DELETE CASCADE,
+    position        INTEGER NOT NULL,
+    transaction_id  TEXT NOT NULL,
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL,
+    amount          INTEGER NOT NULL,
+    percent         INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (split_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS attachment
+(
+    id             TEXT NOT NULL PRIMARY KEY,
+    main_category  TEXT NOT NULL,
+    category       TEXT NOT NULL,
+    month          INTEGER NOT NULL,
+    year           INTEGER NOT NULL,
+    transaction_id TEXT NOT NULL DEFAULT '',
+    name           TEXT NOT NULL,
+    size           INTEGER NOT NULL,
+    added          TIMESTAMP NOT NULL,
+    content        BLOB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS attachment_cell_idx ON attachment (main_category, category, year, month);
+
+CREATE TABLE IF NOT EXISTS payee
+(
+    id            TEXT NOT NULL PRIMARY KEY,
+    name          TEXT NOT NULL UNIQUE,
+    main_category TEXT NOT NULL DEFAULT '',
+    category      TEXT NOT NULL DEFAULT ''
+);
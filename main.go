@@ -5,8 +5,10 @@ import (
 	"os"
 
 	"table-app/assembly"
+	"table-app/gui"
 	"table-app/internal/app"
 	"table-app/internal/shutdown"
+	"table-app/repository"
 
 	"github.com/pkg/errors"
 )
@@ -31,6 +33,12 @@ func main() {
 	assembly := assembly.New(app)
 
 	app.Gui, err = assembly.ReceiveConfig(app.Context(), remoteCfg)
+	if errors.Is(err, repository.ErrStorageLocked) {
+		// второй экземпляр не открывает занятые данные, а сообщает об этом пользователю
+		gui.RunErrorWindow("Данные уже открыты",
+			"Файлы данных используются другим запущенным окном приложения. "+
+				"Закройте его и запустите приложение снова.")
+	}
 	if err != nil {
 		logger.Fatal(app.Context(), errors.WithMessage(err, "failed to receive config"))
 	}
@@ -1,14 +1,5 @@
 -- +goose Up
-CREATE TABLE finances
-(
-    id              UUID NOT NULL PRIMARY KEY,
-    main_category   TEXT NOT NULL,
-    category        TEXT NOT NULL REFERENCES table_app.category(name) ON UPDATE CASCADE,
-    value           INT NOT NULL,
-    month           INT NOT NULL,
-    year            INT NOT NULL
-);
-
+-- таблицы создаются в схеме table_app: клиент бд применяет миграции с ней в search_path
 CREATE TABLE category
 (
     id              UUID NOT NULL,
@@ -19,6 +10,16 @@ CREATE TABLE category
     CONSTRAINT category_pk PRIMARY KEY (main_category, priority)
 );
 
+CREATE TABLE finances
+(
+    id              UUID NOT NULL PRIMARY KEY,
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL REFERENCES table_app.category(name) ON UPDATE CASCADE,
+    value           INT NOT NULL,
+    month           INT NOT NULL,
+    year            INT NOT NULL
+);
+
 -- +goose Down
 DROP TABLE finances;
 DROP TABLE category;
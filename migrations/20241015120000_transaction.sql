@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE transaction
+(
+    id              UUID NOT NULL PRIMARY KEY,
+    cell_id         UUID NOT NULL REFERENCES table_app.finances(id) ON DELETE CASCADE,
+    date            DATE NOT NULL,
+    amount          INT NOT NULL,
+    note            TEXT NOT NULL DEFAULT '',
+    payee           TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX transaction_cell_id_idx ON transaction (cell_id);
+
+-- +goose Down
+DROP TABLE transaction;
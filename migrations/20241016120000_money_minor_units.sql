@@ -0,0 +1,8 @@
+-- +goose Up
+-- суммы хранятся в копейках
+ALTER TABLE finances ALTER COLUMN value TYPE BIGINT USING value::BIGINT * 100;
+ALTER TABLE transaction ALTER COLUMN amount TYPE BIGINT USING amount::BIGINT * 100;
+
+-- +goose Down
+ALTER TABLE finances ALTER COLUMN value TYPE INT USING value / 100;
+ALTER TABLE transaction ALTER COLUMN amount TYPE INT USING amount / 100;
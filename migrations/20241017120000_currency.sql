@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE finances ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE category ADD COLUMN currency TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE exchange_rate
+(
+    currency        TEXT NOT NULL,
+    month           INT NOT NULL,
+    year            INT NOT NULL,
+    rate            DOUBLE PRECISION NOT NULL,
+
+    CONSTRAINT exchange_rate_pk PRIMARY KEY (currency, year, month)
+);
+
+-- +goose Down
+DROP TABLE exchange_rate;
+ALTER TABLE category DROP COLUMN currency;
+ALTER TABLE finances DROP COLUMN currency;
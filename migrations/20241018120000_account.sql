@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE account
+(
+    id              UUID NOT NULL PRIMARY KEY,
+    name            TEXT NOT NULL,
+    kind            TEXT NOT NULL,
+    opening_balance BIGINT NOT NULL DEFAULT 0,
+    priority        INT NOT NULL
+);
+
+-- счета переводов не ссылаются на account внешним ключом:
+-- пустой счет означает "без счета", а список счетов сохраняется целиком
+CREATE TABLE transfer
+(
+    id              UUID NOT NULL PRIMARY KEY,
+    from_account_id TEXT NOT NULL,
+    to_account_id   TEXT NOT NULL,
+    amount          BIGINT NOT NULL,
+    date            DATE NOT NULL,
+    note            TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE finances ADD COLUMN account_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE transaction ADD COLUMN account_id TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE transaction DROP COLUMN account_id;
+ALTER TABLE finances DROP COLUMN account_id;
+DROP TABLE transfer;
+DROP TABLE account;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE plan
+(
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL,
+    month           INT NOT NULL,
+    year            INT NOT NULL,
+    value           BIGINT NOT NULL,
+    currency        TEXT NOT NULL DEFAULT '',
+
+    CONSTRAINT plan_pk PRIMARY KEY (main_category, category, year, month)
+);
+
+-- +goose Down
+DROP TABLE plan;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE recurring
+(
+    id              UUID NOT NULL PRIMARY KEY,
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL,
+    amount          BIGINT NOT NULL,
+    note            TEXT NOT NULL DEFAULT '',
+    payee           TEXT NOT NULL DEFAULT '',
+    account_id      TEXT NOT NULL DEFAULT '',
+    day             INT NOT NULL,
+    every_months    INT NOT NULL DEFAULT 1,
+    start_date      DATE NOT NULL,
+    end_date        DATE,
+    last_date       DATE
+);
+
+-- +goose Down
+DROP TABLE recurring;
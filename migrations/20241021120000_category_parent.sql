@@ -0,0 +1,6 @@
+-- +goose Up
+-- родитель хранится текстом: пустая строка означает категорию верхнего уровня
+ALTER TABLE category ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE category DROP COLUMN parent_id;
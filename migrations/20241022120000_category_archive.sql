@@ -0,0 +1,18 @@
+-- +goose Up
+-- нулевой год означает активную категорию
+ALTER TABLE category ADD COLUMN archived_month INT NOT NULL DEFAULT 0;
+ALTER TABLE category ADD COLUMN archived_year INT NOT NULL DEFAULT 0;
+
+-- категория удаляется только после удаления или переноса ее ячеек,
+-- поэтому ячейки не должны исчезать каскадно вместе с категорией
+ALTER TABLE finances DROP CONSTRAINT finances_category_fkey;
+ALTER TABLE finances ADD CONSTRAINT finances_category_fkey
+    FOREIGN KEY (category) REFERENCES category(name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+-- +goose Down
+ALTER TABLE finances DROP CONSTRAINT finances_category_fkey;
+ALTER TABLE finances ADD CONSTRAINT finances_category_fkey
+    FOREIGN KEY (category) REFERENCES category(name) ON UPDATE CASCADE;
+
+ALTER TABLE category DROP COLUMN archived_year;
+ALTER TABLE category DROP COLUMN archived_month;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- категория обновляется по id: при перестановке и переносе между основными категориями
+-- меняются main_category и priority
+ALTER TABLE category ADD CONSTRAINT category_id_key UNIQUE (id);
+
+-- при обмене приоритетами внутри транзакции пара (main_category, priority) временно повторяется,
+-- поэтому уникальность проверяется в конце транзакции
+ALTER TABLE category DROP CONSTRAINT category_pk;
+ALTER TABLE category ADD CONSTRAINT category_pk PRIMARY KEY (main_category, priority) DEFERRABLE INITIALLY DEFERRED;
+
+-- +goose Down
+ALTER TABLE category DROP CONSTRAINT category_pk;
+ALTER TABLE category ADD CONSTRAINT category_pk PRIMARY KEY (main_category, priority);
+
+ALTER TABLE category DROP CONSTRAINT category_id_key;
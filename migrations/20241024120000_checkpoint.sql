@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE checkpoint
+(
+    id          UUID NOT NULL PRIMARY KEY,
+    date        DATE NOT NULL,
+    balance     BIGINT NOT NULL,
+    note        TEXT NOT NULL DEFAULT ''
+);
+
+-- +goose Down
+DROP TABLE checkpoint;
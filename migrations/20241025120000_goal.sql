@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE goal
+(
+    id          UUID NOT NULL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    target      BIGINT NOT NULL,
+    deadline    DATE NOT NULL
+);
+
+CREATE TABLE goal_link
+(
+    goal_id         UUID NOT NULL REFERENCES goal (id) ON DELETE CASCADE,
+    position        INT NOT NULL,
+    kind            TEXT NOT NULL,
+    main_category   TEXT NOT NULL DEFAULT '',
+    category        TEXT NOT NULL DEFAULT '',
+    account_id      TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (goal_id, position)
+);
+
+-- +goose Down
+DROP TABLE goal_link;
+DROP TABLE goal;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE loan
+(
+    id                      UUID NOT NULL PRIMARY KEY,
+    name                    TEXT NOT NULL,
+    principal               BIGINT NOT NULL,
+    rate                    INT NOT NULL DEFAULT 0,
+    term_months             INT NOT NULL,
+    type                    TEXT NOT NULL,
+    start_date              DATE NOT NULL,
+    account_id              TEXT NOT NULL DEFAULT '',
+    interest_main_category  TEXT NOT NULL,
+    interest_category       TEXT NOT NULL,
+    principal_main_category TEXT NOT NULL,
+    principal_category      TEXT NOT NULL,
+    last_date               DATE
+);
+
+-- +goose Down
+DROP TABLE loan;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE valuation
+(
+    id          UUID NOT NULL PRIMARY KEY,
+    asset       TEXT NOT NULL,
+    kind        TEXT NOT NULL,
+    date        DATE NOT NULL,
+    value       BIGINT NOT NULL,
+    note        TEXT NOT NULL DEFAULT ''
+);
+
+-- +goose Down
+DROP TABLE valuation;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE split
+(
+    id          UUID NOT NULL PRIMARY KEY,
+    date        DATE NOT NULL,
+    amount      BIGINT NOT NULL,
+    by_percent  BOOLEAN NOT NULL DEFAULT FALSE,
+    account_id  TEXT NOT NULL DEFAULT '',
+    note        TEXT NOT NULL DEFAULT '',
+    payee       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE split_part
+(
+    split_id        UUID NOT NULL REFERENCES split (id) ON DELETE CASCADE,
+    position        INT NOT NULL,
+    transaction_id  UUID NOT NULL,
+    main_category   TEXT NOT NULL,
+    category        TEXT NOT NULL,
+    amount          BIGINT NOT NULL,
+    percent         INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (split_id, position)
+);
+
+-- +goose Down
+DROP TABLE split_part;
+DROP TABLE split;
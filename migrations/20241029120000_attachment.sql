@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE attachment
+(
+    id             UUID NOT NULL PRIMARY KEY,
+    main_category  TEXT NOT NULL,
+    category       TEXT NOT NULL,
+    month          INT NOT NULL,
+    year           INT NOT NULL,
+    transaction_id TEXT NOT NULL DEFAULT '',
+    name           TEXT NOT NULL,
+    size           BIGINT NOT NULL,
+    added          TIMESTAMPTZ NOT NULL,
+    content        BYTEA NOT NULL
+);
+
+CREATE INDEX attachment_cell_idx ON attachment (main_category, category, year, month);
+
+-- +goose Down
+DROP TABLE attachment;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE payee
+(
+    id            UUID NOT NULL PRIMARY KEY,
+    name          TEXT NOT NULL UNIQUE,
+    main_category TEXT NOT NULL DEFAULT '',
+    category      TEXT NOT NULL DEFAULT ''
+);
+
+-- +goose Down
+DROP TABLE payee;
@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS
+// миграции goose, встроенные в приложение; клиент postgres применяет их при подключении
+//
+//go:embed *.sql
+var FS embed.FS
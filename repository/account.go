@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"strconv"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Account struct {
+	db db.DB
+}
+
+func NewAccount(db db.DB) Account {
+	return Account{
+		db: db,
+	}
+}
+
+type AccountFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewAccountFile(filePath string, logger log.Logger) AccountFile {
+	return AccountFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// accountLayout - колонки файла счетов
+var accountLayout = csvLayout{
+	columns: []string{"id", "name", "kind", "openingBalance", "priority"},
+}
+
+// ReplaceAll
+// список счетов небольшой, поэтому сохраняется целиком
+func (r Account) ReplaceAll(ctx context.Context, accounts []domain.Account) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace accounts transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.account;`)
+	if err == nil {
+		for _, account := range accounts {
+			err = insertAccount(ctx, tx.Exec, account)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace accounts transaction")
+		}
+
+		return errors.WithMessage(err, "replace accounts transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace accounts transaction")
+	}
+
+	return nil
+}
+
+func insertAccount(ctx context.Context, txExec TxFuncExec, account domain.Account) error {
+	q := `
+	INSERT INTO table_app.account
+    	(id, name, kind, opening_balance, priority)
+	VALUES
+    	($1, $2, $3, $4, $5);`
+
+	_, err := txExec(ctx, q, account.Id, account.Name, account.Kind, int64(account.OpeningBalance), account.Priority)
+	if err != nil {
+		return errors.WithMessage(err, "insert account")
+	}
+
+	return nil
+}
+
+func (r Account) GetAll(ctx context.Context) ([]domain.Account, error) {
+	q := `
+	SELECT id, name, kind, opening_balance, priority
+	FROM table_app.account;`
+
+	var accounts []domain.Account
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get accounts")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var account domain.Account
+		var openingBalance int64
+		err = rows.Scan(&account.Id, &account.Name, &account.Kind, &openingBalance, &account.Priority)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		account.OpeningBalance = entity.Money(openingBalance)
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func (r AccountFile) ReplaceAll(ctx context.Context, accounts []domain.Account) error {
+	return r.writeToFile(accounts)
+}
+
+func (r AccountFile) GetAll(ctx context.Context) ([]domain.Account, error) {
+	return r.readFromFile()
+}
+
+func (r AccountFile) readFromFile() ([]domain.Account, error) {
+	table, err := readCSVFile(r.filePath, accountLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Account, 0, len(table.rows))
+	for _, row := range table.rows {
+		account, err := parseAccountRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, account)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseAccountRow(row csvRow) (domain.Account, error) {
+	account := domain.Account{}
+	account.Id = row.Value("id")
+	account.Name = row.Value("name")
+	account.Kind = row.Value("kind")
+
+	openingBalance, err := entity.ParseMoney(row.Value("openingBalance"))
+	if err != nil {
+		return account, errors.WithMessage(err, "convert opening balance")
+	}
+	account.OpeningBalance = openingBalance
+
+	account.Priority, err = strconv.Atoi(row.Value("priority"))
+	if err != nil {
+		return account, errors.WithMessage(err, "convert priority value")
+	}
+
+	return account, nil
+}
+
+func (r AccountFile) writeToFile(data []domain.Account) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(accountLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, account := range data {
+		err := writer.Write([]string{
+			account.Id,
+			account.Name,
+			account.Kind,
+			account.OpeningBalance.String(),
+			strconv.Itoa(account.Priority),
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	"table-app/domain"
+)
+
+// AccountCache
+// счета и переводы между ними
+type AccountCache struct {
+	accounts  []domain.Account
+	transfers []domain.Transfer
+	mutex     sync.Mutex
+}
+
+func NewAccountCache() *AccountCache {
+	return &AccountCache{
+		accounts:  make([]domain.Account, 0),
+		transfers: make([]domain.Transfer, 0),
+		mutex:     sync.Mutex{},
+	}
+}
+
+func (r *AccountCache) InitCache(accounts []domain.Account, transfers []domain.Transfer) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.accounts = append(make([]domain.Account, 0, len(accounts)), accounts...)
+	sort.Slice(r.accounts, func(i, j int) bool {
+		return r.accounts[i].Priority < r.accounts[j].Priority
+	})
+
+	r.transfers = append(make([]domain.Transfer, 0, len(transfers)), transfers...)
+	sort.Slice(r.transfers, func(i, j int) bool {
+		return r.transfers[i].Date.Before(r.transfers[j].Date)
+	})
+}
+
+func (r *AccountCache) ReadAccounts() []domain.Account {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Account, 0, len(r.accounts)), r.accounts...)
+}
+
+func (r *AccountCache) ReadTransfers() []domain.Transfer {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Transfer, 0, len(r.transfers)), r.transfers...)
+}
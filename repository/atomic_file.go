@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// atomicFile
+// файл данных переписывается через временный файл в том же каталоге: после записи данные
+// сбрасываются на диск и временный файл переименовывается поверх старого, поэтому при сбое
+// на диске остается либо старый, либо новый файл целиком
+type atomicFile struct {
+	*os.File
+	filePath  string
+	committed bool
+}
+
+func createAtomicFile(filePath string) (*atomicFile, error) {
+	file, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return nil, errors.WithMessage(err, "create temp file")
+	}
+
+	err = file.Chmod(0664)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, errors.WithMessage(err, "chmod temp file")
+	}
+
+	return &atomicFile{
+		File:     file,
+		filePath: filePath,
+	}, nil
+}
+
+// writeAtomicTemp
+// пишет данные во временный файл; если запись не удалась, временный файл удаляется
+// и возвращается ошибка, иначе файл данных заменяется при Commit
+func writeAtomicTemp(filePath string, write func(w io.Writer) error) (*atomicFile, error) {
+	file, err := createAtomicFile(filePath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "open file")
+	}
+
+	err = write(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Commit
+// сбрасывает временный файл на диск и заменяет им файл данных
+func (f *atomicFile) Commit() error {
+	err := f.File.Sync()
+	if err != nil {
+		return errors.WithMessage(err, "sync temp file")
+	}
+
+	err = f.File.Close()
+	if err != nil {
+		return errors.WithMessage(err, "close temp file")
+	}
+	f.committed = true
+
+	err = os.Rename(f.File.Name(), f.filePath)
+	if err != nil {
+		os.Remove(f.File.Name())
+		return errors.WithMessage(err, "rename temp file")
+	}
+
+	err = syncDir(filepath.Dir(f.filePath))
+	if err != nil {
+		return errors.WithMessage(err, "sync data dir")
+	}
+
+	return nil
+}
+
+// Close
+// удаляет временный файл, если запись не дошла до Commit
+func (f *atomicFile) Close() error {
+	if f.committed {
+		return nil
+	}
+	f.committed = true
+
+	err := f.File.Close()
+	os.Remove(f.File.Name())
+
+	return err
+}
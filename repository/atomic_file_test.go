@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAtomicFileCommit(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "table.csv")
+	err := os.WriteFile(filePath, []byte("old"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := createAtomicFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// до Commit файл данных не меняется
+	assertFileContent(t, filePath, "old")
+
+	err = file.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertFileContent(t, filePath, "new")
+	assertDirFiles(t, dir, []string{"table.csv"})
+
+	// Close после Commit ничего не удаляет
+	err = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFileContent(t, filePath, "new")
+}
+
+func TestAtomicFileCloseWithoutCommit(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "table.csv")
+	err := os.WriteFile(filePath, []byte("old"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := createAtomicFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = file.WriteString("partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertFileContent(t, filePath, "old")
+	assertDirFiles(t, dir, []string{"table.csv"})
+}
+
+// fullDiskWriter
+// принимает limit байт, а дальше возвращает ошибку, как при переполненном диске
+type fullDiskWriter struct {
+	w     io.Writer
+	limit int
+}
+
+func (f *fullDiskWriter) Write(p []byte) (int, error) {
+	if len(p) > f.limit {
+		n, _ := f.w.Write(p[:f.limit])
+		f.limit = 0
+		return n, errors.New("no space left on device")
+	}
+
+	f.limit -= len(p)
+	return f.w.Write(p)
+}
+
+func TestWriteCSVTempFlushError(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "table.csv")
+	err := os.WriteFile(filePath, []byte("old"), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := [][]string{{"c1", "Расходы", "Еда", "10.00", "3", "2024", "", ""}}
+	file, err := writeAtomicTemp(filePath, func(w io.Writer) error {
+		return writeCSV(&fullDiskWriter{w: w, limit: 10}, cellLayout, records)
+	})
+	if err == nil {
+		file.Close()
+		t.Fatal("want flush error")
+	}
+	if file != nil {
+		t.Error("temp file is returned with error")
+	}
+
+	assertFileContent(t, filePath, "old")
+	assertDirFiles(t, dir, []string{"table.csv"})
+}
+
+func assertFileContent(t *testing.T, filePath, want string) {
+	t.Helper()
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != want {
+		t.Errorf("%s content = %q, want %q", filepath.Base(filePath), content, want)
+	}
+}
+
+// assertDirFiles
+// в каталоге не осталось временных файлов
+func assertDirFiles(t *testing.T, dir string, want []string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	if len(names) != len(want) {
+		t.Fatalf("dir files = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("dir files = %v, want %v", names, want)
+		}
+	}
+}
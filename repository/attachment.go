@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/conf"
+	"table-app/domain"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+// defaultAttachmentDir - каталог вложений рядом с файлами данных, если в конфиге он не задан
+const defaultAttachmentDir = "attachments"
+
+// attachmentTempDir - каталог во временной папке, куда выгружаются вложения из бд для просмотра
+const attachmentTempDir = "table-app-attachments"
+
+// attachmentAddedLayout - формат даты и времени добавления вложения в файле
+const attachmentAddedLayout = time.RFC3339
+
+// Attachment
+// вложения в бд: описание и содержимое в одной строке
+type Attachment struct {
+	db db.DB
+}
+
+func NewAttachment(db db.DB) Attachment {
+	return Attachment{
+		db: db,
+	}
+}
+
+// AttachmentFile
+// описания вложений в файле csv, содержимое - отдельными файлами в каталоге вложений
+type AttachmentFile struct {
+	filePath string
+	dir      string
+	logger   log.Logger
+}
+
+// attachmentLayout - колонки файла описаний вложений
+var attachmentLayout = csvLayout{
+	columns: []string{"id", "mainCategory", "category", "month", "year", "transactionId", "name", "size", "added"},
+}
+
+func NewAttachmentFile(files conf.Files, logger log.Logger) AttachmentFile {
+	return AttachmentFile{
+		filePath: files.AttachmentFilePath,
+		dir:      attachmentDir(files),
+		logger:   logger,
+	}
+}
+
+// attachmentDir
+// каталог файлов вложений из конфига или каталог по умолчанию рядом с файлом таблицы
+func attachmentDir(files conf.Files) string {
+	if len(files.AttachmentDir) != 0 {
+		return files.AttachmentDir
+	}
+
+	return filepath.Join(filepath.Dir(files.TableFilePath), defaultAttachmentDir)
+}
+
+// ReplaceAll
+// сохраняет описания вложений; содержимое записывается сразу при добавлении в WriteContent,
+// а содержимое вложений, которых нет в списке, удаляется
+func (r Attachment) ReplaceAll(ctx context.Context, items []domain.Attachment) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace attachments transaction")
+	}
+
+	err = deleteOrphanAttachments(ctx, tx, items)
+	if err == nil {
+		for _, item := range items {
+			err = updateAttachment(ctx, tx.Exec, item)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace attachments transaction")
+		}
+
+		return errors.WithMessage(err, "replace attachments transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace attachments transaction")
+	}
+
+	return nil
+}
+
+// deleteOrphanAttachments
+// удаляет вложения, которых нет в списке; лишние id выбираются отдельно, чтобы запрос
+// не зависел от массивов postgres
+func deleteOrphanAttachments(ctx context.Context, tx pgx.Tx, items []domain.Attachment) error {
+	keep := make(map[string]bool, len(items))
+	for _, item := range items {
+		keep[item.Id] = true
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id FROM table_app.attachment;`)
+	if err != nil {
+		return errors.WithMessage(err, "select attachment ids")
+	}
+
+	orphans := make([]string, 0)
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			rows.Close()
+			return errors.WithMessage(err, "scan attachment id")
+		}
+
+		if !keep[id] {
+			orphans = append(orphans, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range orphans {
+		_, err = tx.Exec(ctx, `DELETE FROM table_app.attachment WHERE id = $1;`, id)
+		if err != nil {
+			return errors.WithMessage(err, "delete attachment")
+		}
+	}
+
+	return nil
+}
+
+func updateAttachment(ctx context.Context, txExec TxFuncExec, item domain.Attachment) error {
+	q := `
+	UPDATE table_app.attachment
+	SET main_category = $2, category = $3, month = $4, year = $5, transaction_id = $6
+	WHERE id = $1;`
+
+	_, err := txExec(ctx, q, item.Id, item.MainCategory, item.Category, int(item.Month), item.Year,
+		item.TransactionId)
+	if err != nil {
+		return errors.WithMessage(err, "update attachment")
+	}
+
+	return nil
+}
+
+// WriteContent
+// записывает содержимое вложения вместе с описанием
+func (r Attachment) WriteContent(ctx context.Context, item domain.Attachment, content []byte) error {
+	q := `
+	INSERT INTO table_app.attachment
+    	(id, main_category, category, month, year, transaction_id, name, size, added, content)
+	VALUES
+    	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	ON CONFLICT (id) DO UPDATE SET content = excluded.content, size = excluded.size;`
+
+	_, err := r.db.Exec(ctx, q, item.Id, item.MainCategory, item.Category, int(item.Month), item.Year,
+		item.TransactionId, item.Name, item.Size, item.Added, content)
+	if err != nil {
+		return errors.WithMessage(err, "insert attachment")
+	}
+
+	return nil
+}
+
+// ReadContent
+// содержимое вложения
+func (r Attachment) ReadContent(ctx context.Context, item domain.Attachment) ([]byte, error) {
+	var content []byte
+	err := r.db.SelectRow(ctx, `SELECT content FROM table_app.attachment WHERE id = $1;`, item.Id).
+		Scan(&content)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get attachment content")
+	}
+
+	return content, nil
+}
+
+// LocalPath
+// путь к файлу вложения для открытия в программе просмотра; вложение сначала
+// выгружается во временный каталог
+func (r Attachment) LocalPath(ctx context.Context, item domain.Attachment) (string, error) {
+	content, err := r.ReadContent(ctx, item)
+	if err != nil {
+		return "", err
+	}
+
+	return writeTempAttachment(item, content)
+}
+
+// writeTempAttachment
+// выгружает содержимое вложения во временный каталог для программы просмотра
+func writeTempAttachment(item domain.Attachment, content []byte) (string, error) {
+	dir := filepath.Join(os.TempDir(), attachmentTempDir)
+	err := os.MkdirAll(dir, 0775)
+	if err != nil {
+		return "", errors.WithMessage(err, "create temp attachment dir")
+	}
+
+	path := filepath.Join(dir, item.FileName())
+	err = os.WriteFile(path, content, 0664)
+	if err != nil {
+		return "", errors.WithMessage(err, "write temp attachment file")
+	}
+
+	return path, nil
+}
+
+func (r Attachment) GetAll(ctx context.Context) ([]domain.Attachment, error) {
+	q := `
+	SELECT id, main_category, category, month, year, transaction_id, name, size, added
+	FROM table_app.attachment
+	ORDER BY added;`
+
+	var items []domain.Attachment
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get attachments")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var item domain.Attachment
+		var month int
+		err = rows.Scan(&item.Id, &item.MainCategory, &item.Category, &month, &item.Year,
+			&item.TransactionId, &item.Name, &item.Size, &item.Added)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		item.Month = time.Month(month)
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r AttachmentFile) ReplaceAll(ctx context.Context, items []domain.Attachment) error {
+	err := r.writeToFile(items)
+	if err != nil {
+		return errors.WithMessage(err, "write attachments")
+	}
+
+	return r.removeOrphanFiles(items)
+}
+
+// WriteContent
+// содержимое записывается в каталог вложений
+func (r AttachmentFile) WriteContent(ctx context.Context, item domain.Attachment, content []byte) error {
+	err := os.MkdirAll(r.dir, 0775)
+	if err != nil {
+		return errors.WithMessage(err, "create attachment dir")
+	}
+
+	file, err := createAtomicFile(filepath.Join(r.dir, item.FileName()))
+	if err != nil {
+		return errors.WithMessage(err, "open attachment file")
+	}
+	defer file.Close()
+
+	_, err = file.Write(content)
+	if err != nil {
+		return errors.WithMessage(err, "write attachment file")
+	}
+
+	return file.Commit()
+}
+
+func (r AttachmentFile) ReadContent(ctx context.Context, item domain.Attachment) ([]byte, error) {
+	content, err := os.ReadFile(filepath.Join(r.dir, item.FileName()))
+	if err != nil {
+		return nil, errors.WithMessage(err, "read attachment file")
+	}
+
+	return content, nil
+}
+
+func (r AttachmentFile) LocalPath(ctx context.Context, item domain.Attachment) (string, error) {
+	path, err := filepath.Abs(filepath.Join(r.dir, item.FileName()))
+	if err != nil {
+		return "", errors.WithMessage(err, "attachment path")
+	}
+
+	return path, nil
+}
+
+func (r AttachmentFile) GetAll(ctx context.Context) ([]domain.Attachment, error) {
+	return r.readFromFile()
+}
+
+// removeOrphanFiles
+// удаляет из каталога вложений файлы удаленных вложений. Каталог может быть задан в конфиге и
+// содержать чужие файлы, поэтому удаляются только файлы с именем вида <uuid><расширение>.
+// Пока рядом с описаниями лежит копия с отброшенными строками, файлы не удаляются:
+// среди них может быть содержимое вложений из этих строк
+func (r AttachmentFile) removeOrphanFiles(items []domain.Attachment) error {
+	rejected, err := filepath.Glob(r.filePath + ".rejected-*")
+	if err != nil {
+		return errors.WithMessage(err, "find rejected attachment index")
+	}
+	if len(rejected) > 0 {
+		r.logger.Warn(context.Background(), "attachment files cleanup is skipped while rejected index copy exists: "+
+			rejected[0])
+		return nil
+	}
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return errors.WithMessage(err, "read attachment dir")
+	}
+
+	names := make(map[string]bool, len(items))
+	for _, item := range items {
+		names[item.FileName()] = true
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || names[entry.Name()] || !isAttachmentFileName(entry.Name()) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(r.dir, entry.Name()))
+		if err != nil {
+			return errors.WithMessage(err, "remove attachment file")
+		}
+	}
+
+	return nil
+}
+
+// isAttachmentFileName
+// имя файла содержимого вложения: id вложения и расширение исходного файла
+func isAttachmentFileName(name string) bool {
+	id := strings.TrimSuffix(name, filepath.Ext(name))
+	if len(id) != 36 {
+		return false
+	}
+
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+func (r AttachmentFile) readFromFile() ([]domain.Attachment, error) {
+	table, err := readCSVFile(r.filePath, attachmentLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Attachment, 0, len(table.rows))
+	for _, row := range table.rows {
+		item, err := parseAttachmentRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseAttachmentRow(row csvRow) (domain.Attachment, error) {
+	item := domain.Attachment{}
+	item.Id = row.Value("id")
+	item.MainCategory = row.Value("mainCategory")
+	item.Category = row.Value("category")
+
+	var err error
+	item.Month, err = parseMonth(row.Value("month"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert attachment month")
+	}
+
+	item.Year, err = strconv.Atoi(row.Value("year"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert attachment year")
+	}
+
+	item.TransactionId = row.Value("transactionId")
+	item.Name = row.Value("name")
+
+	item.Size, err = strconv.ParseInt(row.Value("size"), 10, 64)
+	if err != nil {
+		return item, errors.WithMessage(err, "convert attachment size")
+	}
+
+	item.Added, err = time.Parse(attachmentAddedLayout, row.Value("added"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert attachment date")
+	}
+
+	return item, nil
+}
+
+func (r AttachmentFile) writeToFile(data []domain.Attachment) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(attachmentLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, item := range data {
+		err := writer.Write([]string{
+			item.Id,
+			item.MainCategory,
+			item.Category,
+			strconv.Itoa(int(item.Month)),
+			strconv.Itoa(item.Year),
+			item.TransactionId,
+			item.Name,
+			strconv.FormatInt(item.Size, 10),
+			item.Added.Format(attachmentAddedLayout),
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"sync"
+
+	"table-app/domain"
+)
+
+// AttachmentCache
+// описания вложений ячеек и операций; содержимое в кеше не хранится
+type AttachmentCache struct {
+	items []domain.Attachment
+	mutex sync.Mutex
+}
+
+func NewAttachmentCache() *AttachmentCache {
+	return &AttachmentCache{
+		items: make([]domain.Attachment, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *AttachmentCache) InitCache(items []domain.Attachment) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(make([]domain.Attachment, 0, len(items)), items...)
+}
+
+func (r *AttachmentCache) ReadAll() []domain.Attachment {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Attachment, 0, len(r.items)), r.items...)
+}
+
+func (r *AttachmentCache) Get(id string) (domain.Attachment, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, item := range r.items {
+		if item.Id == id {
+			return item, true
+		}
+	}
+
+	return domain.Attachment{}, false
+}
+
+// GetByCell
+// вложения ячейки и ее операций
+func (r *AttachmentCache) GetByCell(key domain.CellKey) []domain.Attachment {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make([]domain.Attachment, 0)
+	for _, item := range r.items {
+		if item.CellKey() == key {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+func (r *AttachmentCache) Add(item domain.Attachment) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(r.items, item)
+}
+
+func (r *AttachmentCache) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	items := make([]domain.Attachment, 0, len(r.items))
+	for _, item := range r.items {
+		if item.Id != id {
+			items = append(items, item)
+		}
+	}
+
+	r.items = items
+}
+
+// UpdateCategoryName
+// переносит вложения на новое название категории; при слиянии - в ячейки того же месяца
+func (r *AttachmentCache) UpdateCategoryName(oldCategory, newCategory domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if r.items[i].IsLinkedTo(oldCategory) {
+			r.items[i].MainCategory = newCategory.MainCategory
+			r.items[i].Category = newCategory.Name
+		}
+	}
+}
+
+// DeleteCategory
+// удаляет вложения ячеек удаленной категории
+func (r *AttachmentCache) DeleteCategory(category domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	items := make([]domain.Attachment, 0, len(r.items))
+	for _, item := range r.items {
+		if !item.IsLinkedTo(category) {
+			items = append(items, item)
+		}
+	}
+
+	r.items = items
+}
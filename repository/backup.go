@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"table-app/domain"
+
+	"github.com/pkg/errors"
+)
+
+// backupAttachmentDir - каталог вложений внутри архива резервной копии
+const backupAttachmentDir = "attachments/"
+
+// Backup
+// резервная копия в zip архиве: файлы данных файлового хранилища, вложения и их список
+type Backup struct {
+	dataFiles []string
+}
+
+func NewBackup(dataFiles []string) Backup {
+	return Backup{
+		dataFiles: dataFiles,
+	}
+}
+
+// Write
+// архив пишется во временный файл и заменяет прежний только после успешной записи;
+// содержимое вложений читается через content по одному, список вложений пишется в attachments/index.csv
+func (r Backup) Write(filePath string, attachments []domain.Attachment,
+	content func(item domain.Attachment) ([]byte, error)) error {
+	file, err := createAtomicFile(filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+
+	for _, dataFile := range r.dataFiles {
+		err = addFileToArchive(archive, dataFile)
+		if err != nil {
+			return errors.WithMessagef(err, "add data file %s", dataFile)
+		}
+	}
+
+	for _, item := range attachments {
+		data, err := content(item)
+		if err != nil {
+			return errors.WithMessagef(err, "read attachment %s", item.Name)
+		}
+
+		writer, err := archive.Create(backupAttachmentDir + item.FileName())
+		if err != nil {
+			return errors.WithMessage(err, "create attachment entry")
+		}
+
+		_, err = writer.Write(data)
+		if err != nil {
+			return errors.WithMessage(err, "write attachment entry")
+		}
+	}
+
+	err = writeAttachmentIndex(archive, attachments)
+	if err != nil {
+		return errors.WithMessage(err, "write attachment index")
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return errors.WithMessage(err, "close archive")
+	}
+
+	return file.Commit()
+}
+
+func addFileToArchive(archive *zip.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer, err := archive.Create(filepath.Base(path))
+	if err != nil {
+		return errors.WithMessage(err, "create entry")
+	}
+
+	_, err = io.Copy(writer, file)
+	if err != nil {
+		return errors.WithMessage(err, "copy file")
+	}
+
+	return nil
+}
+
+func writeAttachmentIndex(archive *zip.Writer, attachments []domain.Attachment) error {
+	entry, err := archive.Create(backupAttachmentDir + "index.csv")
+	if err != nil {
+		return errors.WithMessage(err, "create entry")
+	}
+
+	writer := csv.NewWriter(entry)
+	err = writer.Write([]string{"file", "name", "main_category", "category", "month", "year", "transaction_id"})
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, item := range attachments {
+		err = writer.Write([]string{
+			item.FileName(),
+			item.Name,
+			item.MainCategory,
+			item.Category,
+			strconv.Itoa(int(item.Month)),
+			strconv.Itoa(item.Year),
+			item.TransactionId,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
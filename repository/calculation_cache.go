@@ -1,89 +1,69 @@
 package repository
 
 import (
-	"strconv"
 	"sync"
 	"time"
 
 	"table-app/conf"
 	"table-app/domain"
-	"table-app/utils"
+	"table-app/entity"
 
 	"github.com/pkg/errors"
 )
 
 type CalculationCache struct {
-	consumptionByDate map[string]int
-	balanceByDate     map[string]int
-	mutex             sync.Mutex
-	settings          conf.Setting
+	consumptionByDate map[entity.MonthYear]entity.Money
+	balanceByDate     map[entity.MonthYear]entity.Money
+
+	// accountBalanceByDate - остатки по счетам на конец месяца
+	accountBalanceByDate map[entity.MonthYear]map[string]entity.Money
+
+	// checkpointDiffByDate - расхождение последней сверки месяца с расчетным остатком
+	checkpointDiffByDate map[entity.MonthYear]entity.Money
+
+	mutex    sync.Mutex
+	settings conf.Setting
 }
 
 func NewCalculationCache(settings conf.Setting) *CalculationCache {
 	return &CalculationCache{
-		consumptionByDate: make(map[string]int),
-		balanceByDate:     make(map[string]int),
-		mutex:             sync.Mutex{},
-		settings:          settings,
+		consumptionByDate: make(map[entity.MonthYear]entity.Money),
+		balanceByDate:     make(map[entity.MonthYear]entity.Money),
+
+		accountBalanceByDate: make(map[entity.MonthYear]map[string]entity.Money),
+		checkpointDiffByDate: make(map[entity.MonthYear]entity.Money),
+		mutex:                sync.Mutex{},
+		settings:             settings,
 	}
 }
 
-func (r *CalculationCache) InitCache(valuesList map[string]domain.Cell, categories [][]domain.Category) error {
-	// заполнение consumptionByDate
-	for _, cell := range valuesList {
-		if cell.MainCategory != "Расходы" {
-			continue
-		}
+// InitCache
+// расчет расходов и остатков по всем месяцам; если для каких-то ячеек не нашлось курса валюты,
+// они не учитываются, расчет продолжается, а первая такая ошибка возвращается в конце
+func (r *CalculationCache) InitCache(valuesList map[domain.CellKey]domain.Cell, categories [][]domain.Category,
+	checkpoints []domain.Checkpoint, convert domain.Converter) error {
+	var convertErr error
 
-		compositeDate := utils.GetCompositeDate(int(cell.Month), cell.Year)
+	latestCheckpoints := domain.LatestCheckpoints(checkpoints)
 
-		consumption, isExist := r.consumptionByDate[compositeDate]
-		if !isExist {
-			r.consumptionByDate[compositeDate] = cell.Value
-			continue
-		}
-
-		consumption += cell.Value
-		r.consumptionByDate[compositeDate] = consumption
-	}
-
-	mainCategIdx := r.settings.MainCategoryOrder["Доходы"]
-
-	var lastMonth time.Month
+	// расчет идет до конца текущего года, так как будущие месяцы можно планировать
 	for year := r.settings.StartYear; year <= time.Now().Year(); year++ {
-		if year != time.Now().Year() {
-			// если год не текущий
-			lastMonth = time.December
-		} else {
-			lastMonth = time.Now().Month()
-		}
-
-		for month := 1; month <= int(lastMonth); month++ {
-			balanceSum := 0
-
+		for month := 1; month <= int(time.December); month++ {
 			// считаем сумму доходов
-			for i, mainCategory := range categories {
-				if i != mainCategIdx {
-					continue
-				}
-
-				for _, categ := range mainCategory {
-					compositeId := categ.MainCategory + categ.Name + strconv.Itoa(month) + strconv.Itoa(year)
-					cell, ok := valuesList[compositeId]
-					if ok {
-						balanceSum += cell.Value
-					}
-				}
+			balanceSum, err := domain.SumByKind(categories, valuesList, r.settings.MainCategoryOrder,
+				conf.KindIncome, month, year, convert)
+			if err != nil && convertErr == nil {
+				convertErr = err
 			}
 
-			// берем сумму расходов
-			compositeDate := utils.GetCompositeDate(month, year)
-			var consumption int
-			var ok bool
-			consumption, ok = r.consumptionByDate[compositeDate]
-			if !ok {
-				consumption = 0
+			// и сумму расходов
+			date := entity.MonthYear{Month: month, Year: year}
+			consumption, err := domain.SumByKind(categories, valuesList, r.settings.MainCategoryOrder,
+				conf.KindExpense, month, year, convert)
+			if err != nil && convertErr == nil {
+				convertErr = err
 			}
+			r.consumptionByDate[date] = consumption
 
 			// и остаток предыдущего месяца
 			prevBalance, err := r.getPreviousBalance(month, year)
@@ -92,72 +72,115 @@ func (r *CalculationCache) InitCache(valuesList map[string]domain.Cell, categori
 			}
 
 			balanceSum = balanceSum - consumption + prevBalance
-			r.balanceByDate[compositeDate] = balanceSum
+			r.balanceByDate[date] = balanceSum
+
+			// и расхождение со сверкой, от которой отсчитывается следующий месяц
+			checkpoint, ok := latestCheckpoints[date]
+			if ok {
+				diff, err := domain.CheckpointDiff(checkpoint, prevBalance, categories, valuesList,
+					r.settings.MainCategoryOrder, convert)
+				if err != nil && convertErr == nil {
+					convertErr = err
+				}
+				r.checkpointDiffByDate[date] = diff
+			}
 		}
 	}
 
+	if convertErr != nil {
+		return errors.WithMessage(convertErr, "convert currency")
+	}
+
 	return nil
 }
 
-func (r *CalculationCache) UpsertConsumption(month, year, newValue int) {
+func (r *CalculationCache) UpsertConsumption(month, year int, newValue entity.Money) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	compositeDate := utils.GetCompositeDate(month, year)
-	r.consumptionByDate[compositeDate] = newValue
+	date := entity.MonthYear{Month: month, Year: year}
+	r.consumptionByDate[date] = newValue
 }
 
-func (r *CalculationCache) UpsertBalance(month, year, newValue int) {
+func (r *CalculationCache) UpsertBalance(month, year int, newValue entity.Money) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	compositeDate := utils.GetCompositeDate(month, year)
-	r.balanceByDate[compositeDate] = newValue
+	date := entity.MonthYear{Month: month, Year: year}
+	r.balanceByDate[date] = newValue
 }
 
-func (r *CalculationCache) GetConsumption(month, year int) (int, bool) {
+func (r *CalculationCache) GetConsumption(month, year int) (entity.Money, bool) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	compositeDate := utils.GetCompositeDate(month, year)
-	value, ok := r.consumptionByDate[compositeDate]
+	date := entity.MonthYear{Month: month, Year: year}
+	value, ok := r.consumptionByDate[date]
 	return value, ok
 }
 
-func (r *CalculationCache) GetBalance(month, year int) (int, bool) {
+func (r *CalculationCache) GetBalance(month, year int) (entity.Money, bool) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	compositeDate := utils.GetCompositeDate(month, year)
-	value, ok := r.balanceByDate[compositeDate]
+	date := entity.MonthYear{Month: month, Year: year}
+	value, ok := r.balanceByDate[date]
 	return value, ok
 }
 
-func (r *CalculationCache) getPreviousBalance(month, year int) (int, error) {
+// SetAccountBalances
+// остатки по счетам пересчитываются целиком, поэтому заменяются все сразу
+func (r *CalculationCache) SetAccountBalances(balancesByDate map[entity.MonthYear]map[string]entity.Money) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	if year == r.settings.StartYear {
-		if month == r.settings.StartMonth {
-			return r.settings.StartMoney, nil
-		}
+	r.accountBalanceByDate = balancesByDate
+}
 
-		balance, ok := r.balanceByDate[utils.GetCompositeDate(month-1, year)]
-		if !ok {
-			return 0, errors.Errorf("not found month balance, %s %d", time.Month(month-1).String(), year)
-		}
+func (r *CalculationCache) GetAccountBalances(month, year int) (map[string]entity.Money, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	date := entity.MonthYear{Month: month, Year: year}
+	value, ok := r.accountBalanceByDate[date]
+	return value, ok
+}
 
-		return balance, nil
+// UpsertCheckpointDiff
+// расхождение сверки месяца; ok = false - в месяце нет сверки
+func (r *CalculationCache) UpsertCheckpointDiff(month, year int, diff entity.Money, ok bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	date := entity.MonthYear{Month: month, Year: year}
+	if !ok {
+		delete(r.checkpointDiffByDate, date)
+		return
 	}
 
-	if month == 1 {
+	r.checkpointDiffByDate[date] = diff
+}
+
+func (r *CalculationCache) GetCheckpointDiff(month, year int) (entity.Money, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	date := entity.MonthYear{Month: month, Year: year}
+	value, ok := r.checkpointDiffByDate[date]
+	return value, ok
+}
+
+// getPreviousBalance
+// остаток на конец предыдущего месяца; если в нем была сверка, остаток отсчитывается от нее
+func (r *CalculationCache) getPreviousBalance(month, year int) (entity.Money, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if year == r.settings.StartYear && month == r.settings.StartMonth {
+		return r.settings.StartMoney, nil
+	}
+
+	prevDate := entity.MonthYear{Month: month - 1, Year: year}
+	if month == 1 && year != r.settings.StartYear {
 		// если Январь, то берем Декабрь предыдущего года
-		balance, ok := r.balanceByDate[utils.GetCompositeDate(int(time.December), year-1)]
-		if !ok {
-			return 0, errors.Errorf("not found month balance, %s %d", time.December, year-1)
-		}
-		return balance, nil
+		prevDate = entity.MonthYear{Month: int(time.December), Year: year - 1}
 	}
 
-	balance, ok := r.balanceByDate[utils.GetCompositeDate(month-1, year)]
+	balance, ok := r.balanceByDate[prevDate]
 	if !ok {
-		return 0, errors.Errorf("not found month balance, %s %d", time.Month(month-1).String(), year)
+		return 0, errors.Errorf("not found month balance, %s %d", time.Month(prevDate.Month).String(), prevDate.Year)
 	}
 
-	return balance, nil
+	return balance + r.checkpointDiffByDate[prevDate], nil
 }
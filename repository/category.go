@@ -2,40 +2,46 @@ package repository
 
 import (
 	"context"
-	"encoding/csv"
-	"os"
 	"strconv"
+	"time"
 
-	"table-app/conf"
 	"table-app/domain"
 	"table-app/internal/db"
+	"table-app/internal/log"
 
 	"github.com/pkg/errors"
 )
 
 type Category struct {
-	db       db.DB
-	filePath string
+	db db.DB
 }
 
-func NewCategory(db db.DB, storage conf.Storage) Category {
-	var filePath string
-
-	if storage.Files != nil {
-		filePath = storage.Files.CategoryFilePath
+func NewCategory(db db.DB) Category {
+	return Category{
+		db: db,
 	}
+}
 
-	return Category{
-		db:       db,
+type CategoryFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewCategoryFile(filePath string, logger log.Logger) CategoryFile {
+	return CategoryFile{
 		filePath: filePath,
+		logger:   logger,
 	}
 }
 
-func (r Category) UpsertAll(ctx context.Context, categories []domain.Category) error {
-	if len(r.filePath) != 0 {
-		return r.writeToFile(categories)
-	}
+// categoryLayout - колонки файла категорий; в старых файлах обязательны первые четыре
+var categoryLayout = csvLayout{
+	columns: []string{"id", "name", "mainCategory", "priority", "currency", "parentId", "archivedMonth",
+		"archivedYear"},
+	legacyRequired: 4,
+}
 
+func (r Category) UpsertAll(ctx context.Context, categories []domain.Category) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return errors.WithMessage(err, "begin upsert category transaction")
@@ -64,13 +70,15 @@ func (r Category) UpsertAll(ctx context.Context, categories []domain.Category) e
 func upsertCategory(ctx context.Context, txExec TxFuncExec, category domain.Category) error {
 	q := `
 	INSERT INTO table_app.category
-    	(id, name, main_category, priority)
+    	(id, name, main_category, priority, currency, parent_id, archived_month, archived_year)
 	VALUES
-    	($1, $2, $3, $4)
-	ON CONFLICT (main_category, priority) 
-	DO UPDATE SET name = $2;`
+    	($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (id) 
+	DO UPDATE SET name = $2, main_category = $3, priority = $4, currency = $5, parent_id = $6,
+		archived_month = $7, archived_year = $8;`
 
-	_, err := txExec(ctx, q, category.Id, category.Name, category.MainCategory, category.Priority)
+	_, err := txExec(ctx, q, category.Id, category.Name, category.MainCategory, category.Priority, category.Currency,
+		category.ParentId, int(category.ArchivedMonth), category.ArchivedYear)
 	if err != nil {
 		return errors.WithMessage(err, "upsert category")
 	}
@@ -78,13 +86,54 @@ func upsertCategory(ctx context.Context, txExec TxFuncExec, category domain.Cate
 	return nil
 }
 
-func (r Category) GetAll(ctx context.Context) ([]domain.Category, error) {
-	if len(r.filePath) != 0 {
-		return r.readFromFile()
+// DeleteAll
+// удаляет категории из бд; ячейки категорий к этому моменту должны быть удалены или перенесены
+func (r Category) DeleteAll(ctx context.Context, categories []domain.Category) error {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin delete category transaction")
+	}
+
+	for _, category := range categories {
+		err = deleteCategory(ctx, tx.Exec, category.Id)
+		if err != nil {
+			rollbackErr := tx.Rollback(ctx)
+			if rollbackErr != nil {
+				return errors.WithMessage(err, "rollback delete category transaction")
+			}
+
+			return errors.WithMessage(err, "delete category transaction")
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit delete category transaction")
+	}
+
+	return nil
+}
+
+func deleteCategory(ctx context.Context, txExec TxFuncExec, id string) error {
+	q := `
+	DELETE FROM table_app.category
+	WHERE id = $1;`
+
+	_, err := txExec(ctx, q, id)
+	if err != nil {
+		return errors.WithMessage(err, "delete category")
 	}
 
+	return nil
+}
+
+func (r Category) GetAll(ctx context.Context) ([]domain.Category, error) {
 	q := `
-	SELECT id, name, main_category, priority
+	SELECT id, name, main_category, priority, currency, parent_id, archived_month, archived_year
 	FROM table_app.category;`
 
 	var list []domain.Category
@@ -96,68 +145,120 @@ func (r Category) GetAll(ctx context.Context) ([]domain.Category, error) {
 	defer rows.Close()
 	for rows.Next() {
 		var cat domain.Category
-		err = rows.Scan(&cat.Id, &cat.Name, &cat.MainCategory, &cat.Priority)
+		var archivedMonth int
+		err = rows.Scan(&cat.Id, &cat.Name, &cat.MainCategory, &cat.Priority, &cat.Currency, &cat.ParentId,
+			&archivedMonth, &cat.ArchivedYear)
 		if err != nil {
 			return nil, errors.WithMessage(err, "scan row")
 		}
+		cat.ArchivedMonth = time.Month(archivedMonth)
 		list = append(list, cat)
 	}
 
 	return list, nil
 }
 
-func (r Category) readFromFile() ([]domain.Category, error) {
-	file, err := os.OpenFile(r.filePath, os.O_RDONLY|os.O_CREATE, 0664)
+func (r CategoryFile) UpsertAll(ctx context.Context, categories []domain.Category) error {
+	return r.writeToFile(categories)
+}
+
+// DeleteAll
+// файл переписывается целиком при UpsertAll, поэтому удаление не требуется
+func (r CategoryFile) DeleteAll(ctx context.Context, categories []domain.Category) error {
+	return nil
+}
+
+func (r CategoryFile) GetAll(ctx context.Context) ([]domain.Category, error) {
+	return r.readFromFile()
+}
+
+func (r CategoryFile) readFromFile() ([]domain.Category, error) {
+	table, err := readCSVFile(r.filePath, categoryLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Category, 0, len(table.rows))
+	for _, row := range table.rows {
+		category, err := parseCategoryRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, category)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
 	if err != nil {
-		return nil, errors.WithMessage(err, "open file")
+		return nil, err
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+	return result, nil
+}
+
+// parseCategoryRow
+// валюта, вложенность и архивирование появились позже, в старых файлах этих колонок нет
+func parseCategoryRow(row csvRow) (domain.Category, error) {
+	var err error
+	category := domain.Category{}
+	category.Id = row.Value("id")
+	category.Name = row.Value("name")
+	category.MainCategory = row.Value("mainCategory")
+
+	category.Priority, err = strconv.Atoi(row.Value("priority"))
 	if err != nil {
-		return nil, errors.WithMessage(err, "read all file")
+		return category, errors.WithMessage(err, "convert priority value")
 	}
 
-	result := make([]domain.Category, 0)
-	for _, record := range records {
-		category := domain.Category{}
-		category.Id = record[0]
-		category.Name = record[1]
-		category.MainCategory = record[2]
+	category.Currency = row.Value("currency")
+	category.ParentId = row.Value("parentId")
 
-		priority, err := strconv.Atoi(record[3])
+	if value := row.Value("archivedMonth"); len(value) > 0 {
+		archivedMonth, err := strconv.Atoi(value)
 		if err != nil {
-			return nil, errors.WithMessage(err, "convert priority value")
+			return category, errors.WithMessage(err, "convert archived month value")
 		}
-		category.Priority = priority
+		category.ArchivedMonth = time.Month(archivedMonth)
+	}
 
-		result = append(result, category)
+	if value := row.Value("archivedYear"); len(value) > 0 {
+		category.ArchivedYear, err = strconv.Atoi(value)
+		if err != nil {
+			return category, errors.WithMessage(err, "convert archived year value")
+		}
 	}
 
-	return result, nil
+	return category, nil
 }
 
-func (r Category) writeToFile(data []domain.Category) error {
-	file, err := os.OpenFile(r.filePath, os.O_WRONLY, 0664)
+func (r CategoryFile) writeToFile(data []domain.Category) error {
+	file, err := r.writeTempFile(data)
 	if err != nil {
-		return errors.WithMessage(err, "open file")
+		return err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	return file.Commit()
+}
+
+// writeTempFile
+// записывает категории во временный файл; файл данных заменяется только после Commit
+func (r CategoryFile) writeTempFile(data []domain.Category) (*atomicFile, error) {
+	records := make([][]string, 0, len(data))
 	for _, category := range data {
-		err := writer.Write([]string{
+		records = append(records, []string{
 			category.Id,
 			category.Name,
 			category.MainCategory,
 			strconv.Itoa(category.Priority),
+			category.Currency,
+			category.ParentId,
+			strconv.Itoa(int(category.ArchivedMonth)),
+			strconv.Itoa(category.ArchivedYear),
 		})
-		if err != nil {
-			return errors.WithMessage(err, "write to file")
-		}
 	}
-	writer.Flush()
 
-	return nil
+	return writeCSVTemp(r.filePath, categoryLayout, records)
 }
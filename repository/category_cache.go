@@ -1,8 +1,9 @@
 package repository
 
 import (
-	"sort"
+	"slices"
 	"sync"
+	"time"
 
 	"table-app/conf"
 	"table-app/domain"
@@ -14,21 +15,37 @@ import (
 // CategoryCache
 // use mutex functions outside
 type CategoryCache struct {
-	// mainCategoryPriorityByName - это
-	// мап приоритетов основных категорий
-	mainCategoryPriorityByName map[string]int
+	// mainCategoryOrder - это
+	// мап приоритетов и видов основных категорий
+	mainCategoryOrder conf.Order
 
 	// orderArr
-	// используется для определения порядка
+	// используется для определения порядка; внутри основной категории
+	// подкатегории идут сразу за родителем (порядок обхода дерева)
 	orderArr [][]domain.Category
 
-	// categoryIndexByName - map[string][]int, где []int = {mainCategoryIndex, categoryIndex};
+	// categoryIndexByName - map[categoryKey][]int, где []int = {mainCategoryIndex, categoryIndex};
 	// используется для поиска категорий в кеше
-	categoryIndexByName map[string][]int
+	categoryIndexByName map[categoryKey][]int
+
+	// deleted - удаленные категории, удаление которых еще не сохранено
+	deleted []domain.Category
 
 	mutex sync.Mutex
 }
 
+// categoryKey
+// ключ категории в кеше; в отличие от склеенной строки не дает совпадений
+// вида "Доход"+"ы X" и "Доходы"+" X"
+type categoryKey struct {
+	mainCategory string
+	name         string
+}
+
+func newCategoryKey(mainCategory, name string) categoryKey {
+	return categoryKey{mainCategory: mainCategory, name: name}
+}
+
 func NewCategoryCache(order conf.Order) *CategoryCache {
 	orderArr := make([][]domain.Category, 0)
 	for i := 0; i < len(order); i++ {
@@ -36,53 +53,94 @@ func NewCategoryCache(order conf.Order) *CategoryCache {
 	}
 
 	return &CategoryCache{
-		mainCategoryPriorityByName: order,
-		orderArr:                   orderArr,
-		categoryIndexByName:        make(map[string][]int),
-		mutex:                      sync.Mutex{},
+		mainCategoryOrder:   order,
+		orderArr:            orderArr,
+		categoryIndexByName: make(map[categoryKey][]int),
+		deleted:             make([]domain.Category, 0),
+		mutex:               sync.Mutex{},
 	}
 }
 
 func (r *CategoryCache) InitCache(categories []domain.Category) {
 	for _, cat := range categories {
-		priority, ok := r.mainCategoryPriorityByName[cat.MainCategory]
+		priority, ok := r.mainCategoryOrder.Priority(cat.MainCategory)
 		if ok {
 			r.orderArr[priority] = append(r.orderArr[priority], cat)
 		}
 	}
 
 	for k := range r.orderArr {
-		sort.Slice(r.orderArr[k], func(i, j int) bool {
-			return r.orderArr[k][i].Priority < r.orderArr[k][j].Priority
-		})
+		r.orderArr[k] = domain.NewCategoryTree(r.orderArr[k]).Ordered()
 	}
 
+	r.reindex()
+}
+
+// reindex
+// пересобирает categoryIndexByName после изменения порядка категорий
+func (r *CategoryCache) reindex() {
+	r.categoryIndexByName = make(map[categoryKey][]int)
 	for i := range r.orderArr {
 		for j := range r.orderArr[i] {
 			category := r.orderArr[i][j]
-			r.categoryIndexByName[category.MainCategory+category.Name] = []int{i, j}
+			r.categoryIndexByName[newCategoryKey(category.MainCategory, category.Name)] = []int{i, j}
 		}
 	}
 }
 
 func (r *CategoryCache) Insert(newCategory domain.Category) error {
 	// находим приоритет основной категории
-	mainPriority, ok := r.mainCategoryPriorityByName[newCategory.MainCategory]
+	mainPriority, ok := r.mainCategoryOrder.Priority(newCategory.MainCategory)
 	if !ok {
 		return errors.Errorf("main category %s not found", newCategory.MainCategory)
 	}
 
-	// находим приоритет категории:
-	// порядковый номер в массиве категорий данной основной категории + 1
-	priority := len(r.orderArr[mainPriority]) + 1
-	newCategory.Priority = priority
+	if len(newCategory.ParentId) != 0 && !r.hasCategory(mainPriority, newCategory.ParentId) {
+		return errors.Errorf("parent category %s not found in %s", newCategory.ParentId, newCategory.MainCategory)
+	}
+
+	newCategory.Priority = r.nextPriority(mainPriority, newCategory.MainCategory)
 	newCategory.Id = uuid.New().String()
 
-	r.orderArr[mainPriority] = append(r.orderArr[mainPriority], newCategory)
-	r.categoryIndexByName[newCategory.MainCategory+newCategory.Name] = []int{mainPriority, priority}
+	r.orderArr[mainPriority] = domain.NewCategoryTree(append(r.orderArr[mainPriority], newCategory)).Ordered()
+	r.reindex()
 	return nil
 }
 
+// nextPriority
+// следующий после максимального приоритет в основной категории, чтобы приоритет оставался уникальным;
+// порядок среди соседей в дереве задается им же.
+// Удаленные, но еще не сохраненные категории тоже занимают свой приоритет в бд
+func (r *CategoryCache) nextPriority(mainPriority int, mainCategory string) int {
+	priority := 0
+	for _, category := range r.orderArr[mainPriority] {
+		priority = max(priority, category.Priority)
+	}
+	for _, category := range r.deleted {
+		if category.MainCategory == mainCategory {
+			priority = max(priority, category.Priority)
+		}
+	}
+
+	return priority + 1
+}
+
+func (r *CategoryCache) hasCategory(mainPriority int, id string) bool {
+	for _, category := range r.orderArr[mainPriority] {
+		if category.Id == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetTree
+// дерево всех категорий
+func (r *CategoryCache) GetTree() domain.CategoryTree {
+	return domain.NewCategoryTree(r.ReadAll())
+}
+
 func (r *CategoryCache) ReadAll() []domain.Category {
 	all := make([]domain.Category, 0)
 	for _, catArr := range r.orderArr {
@@ -94,27 +152,236 @@ func (r *CategoryCache) ReadAll() []domain.Category {
 	return all
 }
 
+// MainCategoryOrder
+// приоритеты и виды основных категорий
+func (r *CategoryCache) MainCategoryOrder() conf.Order {
+	return r.mainCategoryOrder
+}
+
 func (r *CategoryCache) GetCategoryArray() [][]domain.Category {
 	return r.orderArr
 }
 
+// IsInCache
+// название удаленной категории занято, пока ее удаление не сохранено
 func (r *CategoryCache) IsInCache(category domain.Category) bool {
-	_, ok := r.categoryIndexByName[category.MainCategory+category.Name]
-	return ok
+	_, ok := r.categoryIndexByName[newCategoryKey(category.MainCategory, category.Name)]
+	if ok {
+		return true
+	}
+
+	for _, deleted := range r.deleted {
+		if deleted.Name == category.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get
+// категория по основной категории и названию
+func (r *CategoryCache) Get(mainCategory, name string) (domain.Category, bool) {
+	idxs, ok := r.categoryIndexByName[newCategoryKey(mainCategory, name)]
+	if !ok {
+		return domain.Category{}, false
+	}
+
+	return r.orderArr[idxs[0]][idxs[1]], true
+}
+
+// Delete
+// удаляет категорию; ее подкатегории переходят к ее родителю.
+// Последнюю категорию основной категории удалить нельзя
+func (r *CategoryCache) Delete(category domain.Category) error {
+	err := r.CheckDelete(category)
+	if err != nil {
+		return err
+	}
+
+	idxs := r.categoryIndexByName[newCategoryKey(category.MainCategory, category.Name)]
+	mainPriority := idxs[0]
+	deleted := r.orderArr[mainPriority][idxs[1]]
+	categories := make([]domain.Category, 0, len(r.orderArr[mainPriority])-1)
+	for _, categ := range r.orderArr[mainPriority] {
+		if categ.Id == deleted.Id {
+			continue
+		}
+
+		if categ.ParentId == deleted.Id {
+			categ.ParentId = deleted.ParentId
+		}
+		categories = append(categories, categ)
+	}
+
+	r.orderArr[mainPriority] = domain.NewCategoryTree(categories).Ordered()
+	r.deleted = append(r.deleted, deleted)
+	r.reindex()
+	return nil
+}
+
+// CheckDelete
+// проверяет, что категорию можно удалить
+func (r *CategoryCache) CheckDelete(category domain.Category) error {
+	idxs, ok := r.categoryIndexByName[newCategoryKey(category.MainCategory, category.Name)]
+	if !ok {
+		return errors.Errorf("category %s %s not found", category.MainCategory, category.Name)
+	}
+
+	if len(r.orderArr[idxs[0]]) == 1 {
+		return errors.Errorf("category %s is the last one in %s", category.Name, category.MainCategory)
+	}
+
+	return nil
+}
+
+// SetArchived
+// архивирует категорию с указанного месяца; нулевой год возвращает категорию из архива
+func (r *CategoryCache) SetArchived(category domain.Category, month time.Month, year int) error {
+	idxs, ok := r.categoryIndexByName[newCategoryKey(category.MainCategory, category.Name)]
+	if !ok {
+		return errors.Errorf("category %s %s not found", category.MainCategory, category.Name)
+	}
+
+	archived := &r.orderArr[idxs[0]][idxs[1]]
+	archived.ArchivedMonth = month
+	archived.ArchivedYear = year
+	if year == 0 {
+		archived.ArchivedMonth = 0
+	}
+
+	return nil
+}
+
+// ReadDeleted
+// категории, удаление которых еще не сохранено
+func (r *CategoryCache) ReadDeleted() []domain.Category {
+	return append([]domain.Category(nil), r.deleted...)
+}
+
+// ClearDeleted
+// вызывается после сохранения удаления категорий
+func (r *CategoryCache) ClearDeleted() {
+	r.deleted = make([]domain.Category, 0)
 }
 
 func (r *CategoryCache) UpdateCategory(old, new domain.Category) error {
-	idxs, ok := r.categoryIndexByName[old.MainCategory+old.Name]
+	idxs, ok := r.categoryIndexByName[newCategoryKey(old.MainCategory, old.Name)]
 	if !ok {
 		return errors.Errorf("category %s %s not found", old.MainCategory, old.Name)
 	}
 
-	r.categoryIndexByName[new.MainCategory+new.Name] = idxs
-	delete(r.categoryIndexByName, old.MainCategory+old.Name)
+	r.orderArr[idxs[0]][idxs[1]].Name = new.Name
+	r.categoryIndexByName[newCategoryKey(new.MainCategory, new.Name)] = idxs
+	delete(r.categoryIndexByName, newCategoryKey(old.MainCategory, old.Name))
 
 	return nil
 }
 
+// Reorder
+// сдвигает категорию на shift позиций среди категорий с тем же родителем;
+// соседи обмениваются своими приоритетами, поэтому набор приоритетов не меняется
+func (r *CategoryCache) Reorder(category domain.Category, shift int) error {
+	idxs, ok := r.categoryIndexByName[newCategoryKey(category.MainCategory, category.Name)]
+	if !ok {
+		return errors.Errorf("category %s %s not found", category.MainCategory, category.Name)
+	}
+
+	mainPriority := idxs[0]
+	id := r.orderArr[mainPriority][idxs[1]].Id
+	siblings := domain.NewCategoryTree(r.orderArr[mainPriority]).Siblings(id)
+
+	position := slices.IndexFunc(siblings, func(sibling domain.Category) bool {
+		return sibling.Id == id
+	})
+	newPosition := position + shift
+	if newPosition < 0 || newPosition >= len(siblings) || shift == 0 {
+		return nil
+	}
+
+	priorities := make([]int, 0, len(siblings))
+	for _, sibling := range siblings {
+		priorities = append(priorities, sibling.Priority)
+	}
+
+	moved := slices.Delete(slices.Clone(siblings), position, position+1)
+	moved = slices.Insert(moved, newPosition, siblings[position])
+
+	priorityById := make(map[string]int, len(moved))
+	for k, sibling := range moved {
+		priorityById[sibling.Id] = priorities[k]
+	}
+
+	categories := r.orderArr[mainPriority]
+	for k := range categories {
+		if priority, ok := priorityById[categories[k].Id]; ok {
+			categories[k].Priority = priority
+		}
+	}
+
+	r.orderArr[mainPriority] = domain.NewCategoryTree(categories).Ordered()
+	r.reindex()
+	return nil
+}
+
+// ChangeMainCategory
+// переносит категорию вместе с подкатегориями в конец другой основной категории;
+// возвращает перенесенные категории в том виде, в котором они были до переноса
+func (r *CategoryCache) ChangeMainCategory(category domain.Category, mainCategory string) ([]domain.Category, error) {
+	idxs, ok := r.categoryIndexByName[newCategoryKey(category.MainCategory, category.Name)]
+	if !ok {
+		return nil, errors.Errorf("category %s %s not found", category.MainCategory, category.Name)
+	}
+
+	targetPriority, ok := r.mainCategoryOrder.Priority(mainCategory)
+	if !ok {
+		return nil, errors.Errorf("main category %s not found", mainCategory)
+	}
+
+	sourcePriority := idxs[0]
+	if sourcePriority == targetPriority {
+		return nil, nil
+	}
+
+	root := r.orderArr[sourcePriority][idxs[1]]
+	moved := append([]domain.Category{root}, domain.NewCategoryTree(r.orderArr[sourcePriority]).Descendants(root.Id)...)
+	if len(moved) == len(r.orderArr[sourcePriority]) {
+		return nil, errors.Errorf("category %s is the last one in %s", category.Name, category.MainCategory)
+	}
+
+	movedIds := make(map[string]bool, len(moved))
+	for _, categ := range moved {
+		if _, ok := r.categoryIndexByName[newCategoryKey(mainCategory, categ.Name)]; ok {
+			return nil, errors.Errorf("category %s already exists in %s", categ.Name, mainCategory)
+		}
+		movedIds[categ.Id] = true
+	}
+
+	remaining := make([]domain.Category, 0, len(r.orderArr[sourcePriority])-len(moved))
+	for _, categ := range r.orderArr[sourcePriority] {
+		if !movedIds[categ.Id] {
+			remaining = append(remaining, categ)
+		}
+	}
+
+	// порядок обхода дерева сохраняет порядок соседей при последовательной выдаче приоритетов
+	target := slices.Clone(r.orderArr[targetPriority])
+	priority := r.nextPriority(targetPriority, mainCategory)
+	for k, categ := range moved {
+		categ.MainCategory = mainCategory
+		categ.Priority = priority + k
+		if categ.Id == root.Id {
+			categ.ParentId = ""
+		}
+		target = append(target, categ)
+	}
+
+	r.orderArr[sourcePriority] = domain.NewCategoryTree(remaining).Ordered()
+	r.orderArr[targetPriority] = domain.NewCategoryTree(target).Ordered()
+	r.reindex()
+	return moved, nil
+}
+
 func (r *CategoryCache) Lock() {
 	r.mutex.Lock()
 }
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"testing"
+
+	"table-app/conf"
+	"table-app/domain"
+)
+
+func TestCategoryCacheCollidingNames(t *testing.T) {
+	cache := NewCategoryCache(conf.Order{
+		"Доход":  {Priority: 0, Kind: conf.KindIncome},
+		"Доходы": {Priority: 1, Kind: conf.KindIncome},
+	})
+
+	// при склеивании названий обе категории дают "Доходы X"
+	err := cache.Insert(domain.Category{MainCategory: "Доход", Name: "ы X"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.IsInCache(domain.Category{MainCategory: "Доходы", Name: " X"}) {
+		t.Fatal("category is found by a colliding name")
+	}
+
+	err = cache.Insert(domain.Category{MainCategory: "Доходы", Name: " X"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	short, ok := cache.Get("Доход", "ы X")
+	if !ok || short.MainCategory != "Доход" {
+		t.Errorf("Get(Доход, ы X) = %+v, %v", short, ok)
+	}
+	long, ok := cache.Get("Доходы", " X")
+	if !ok || long.MainCategory != "Доходы" {
+		t.Errorf("Get(Доходы,  X) = %+v, %v", long, ok)
+	}
+	if short.Id == long.Id {
+		t.Error("colliding names resolve to the same category")
+	}
+}
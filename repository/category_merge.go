@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"table-app/conf"
+	"table-app/domain"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+// CategoryMerge
+// сохраняет результат слияния категорий одной транзакцией бд
+type CategoryMerge struct {
+	db db.DB
+}
+
+func NewCategoryMerge(db db.DB) CategoryMerge {
+	return CategoryMerge{
+		db: db,
+	}
+}
+
+// CategoryMergeFile
+// сохраняет результат слияния, переписывая файлы категорий, ячеек и операций
+type CategoryMergeFile struct {
+	table       TableFile
+	transaction TransactionFile
+	category    CategoryFile
+}
+
+func NewCategoryMergeFile(files conf.Files, logger log.Logger) CategoryMergeFile {
+	return CategoryMergeFile{
+		table:       TableFile{filePath: files.TableFilePath, logger: logger},
+		transaction: TransactionFile{filePath: files.TransactionFilePath, logger: logger},
+		category:    NewCategoryFile(files.CategoryFilePath, logger),
+	}
+}
+
+// MergeData
+// изменения после слияния: Cells - ячейки, в которые перенесены значения, AllCells - все ячейки
+// после слияния, DeletedCells - поглощенные ячейки, Categories - все категории, Deleted - удаленная категория
+type MergeData struct {
+	Cells        []domain.Cell
+	AllCells     []domain.Cell
+	DeletedCells []domain.Cell
+	Categories   []domain.Category
+	Deleted      domain.Category
+}
+
+func (r CategoryMerge) Save(ctx context.Context, data MergeData) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin merge transaction")
+	}
+
+	err = saveMerge(ctx, tx.Exec, data)
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback merge transaction")
+		}
+
+		return errors.WithMessage(err, "merge transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit merge transaction")
+	}
+
+	return nil
+}
+
+// saveMerge
+// порядок важен из-за внешних ключей: ячейки переводятся на новую категорию и получают операции
+// до удаления поглощенных ячеек, а категория удаляется, когда на нее не ссылается ни одна ячейка
+func saveMerge(ctx context.Context, txExec TxFuncExec, data MergeData) error {
+	for _, category := range data.Categories {
+		err := upsertCategory(ctx, txExec, category)
+		if err != nil {
+			return errors.WithMessage(err, "upsert category")
+		}
+	}
+
+	for _, cell := range data.Cells {
+		err := upsertCell(ctx, txExec, cell)
+		if err != nil {
+			return errors.WithMessage(err, "upsert cell")
+		}
+
+		for _, transaction := range cell.Transactions {
+			if transaction.IsDeleted {
+				err = deleteTransaction(ctx, txExec, transaction.Id)
+			} else {
+				err = upsertTransaction(ctx, txExec, transaction)
+			}
+
+			if err != nil {
+				return errors.WithMessage(err, "upsert transaction")
+			}
+		}
+	}
+
+	for _, cell := range data.DeletedCells {
+		err := deleteCell(ctx, txExec, cell.Id)
+		if err != nil {
+			return errors.WithMessage(err, "delete cell")
+		}
+	}
+
+	err := deleteCategory(ctx, txExec, data.Deleted.Id)
+	if err != nil {
+		return errors.WithMessage(err, "delete category")
+	}
+
+	return nil
+}
+
+// Save
+// файлы переписываются целиком из кешей: сначала все три пишутся во временные файлы, и ошибка
+// записи не меняет файлы данных. Затем файлы заменяются по очереди - ячейки, операции, категории;
+// если замена одного из них не удалась, уже замененные файлы возвращаются к прежнему содержимому.
+// Рассогласование файлов возможно, только если не удался и возврат, об этом говорит ошибка
+func (r CategoryMergeFile) Save(ctx context.Context, data MergeData) error {
+	transactions := make([]domain.Transaction, 0)
+	for _, cell := range data.AllCells {
+		transactions = append(transactions, cell.Transactions...)
+	}
+
+	files := make([]*atomicFile, 0, 3)
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+
+	file, err := r.table.writeTempFile(data.AllCells)
+	if err != nil {
+		return errors.WithMessage(err, "write cells")
+	}
+	files = append(files, file)
+
+	file, err = r.transaction.writeTempFile(transactions)
+	if err != nil {
+		return errors.WithMessage(err, "write transactions")
+	}
+	files = append(files, file)
+
+	file, err = r.category.writeTempFile(data.Categories)
+	if err != nil {
+		return errors.WithMessage(err, "write categories")
+	}
+	files = append(files, file)
+
+	return commitFiles(files)
+}
+
+// commitFiles
+// заменяет файлы данных временными файлами по очереди; если замена не удалась, уже замененные
+// файлы возвращаются к содержимому до замены
+func commitFiles(files []*atomicFile) error {
+	originals := make([]fileSnapshot, 0, len(files))
+	for _, file := range files {
+		original, err := takeFileSnapshot(file.filePath)
+		if err != nil {
+			return errors.WithMessage(err, "read data file")
+		}
+		originals = append(originals, original)
+	}
+
+	for i, file := range files {
+		err := file.Commit()
+		if err == nil {
+			continue
+		}
+
+		// файл i мог быть уже переименован, если не удалась только синхронизация каталога
+		for _, original := range originals[:i+1] {
+			restoreErr := original.restore()
+			if restoreErr != nil {
+				return errors.WithMessagef(err, "replace data file, data files are out of sync: restore %s: %v",
+					original.filePath, restoreErr)
+			}
+		}
+
+		return errors.WithMessage(err, "replace data file")
+	}
+
+	return nil
+}
+
+// fileSnapshot
+// содержимое файла данных до замены, чтобы вернуть его при неудачном слиянии
+type fileSnapshot struct {
+	filePath string
+	content  []byte
+	exists   bool
+}
+
+func takeFileSnapshot(filePath string) (fileSnapshot, error) {
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return fileSnapshot{filePath: filePath}, nil
+	}
+	if err != nil {
+		return fileSnapshot{}, err
+	}
+
+	return fileSnapshot{filePath: filePath, content: content, exists: true}, nil
+}
+
+func (s fileSnapshot) restore() error {
+	if !s.exists {
+		err := os.Remove(s.filePath)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	file, err := writeAtomicTemp(s.filePath, func(w io.Writer) error {
+		_, err := w.Write(s.content)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Commit()
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommitFilesRestoresOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	table := filepath.Join(dir, "table.csv")
+	transaction := filepath.Join(dir, "transaction.csv")
+	category := filepath.Join(dir, "category.csv")
+
+	for _, filePath := range []string{table, category} {
+		err := os.WriteFile(filePath, []byte("old"), 0664)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := make([]*atomicFile, 0, 3)
+	for _, filePath := range []string{table, transaction, category} {
+		file, err := writeAtomicTemp(filePath, func(w io.Writer) error {
+			_, err := w.Write([]byte("new"))
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		files = append(files, file)
+	}
+
+	// временный файл категорий пропал: его замена не удастся после замены ячеек и операций
+	err := os.Remove(files[2].Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = commitFiles(files)
+	if err == nil {
+		t.Fatal("want replace error")
+	}
+
+	assertFileContent(t, table, "old")
+	assertFileContent(t, category, "old")
+	if _, err := os.Stat(transaction); !os.IsNotExist(err) {
+		t.Errorf("transaction file that did not exist before is left: %v", err)
+	}
+	assertDirFiles(t, dir, []string{"category.csv", "table.csv"})
+}
+
+func TestCommitFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := make([]*atomicFile, 0, 2)
+	for _, name := range []string{"a.csv", "b.csv"} {
+		file, err := writeAtomicTemp(filepath.Join(dir, name), func(w io.Writer) error {
+			_, err := w.Write([]byte(name))
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		files = append(files, file)
+	}
+
+	err := commitFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertFileContent(t, filepath.Join(dir, "a.csv"), "a.csv")
+	assertFileContent(t, filepath.Join(dir, "b.csv"), "b.csv")
+	assertDirFiles(t, dir, []string{"a.csv", "b.csv"})
+}
@@ -1,59 +1,170 @@
 package repository
 
 import (
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
 	"table-app/domain"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 )
 
 // CellsCache
 // use mutex functions outside
 type CellsCache struct {
-	cache map[string]domain.Cell
+	cache map[domain.CellKey]domain.Cell
+
+	// deleted - ячейки удаленных категорий, удаление которых еще не сохранено
+	deleted []domain.Cell
+
 	mutex sync.Mutex
 }
 
 func NewCellsCache() *CellsCache {
 	return &CellsCache{
-		cache: make(map[string]domain.Cell),
-		mutex: sync.Mutex{},
+		cache:   make(map[domain.CellKey]domain.Cell),
+		deleted: make([]domain.Cell, 0),
+		mutex:   sync.Mutex{},
 	}
 }
 
-func (r *CellsCache) InitCache(cells []domain.Cell) {
+func (r *CellsCache) InitCache(cells []domain.Cell, transactions []domain.Transaction) {
+	transactionsByCellId := make(map[string][]domain.Transaction)
+	for _, transaction := range transactions {
+		transactionsByCellId[transaction.CellId] = append(transactionsByCellId[transaction.CellId], transaction)
+	}
+
 	for _, cell := range cells {
-		compositeId := cell.CompositeId()
+		key := cell.Key()
 		cell.IsUpdated = false
-		r.cache[compositeId] = cell
+		cell.Transactions = transactionsByCellId[cell.Id]
+		r.cache[key] = cell
 	}
 }
 
 func (r *CellsCache) Upsert(newCell domain.Cell) {
-	compositeId := newCell.CompositeId()
-	cell, isExist := r.cache[compositeId]
+	key := newCell.Key()
+	cell, isExist := r.cache[key]
 	if !isExist {
 		newCell.IsUpdated = true
 		newCell.Id = uuid.New().String()
-		r.cache[compositeId] = newCell
+		newCell.Transactions = bindTransactions(newCell.Id, newCell.Transactions)
+		newCell.CalculateValue()
+		r.cache[key] = newCell
 		return
 	}
 
 	cell.Value = newCell.Value
+	if newCell.Transactions != nil {
+		cell.Transactions = bindTransactions(cell.Id, mergeTransactions(cell.Transactions, newCell.Transactions))
+		cell.CalculateValue()
+	}
 	cell.IsUpdated = true
-	r.cache[compositeId] = cell
+	r.cache[key] = cell
+}
+
+// AddTransaction
+// добавляет операцию в ячейку key, создавая ячейку при необходимости; false - операция
+// с таким id уже есть. Чтение и изменение ячейки идут под одной блокировкой кеша
+func (r *CellsCache) AddTransaction(key domain.CellKey, transaction domain.Transaction) (domain.Cell, bool, error) {
+	cell, ok := r.cache[key]
+	if !ok {
+		cell = domain.Cell{
+			MainCategory: key.MainCategory,
+			Category:     key.Category,
+			Month:        key.Month,
+			Year:         key.Year,
+		}
+	}
+
+	if cell.HasTransaction(transaction.Id) {
+		return domain.Cell{}, false, nil
+	}
+
+	cell.Transactions = slices.Clone(cell.Transactions)
+	cell.AddTransaction(transaction)
+	err := cell.Validate()
+	if err != nil {
+		return domain.Cell{}, false, errors.WithMessage(err, "validate cell")
+	}
+	r.Upsert(cell)
+
+	return r.cache[key], true, nil
+}
+
+// mergeTransactions
+// операции updated поверх операций current по id: операции, которых нет в updated,
+// сохраняются - их могли добавить, пока ячейка была открыта в окне. Удаление операции
+// всегда идет пометкой IsDeleted, поэтому отсутствие в списке не означает удаления
+func mergeTransactions(current, updated []domain.Transaction) []domain.Transaction {
+	updatedById := make(map[string]domain.Transaction, len(updated))
+	for _, transaction := range updated {
+		if len(transaction.Id) != 0 {
+			updatedById[transaction.Id] = transaction
+		}
+	}
+
+	result := make([]domain.Transaction, 0, len(current)+len(updated))
+	merged := make(map[string]bool, len(updated))
+	for _, transaction := range current {
+		if item, ok := updatedById[transaction.Id]; ok {
+			transaction = item
+			merged[transaction.Id] = true
+		}
+		result = append(result, transaction)
+	}
+
+	for _, transaction := range updated {
+		if len(transaction.Id) == 0 || !merged[transaction.Id] {
+			result = append(result, transaction)
+		}
+	}
+
+	return result
+}
+
+// ClearDeletedTransactions
+// убирает из кеша операции, удаление которых уже сохранено
+func (r *CellsCache) ClearDeletedTransactions() {
+	for key, cell := range r.cache {
+		active := cell.ActiveTransactions()
+		if len(active) == len(cell.Transactions) {
+			continue
+		}
+
+		cell.Transactions = active
+		r.cache[key] = cell
+	}
+}
+
+// bindTransactions
+// привязывает операции к ячейке, новым операциям присваивает id
+func bindTransactions(cellId string, transactions []domain.Transaction) []domain.Transaction {
+	result := make([]domain.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if len(transaction.Id) == 0 {
+			transaction.Id = uuid.New().String()
+			transaction.IsUpdated = true
+		}
+		transaction.CellId = cellId
+
+		result = append(result, transaction)
+	}
+
+	return result
 }
 
 func (r *CellsCache) Insert(newCell domain.Cell) {
-	compositeId := newCell.CompositeId()
+	key := newCell.Key()
 	newCell.IsUpdated = true
-	r.cache[compositeId] = newCell
+	r.cache[key] = newCell
 }
 
-func (r *CellsCache) Get(compositeId string) (domain.Cell, bool) {
-	cell, ok := r.cache[compositeId]
+func (r *CellsCache) Get(key domain.CellKey) (domain.Cell, bool) {
+	cell, ok := r.cache[key]
 	return cell, ok
 }
 
@@ -65,43 +176,169 @@ func (r *CellsCache) ReadAll() []domain.Cell {
 	return all
 }
 
-func (r *CellsCache) Delete(compositeId string) {
-	_, ok := r.cache[compositeId]
+func (r *CellsCache) Delete(key domain.CellKey) {
+	_, ok := r.cache[key]
 	if ok {
-		delete(r.cache, compositeId)
+		delete(r.cache, key)
 	}
 }
 
-func (r *CellsCache) GetList() map[string]domain.Cell {
-	return r.cache
+// GetList
+// копия ячеек кеша: ее обходят после снятия блокировки, пока планировщик в другой горутине
+// добавляет ячейки в кеш
+func (r *CellsCache) GetList() map[domain.CellKey]domain.Cell {
+	return maps.Clone(r.cache)
 }
 
 // UpdateCategoryName
-// обновляем compositeId в кеше, так как меняется название категории
+// обновляем ключи в кеше, так как меняется название или основная категория
 func (r *CellsCache) UpdateCategoryName(oldCategory, newCategory domain.Category, startMonth, startYear int) {
 	currentYear := time.Now().Year()
 
 	for year := startYear; year <= currentYear; year++ {
-		currentMonth := time.December
-		if year == currentYear {
-			currentMonth = time.Now().Month()
+		firstMonth := time.January
+		if year == startYear {
+			firstMonth = time.Month(startMonth)
 		}
 
-		for month := time.Month(startMonth); month <= currentMonth; month++ {
-			compositeId := oldCategory.CellCompositeId(month, year)
-			cell, ok := r.cache[compositeId]
+		// будущие месяцы текущего года тоже могут быть заполнены
+		for month := firstMonth; month <= time.December; month++ {
+			key := oldCategory.CellKey(month, year)
+			cell, ok := r.cache[key]
 			if !ok {
 				continue
 			}
 
+			cell.MainCategory = newCategory.MainCategory
 			cell.Category = newCategory.Name
 			cell.IsUpdated = true
 
-			newCompositeId := newCategory.CellCompositeId(month, year)
-			r.cache[newCompositeId] = cell
-			delete(r.cache, compositeId)
+			newKey := newCategory.CellKey(month, year)
+			r.cache[newKey] = cell
+			delete(r.cache, key)
+		}
+	}
+}
+
+// RenamePayee
+// меняет получателя во всех операциях; операции копируются, чтобы не менять прочитанные ранее ячейки
+func (r *CellsCache) RenamePayee(oldName, newName string) {
+	for key, cell := range r.cache {
+		transactions := make([]domain.Transaction, 0, len(cell.Transactions))
+		changed := false
+		for _, transaction := range cell.Transactions {
+			if !transaction.IsDeleted && domain.IsSamePayee(transaction.Payee, oldName) {
+				transaction.Payee = newName
+				transaction.IsUpdated = true
+				changed = true
+			}
+			transactions = append(transactions, transaction)
+		}
+
+		if !changed {
+			continue
+		}
+
+		cell.Transactions = transactions
+		cell.IsUpdated = true
+		r.cache[key] = cell
+	}
+}
+
+// DeleteCategory
+// убирает из кеша все ячейки категории
+func (r *CellsCache) DeleteCategory(category domain.Category) {
+	for key, cell := range r.cache {
+		if cell.MainCategory != category.MainCategory || cell.Category != category.Name {
+			continue
+		}
+
+		r.markDeleted(cell)
+		delete(r.cache, key)
+	}
+}
+
+// MoveCategory
+// переносит ячейки категории from в категорию to; если у to уже есть ячейка того же месяца,
+// значение и операции добавляются в нее, а ячейка from удаляется.
+// Возвращает измененные ячейки категории to и поглощенные ими ячейки
+func (r *CellsCache) MoveCategory(from, to domain.Category) ([]domain.Cell, []domain.Cell, error) {
+	// валюты проверяются до изменений, чтобы не перенести ячейки частично
+	for _, cell := range r.cache {
+		if cell.MainCategory != from.MainCategory || cell.Category != from.Name {
+			continue
+		}
+
+		target, ok := r.cache[to.CellKey(cell.Month, cell.Year)]
+		if ok && cellCurrency(cell, from) != cellCurrency(target, to) {
+			return nil, nil, errors.Errorf("cell currency %s differs from %s in %02d.%d",
+				cellCurrency(cell, from), cellCurrency(target, to), cell.Month, cell.Year)
+		}
+	}
+
+	updated := make([]domain.Cell, 0)
+	absorbed := make([]domain.Cell, 0)
+	for key, cell := range r.cache {
+		if cell.MainCategory != from.MainCategory || cell.Category != from.Name {
+			continue
+		}
+		delete(r.cache, key)
+
+		targetKey := to.CellKey(cell.Month, cell.Year)
+		target, ok := r.cache[targetKey]
+		if !ok {
+			if len(cell.Currency) == 0 {
+				cell.Currency = from.Currency
+			}
+			cell.MainCategory = to.MainCategory
+			cell.Category = to.Name
+			cell.IsUpdated = true
+			r.cache[targetKey] = cell
+			updated = append(updated, cell)
+			continue
 		}
+
+		target.Absorb(cell)
+		target.Transactions = bindTransactions(target.Id, target.Transactions)
+		target.IsUpdated = true
+		r.cache[targetKey] = target
+		updated = append(updated, target)
+
+		r.markDeleted(cell)
+		absorbed = append(absorbed, cell)
 	}
+
+	return updated, absorbed, nil
+}
+
+// markDeleted
+// операции удаляемой ячейки удаляются вместе с ней
+func (r *CellsCache) markDeleted(cell domain.Cell) {
+	cell.IsDeleted = true
+	cell.Transactions = nil
+	r.deleted = append(r.deleted, cell)
+}
+
+// cellCurrency
+// валюта ячейки с учетом валюты категории
+func cellCurrency(cell domain.Cell, category domain.Category) string {
+	if len(cell.Currency) != 0 {
+		return cell.Currency
+	}
+
+	return category.Currency
+}
+
+// ReadDeleted
+// ячейки, удаление которых еще не сохранено
+func (r *CellsCache) ReadDeleted() []domain.Cell {
+	return append([]domain.Cell(nil), r.deleted...)
+}
+
+// ClearDeleted
+// вызывается после сохранения удаления ячеек
+func (r *CellsCache) ClearDeleted() {
+	r.deleted = make([]domain.Cell, 0)
 }
 
 func (r *CellsCache) Lock() {
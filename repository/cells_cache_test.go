@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"table-app/domain"
+)
+
+func TestCellsCacheAddTransactionSurvivesStaleUpsert(t *testing.T) {
+	cache := NewCellsCache()
+	key := domain.NewCellKey("Расходы", "Еда", time.March, 2024)
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	_, ok, err := cache.AddTransaction(key, domain.Transaction{Id: "manual", Date: date, Amount: 1000})
+	if err != nil || !ok {
+		t.Fatalf("add manual transaction: %v, %v", ok, err)
+	}
+
+	// окно суммы открыто до проведения регулярного платежа
+	stale, _ := cache.Get(key)
+
+	_, ok, err = cache.AddTransaction(key, domain.Transaction{Id: "recurring", Date: date, Amount: 500})
+	if err != nil || !ok {
+		t.Fatalf("add recurring transaction: %v, %v", ok, err)
+	}
+
+	_, ok, err = cache.AddTransaction(key, domain.Transaction{Id: "recurring", Date: date, Amount: 500})
+	if err != nil || ok {
+		t.Fatalf("second add of the same transaction = %v, %v, want false", ok, err)
+	}
+
+	stale.Transactions[0].Amount = 2000
+	stale.Transactions = append(stale.Transactions, domain.Transaction{Date: date, Amount: 300})
+	cache.Upsert(stale)
+
+	cell, _ := cache.Get(key)
+	if len(cell.Transactions) != 3 {
+		t.Fatalf("transactions count = %d, want 3: %+v", len(cell.Transactions), cell.Transactions)
+	}
+
+	wantIds := []string{"manual", "recurring"}
+	for i, id := range wantIds {
+		if cell.Transactions[i].Id != id {
+			t.Errorf("transaction %d id = %s, want %s", i, cell.Transactions[i].Id, id)
+		}
+	}
+	if cell.Value != 2800 {
+		t.Errorf("cell value = %d, want 2800", cell.Value)
+	}
+}
+
+func TestCellsCacheAddTransactionValidates(t *testing.T) {
+	cache := NewCellsCache()
+	key := domain.NewCellKey("Расходы", "Еда", time.March, 2024)
+
+	_, _, err := cache.AddTransaction(key, domain.Transaction{Id: "empty date", Amount: 100})
+	if err == nil {
+		t.Fatal("want validation error")
+	}
+
+	if _, ok := cache.Get(key); ok {
+		t.Error("invalid transaction must not create a cell")
+	}
+}
+
+func TestCellsCacheGetListIsCopy(t *testing.T) {
+	cache := NewCellsCache()
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+	_, _, err := cache.AddTransaction(domain.NewCellKey("Расходы", "Еда", time.March, 2024),
+		domain.Transaction{Id: "t1", Date: date, Amount: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := cache.GetList()
+
+	// планировщик добавляет ячейку, пока список обходят без блокировки
+	_, _, err = cache.AddTransaction(domain.NewCellKey("Расходы", "Кафе", time.March, 2024),
+		domain.Transaction{Id: "t2", Date: date, Amount: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(list) != 1 {
+		t.Errorf("list has %d cells, want a copy with 1", len(list))
+	}
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Checkpoint struct {
+	db db.DB
+}
+
+func NewCheckpoint(db db.DB) Checkpoint {
+	return Checkpoint{
+		db: db,
+	}
+}
+
+type CheckpointFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewCheckpointFile(filePath string, logger log.Logger) CheckpointFile {
+	return CheckpointFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// checkpointLayout - колонки файла сверок остатков
+var checkpointLayout = csvLayout{
+	columns: []string{"id", "date", "balance", "note"},
+}
+
+// ReplaceAll
+// сверок немного, поэтому список сохраняется целиком
+func (r Checkpoint) ReplaceAll(ctx context.Context, items []domain.Checkpoint) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace checkpoints transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.checkpoint;`)
+	if err == nil {
+		for _, item := range items {
+			err = insertCheckpoint(ctx, tx.Exec, item)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace checkpoints transaction")
+		}
+
+		return errors.WithMessage(err, "replace checkpoints transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace checkpoints transaction")
+	}
+
+	return nil
+}
+
+func insertCheckpoint(ctx context.Context, txExec TxFuncExec, item domain.Checkpoint) error {
+	q := `
+	INSERT INTO table_app.checkpoint
+    	(id, date, balance, note)
+	VALUES
+    	($1, $2, $3, $4);`
+
+	_, err := txExec(ctx, q, item.Id, item.Date, int64(item.Balance), item.Note)
+	if err != nil {
+		return errors.WithMessage(err, "insert checkpoint")
+	}
+
+	return nil
+}
+
+func (r Checkpoint) GetAll(ctx context.Context) ([]domain.Checkpoint, error) {
+	q := `
+	SELECT id, date, balance, note
+	FROM table_app.checkpoint
+	ORDER BY date;`
+
+	var items []domain.Checkpoint
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get checkpoints")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var item domain.Checkpoint
+		var balance int64
+		err = rows.Scan(&item.Id, &item.Date, &balance, &item.Note)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		item.Balance = entity.Money(balance)
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r CheckpointFile) ReplaceAll(ctx context.Context, items []domain.Checkpoint) error {
+	return r.writeToFile(items)
+}
+
+func (r CheckpointFile) GetAll(ctx context.Context) ([]domain.Checkpoint, error) {
+	return r.readFromFile()
+}
+
+func (r CheckpointFile) readFromFile() ([]domain.Checkpoint, error) {
+	table, err := readCSVFile(r.filePath, checkpointLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Checkpoint, 0, len(table.rows))
+	for _, row := range table.rows {
+		item, err := parseCheckpointRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseCheckpointRow(row csvRow) (domain.Checkpoint, error) {
+	var err error
+	item := domain.Checkpoint{}
+	item.Id = row.Value("id")
+
+	item.Date, err = time.Parse(transactionDateLayout, row.Value("date"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert checkpoint date")
+	}
+
+	item.Balance, err = entity.ParseMoney(row.Value("balance"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert checkpoint balance")
+	}
+
+	item.Note = row.Value("note")
+
+	return item, nil
+}
+
+func (r CheckpointFile) writeToFile(data []domain.Checkpoint) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(checkpointLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, item := range data {
+		err := writer.Write([]string{
+			item.Id,
+			item.Date.Format(transactionDateLayout),
+			item.Balance.String(),
+			item.Note,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
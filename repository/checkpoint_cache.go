@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"sync"
+
+	"table-app/domain"
+)
+
+// CheckpointCache
+// сверки с фактическим остатком
+type CheckpointCache struct {
+	items []domain.Checkpoint
+	mutex sync.Mutex
+}
+
+func NewCheckpointCache() *CheckpointCache {
+	return &CheckpointCache{
+		items: make([]domain.Checkpoint, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *CheckpointCache) InitCache(items []domain.Checkpoint) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(make([]domain.Checkpoint, 0, len(items)), items...)
+}
+
+func (r *CheckpointCache) ReadAll() []domain.Checkpoint {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Checkpoint, 0, len(r.items)), r.items...)
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+// csvFormatVersion - текущая версия формата файлов csv; файлы без заголовка считаются версией 1
+const csvFormatVersion = 2
+
+// csvVersionPrefix - последняя ячейка заголовка с версией формата, например format=2
+const csvVersionPrefix = "format="
+
+// csvRejectedLayout - суффикс копии файла, из которого при чтении отброшены строки
+const csvRejectedLayout = ".rejected-20060102-150405"
+
+// csvLayout
+// колонки файла csv: columns - колонки записи по порядку, group - колонки повторяющейся
+// группы в конце строки (привязки цели, части операции); файлы без заголовка читаются
+// по этому же порядку, а legacyRequired - сколько первых колонок в них обязательно,
+// остальные появились позже и в старых файлах их может не быть
+type csvLayout struct {
+	columns        []string
+	group          []string
+	legacyRequired int
+}
+
+// header
+// заголовок файла: имена колонок и версия формата
+func (l csvLayout) header() []string {
+	header := make([]string, 0, len(l.columns)+len(l.group)+1)
+	header = append(header, l.columns...)
+	header = append(header, l.group...)
+
+	return append(header, csvVersionPrefix+strconv.Itoa(csvFormatVersion))
+}
+
+// csvRow
+// строка файла с доступом к значениям по имени колонки
+type csvRow struct {
+	line    int
+	record  []string
+	columns map[string]int
+}
+
+// Value
+// значение колонки; пустое, если колонки нет в файле или строка короче
+func (r csvRow) Value(column string) string {
+	idx, ok := r.columns[column]
+	if !ok || idx >= len(r.record) {
+		return ""
+	}
+
+	return r.record[idx]
+}
+
+// Groups
+// значения повторяющейся группы, по строке на каждую группу
+func (r csvRow) Groups(layout csvLayout) ([][]string, error) {
+	if len(layout.group) == 0 {
+		return nil, nil
+	}
+
+	start, ok := r.columns[layout.group[0]]
+	if !ok || start > len(r.record) {
+		return nil, nil
+	}
+
+	tail := r.record[start:]
+	if len(tail)%len(layout.group) != 0 {
+		return nil, errors.Errorf("group columns count %d is not a multiple of %d", len(tail), len(layout.group))
+	}
+
+	groups := make([][]string, 0, len(tail)/len(layout.group))
+	for i := 0; i < len(tail); i += len(layout.group) {
+		groups = append(groups, tail[i:i+len(layout.group)])
+	}
+
+	return groups, nil
+}
+
+// parseMonth
+// номер месяца из файла; значение вне 1-12 отбрасывает строку, а не создает ячейку
+// несуществующего месяца
+func parseMonth(value string) (time.Month, error) {
+	month, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if month < 1 || month > 12 {
+		return 0, errors.Errorf("month %d is out of range 1-12", month)
+	}
+
+	return time.Month(month), nil
+}
+
+// csvTable
+// прочитанный файл csv: строки, которые прошли проверку числа колонок, и ошибки по строкам
+type csvTable struct {
+	filePath string
+	rows     []csvRow
+	rejected []error
+	outdated bool
+}
+
+// reject
+// строка не разобралась и отбрасывается, ошибка запоминается с номером строки
+func (t *csvTable) reject(row csvRow, err error) {
+	t.rejected = append(t.rejected, errors.WithMessagef(err, "line %d", row.line))
+}
+
+// readCSVFile
+// читает файл с заголовком по именам колонок, а файл без заголовка - по порядку колонок layout;
+// строки с ошибками не прерывают чтение, а попадают в rejected
+func readCSVFile(filePath string, layout csvLayout) (*csvTable, error) {
+	file, err := os.OpenFile(filePath, os.O_RDONLY|os.O_CREATE, 0664)
+	if err != nil {
+		return nil, errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	table := &csvTable{filePath: filePath}
+	var columns map[string]int
+	required := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			// ошибка синтаксиса csv уже содержит номер строки
+			table.rejected = append(table.rejected, err)
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		if columns == nil {
+			version, ok := parseCSVHeader(record)
+			if ok {
+				if version > csvFormatVersion {
+					return nil, errors.Errorf("file format version %d is newer than supported %d",
+						version, csvFormatVersion)
+				}
+
+				columns, required = headerColumns(record, layout)
+				table.outdated = version < csvFormatVersion
+				continue
+			}
+
+			// файл без заголовка - первая версия формата
+			columns, required = legacyColumns(layout)
+			table.outdated = true
+		}
+
+		row := csvRow{line: line, record: record, columns: columns}
+		if len(record) < required {
+			table.reject(row, errors.Errorf("expected at least %d columns, got %d", required, len(record)))
+			continue
+		}
+
+		table.rows = append(table.rows, row)
+	}
+
+	return table, nil
+}
+
+// parseCSVHeader
+// версия формата из последней ячейки заголовка; false, если строка не заголовок
+func parseCSVHeader(record []string) (int, bool) {
+	last := record[len(record)-1]
+	if !strings.HasPrefix(last, csvVersionPrefix) {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(last, csvVersionPrefix))
+	if err != nil {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// headerColumns
+// номера колонок по заголовку; обязательны все колонки записи, которые есть в заголовке
+func headerColumns(header []string, layout csvLayout) (map[string]int, int) {
+	columns := make(map[string]int, len(header))
+	for idx, name := range header[:len(header)-1] {
+		columns[name] = idx
+	}
+
+	required := 0
+	for _, name := range layout.columns {
+		idx, ok := columns[name]
+		if ok && idx+1 > required {
+			required = idx + 1
+		}
+	}
+
+	return columns, required
+}
+
+// legacyColumns
+// номера колонок файла без заголовка: колонки идут в порядке layout
+func legacyColumns(layout csvLayout) (map[string]int, int) {
+	columns := make(map[string]int, len(layout.columns)+len(layout.group))
+	for idx, name := range append(append([]string{}, layout.columns...), layout.group...) {
+		columns[name] = idx
+	}
+
+	required := layout.legacyRequired
+	if required == 0 {
+		required = len(layout.columns)
+	}
+
+	return columns, required
+}
+
+// finish
+// сообщает об отброшенных строках и сохраняет рядом копию исходного файла, чтобы они не
+// потерялись при следующей записи; файл старой версии сразу переписывается в текущем формате
+func (t *csvTable) finish(logger log.Logger, rewrite func() error) error {
+	ctx := context.Background()
+
+	if len(t.rejected) > 0 {
+		for _, err := range t.rejected {
+			logger.Warn(ctx, errors.WithMessagef(err, "skip malformed row in %s", t.filePath))
+		}
+
+		copyPath := t.filePath + time.Now().Format(csvRejectedLayout)
+		err := copyFile(t.filePath, copyPath)
+		if err != nil {
+			return errors.WithMessage(err, "save copy of file with malformed rows")
+		}
+		logger.Warn(ctx, "original file with malformed rows is saved to "+copyPath)
+	}
+
+	if !t.outdated || len(t.rows) == 0 {
+		return nil
+	}
+
+	err := rewrite()
+	if err != nil {
+		return errors.WithMessage(err, "upgrade file format")
+	}
+	logger.Info(ctx, "file is upgraded to format version "+strconv.Itoa(csvFormatVersion)+": "+t.filePath)
+
+	return nil
+}
+
+// writeCSVTemp
+// записывает заголовок layout и строки во временный файл; файл данных заменяется только после Commit
+func writeCSVTemp(filePath string, layout csvLayout, records [][]string) (*atomicFile, error) {
+	return writeAtomicTemp(filePath, func(w io.Writer) error {
+		return writeCSV(w, layout, records)
+	})
+}
+
+func writeCSV(w io.Writer, layout csvLayout, records [][]string) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(layout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, record := range records {
+		err := writer.Write(record)
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return errors.WithMessage(err, "read file")
+	}
+
+	file, err := createAtomicFile(dst)
+	if err != nil {
+		return errors.WithMessage(err, "open copy")
+	}
+	defer file.Close()
+
+	_, err = file.Write(content)
+	if err != nil {
+		return errors.WithMessage(err, "write copy")
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+)
+
+// testLogger
+// запоминает предупреждения, чтобы проверить сообщения об отброшенных строках
+type testLogger struct {
+	warnings []string
+}
+
+func (l *testLogger) Error(_ context.Context, message any, _ ...log.Field) {}
+
+func (l *testLogger) Warn(_ context.Context, message any, _ ...log.Field) {
+	l.warnings = append(l.warnings, fmt.Sprint(message))
+}
+
+func (l *testLogger) Info(_ context.Context, message any, _ ...log.Field) {}
+
+func (l *testLogger) Debug(_ context.Context, message any, _ ...log.Field) {}
+
+func writeTestFile(t *testing.T, content string) string {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "table.csv")
+	err := os.WriteFile(filePath, []byte(content), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
+
+func TestReadCSVFileLegacyUpgrade(t *testing.T) {
+	filePath := writeTestFile(t, "c1,Расходы,Еда,100,3,2024\n"+
+		"c2,Расходы,Кафе,12.5,4,2024,USD,acc\n")
+	repo := TableFile{filePath: filePath, logger: &testLogger{}}
+
+	cells, err := repo.readFromFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []domain.Cell{
+		{Id: "c1", MainCategory: "Расходы", Category: "Еда", Value: 10000, Month: time.March, Year: 2024},
+		{Id: "c2", MainCategory: "Расходы", Category: "Кафе", Value: 1250, Month: time.April, Year: 2024,
+			Currency: "USD", AccountId: "acc"},
+	}
+	assertCells(t, cells, want)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstLine := strings.SplitN(string(content), "\n", 2)[0]
+	if firstLine != strings.Join(cellLayout.header(), ",") {
+		t.Errorf("upgraded file header = %s", firstLine)
+	}
+
+	// после обновления файл читается по заголовку и больше не переписывается
+	table, err := readCSVFile(filePath, cellLayout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.outdated || len(table.rejected) != 0 {
+		t.Errorf("upgraded file: outdated %v, rejected %v", table.outdated, table.rejected)
+	}
+
+	cells, err = repo.readFromFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCells(t, cells, want)
+}
+
+func TestReadCSVFileReorderedColumns(t *testing.T) {
+	content := "year,month,accountId,currency,value,category,mainCategory,id,format=2\n" +
+		"2024,12,acc,EUR,-7.05,Еда,Расходы,c1\n"
+	filePath := writeTestFile(t, content)
+	repo := TableFile{filePath: filePath, logger: &testLogger{}}
+
+	cells, err := repo.readFromFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertCells(t, cells, []domain.Cell{
+		{Id: "c1", MainCategory: "Расходы", Category: "Еда", Value: -705, Month: time.December, Year: 2024,
+			Currency: "EUR", AccountId: "acc"},
+	})
+
+	// файл текущей версии не переписывается
+	after, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != content {
+		t.Errorf("file of current format is rewritten:\n%s", after)
+	}
+}
+
+func TestReadCSVFileNewerFormat(t *testing.T) {
+	filePath := writeTestFile(t, "id,mainCategory,format=3\nc1,Расходы\n")
+
+	_, err := readCSVFile(filePath, cellLayout)
+	if err == nil {
+		t.Fatal("want error for newer format version")
+	}
+}
+
+func TestReadCSVFileRejectsMalformedRows(t *testing.T) {
+	content := strings.Join(cellLayout.header(), ",") + "\n" +
+		"c1,Расходы,Еда,100,3,2024,,\n" +
+		"c2,Расходы,Еда,100,13,2024,,\n" +
+		"c3,Расходы\n" +
+		"c4,Расходы,Еда,abc,5,2024,,\n" +
+		"c5,Расходы,Еда,200,0,2024,,\n"
+	filePath := writeTestFile(t, content)
+	logger := &testLogger{}
+	repo := TableFile{filePath: filePath, logger: logger}
+
+	cells, err := repo.readFromFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCells(t, cells, []domain.Cell{
+		{Id: "c1", MainCategory: "Расходы", Category: "Еда", Value: 10000, Month: time.March, Year: 2024},
+	})
+
+	for _, line := range []string{"line 3", "line 4", "line 5", "line 6"} {
+		found := false
+		for _, warning := range logger.warnings {
+			if strings.Contains(warning, line+":") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no warning for %s in %v", line, logger.warnings)
+		}
+	}
+
+	copies, err := filepath.Glob(filePath + ".rejected-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copies) != 1 {
+		t.Fatalf("rejected copies = %v, want one", copies)
+	}
+
+	saved, err := os.ReadFile(copies[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved) != content {
+		t.Errorf("rejected copy differs from original:\n%s", saved)
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Month
+		wantErr bool
+	}{
+		{value: "1", want: time.January},
+		{value: "12", want: time.December},
+		{value: "0", wantErr: true},
+		{value: "13", wantErr: true},
+		{value: "-1", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "март", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMonth(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseMonth(%q) = %d, want error", tt.value, got)
+			}
+			continue
+		}
+
+		if err != nil || got != tt.want {
+			t.Errorf("parseMonth(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func assertCells(t *testing.T, got, want []domain.Cell) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("cells = %+v, want %+v", got, want)
+	}
+
+	for i := range want {
+		if got[i].Id != want[i].Id || got[i].MainCategory != want[i].MainCategory ||
+			got[i].Category != want[i].Category || got[i].Value != want[i].Value ||
+			got[i].Month != want[i].Month || got[i].Year != want[i].Year ||
+			got[i].Currency != want[i].Currency || got[i].AccountId != want[i].AccountId {
+			t.Errorf("cell %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
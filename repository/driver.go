@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"table-app/conf"
+	"table-app/domain"
+
+	"github.com/pkg/errors"
+)
+
+// ListRepository
+// данные одного вида, которые сохраняются списком целиком
+type ListRepository[T any] interface {
+	ReplaceAll(ctx context.Context, items []T) error
+	GetAll(ctx context.Context) ([]T, error)
+}
+
+// CellRepository
+// ячейки вместе с операциями
+type CellRepository interface {
+	// SaveAll - cells: все ячейки кеша с операциями, deleted - удаленные ячейки;
+	// хранилище само решает, переписать данные целиком или сохранить только изменения
+	SaveAll(ctx context.Context, cells, deleted []domain.Cell) error
+	GetAll(ctx context.Context) ([]domain.Cell, []domain.Transaction, error)
+}
+
+type CategoryRepository interface {
+	UpsertAll(ctx context.Context, categories []domain.Category) error
+	DeleteAll(ctx context.Context, categories []domain.Category) error
+	GetAll(ctx context.Context) ([]domain.Category, error)
+}
+
+type CategoryMergeRepository interface {
+	Save(ctx context.Context, data MergeData) error
+}
+
+type AttachmentRepository interface {
+	ReplaceAll(ctx context.Context, items []domain.Attachment) error
+	WriteContent(ctx context.Context, item domain.Attachment, content []byte) error
+	ReadContent(ctx context.Context, item domain.Attachment) ([]byte, error)
+	LocalPath(ctx context.Context, item domain.Attachment) (string, error)
+	GetAll(ctx context.Context) ([]domain.Attachment, error)
+}
+
+// Driver
+// хранилище данных: дает репозитории всех видов данных, поэтому сервисы не знают,
+// где лежат данные, а новое хранилище добавляется новым драйвером
+type Driver interface {
+	Cells() CellRepository
+	Category() CategoryRepository
+	CategoryMerge() CategoryMergeRepository
+	Rate() ListRepository[domain.ExchangeRate]
+	Account() ListRepository[domain.Account]
+	Transfer() ListRepository[domain.Transfer]
+	Plan() ListRepository[domain.Plan]
+	Recurring() ListRepository[domain.Recurring]
+	Checkpoint() ListRepository[domain.Checkpoint]
+	Goal() ListRepository[domain.Goal]
+	Loan() ListRepository[domain.Loan]
+	Valuation() ListRepository[domain.Valuation]
+	Split() ListRepository[domain.Split]
+	Payee() ListRepository[domain.Payee]
+	Attachment() AttachmentRepository
+	// DataFiles - файлы данных для резервной копии; пусто, если данные хранятся не в файлах
+	DataFiles() []string
+	Close() error
+}
+
+// DriverFactory
+// открывает хранилище по его настройкам из конфига
+type DriverFactory func(ctx context.Context, storage conf.Storage) (Driver, error)
+
+// DriverRegistry
+// драйверы хранилищ по ключу storage в конфиге
+type DriverRegistry struct {
+	factories map[string]DriverFactory
+	opened    []Driver
+}
+
+func NewDriverRegistry() *DriverRegistry {
+	return &DriverRegistry{
+		factories: make(map[string]DriverFactory),
+	}
+}
+
+func (r *DriverRegistry) Register(key string, factory DriverFactory) {
+	r.factories[key] = factory
+}
+
+// Open
+// открывает драйвер хранилища, заданного в конфиге
+func (r *DriverRegistry) Open(ctx context.Context, storage conf.Storage) (Driver, error) {
+	key, err := storage.Key()
+	if err != nil {
+		return nil, errors.WithMessage(err, "storage key")
+	}
+
+	factory, ok := r.factories[key]
+	if !ok {
+		return nil, errors.Errorf("storage driver %s is not registered", key)
+	}
+
+	driver, err := factory(ctx, storage)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "open storage driver %s", key)
+	}
+
+	r.opened = append(r.opened, driver)
+	return driver, nil
+}
+
+// Close
+// закрывает открытые драйверы
+func (r *DriverRegistry) Close() error {
+	var result error
+	for _, driver := range r.opened {
+		err := driver.Close()
+		if err != nil && result == nil {
+			result = errors.WithMessage(err, "close storage driver")
+		}
+	}
+	r.opened = nil
+
+	return result
+}
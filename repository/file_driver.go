@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+
+	"table-app/conf"
+	"table-app/domain"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+// FileDriver
+// хранилище в файлах csv, которые переписываются целиком при каждом сохранении;
+// пока драйвер открыт, каталоги с данными заблокированы от других экземпляров приложения
+type FileDriver struct {
+	files  conf.Files
+	lock   *storageLock
+	logger log.Logger
+}
+
+// OpenFileDriver
+// блокирует все каталоги с файлами данных и каталог вложений; если хотя бы один из них
+// уже занял другой экземпляр, возвращает ErrStorageLocked. Файлы, не заданные в конфиге,
+// берутся по умолчанию рядом с файлом таблицы
+func OpenFileDriver(files conf.Files, logger log.Logger) (FileDriver, error) {
+	files = files.WithDefaults()
+
+	dirs := make([]string, 0)
+	for _, path := range files.DataFiles() {
+		dirs = append(dirs, filepath.Dir(path))
+	}
+
+	// каталог вложений может еще не существовать: он создается при первом вложении
+	attachments := attachmentDir(files)
+	err := os.MkdirAll(attachments, 0775)
+	if err != nil {
+		return FileDriver{}, errors.WithMessage(err, "create attachment dir")
+	}
+	dirs = append(dirs, attachments)
+
+	lock, err := acquireStorageLock(dirs...)
+	if err != nil {
+		return FileDriver{}, errors.WithMessage(err, "acquire storage lock")
+	}
+
+	return FileDriver{
+		files:  files,
+		lock:   lock,
+		logger: logger,
+	}, nil
+}
+
+func (d FileDriver) Cells() CellRepository {
+	return NewCellsFile(d.files.TableFilePath, d.files.TransactionFilePath, d.logger)
+}
+
+func (d FileDriver) Category() CategoryRepository {
+	return NewCategoryFile(d.files.CategoryFilePath, d.logger)
+}
+
+func (d FileDriver) CategoryMerge() CategoryMergeRepository {
+	return NewCategoryMergeFile(d.files, d.logger)
+}
+
+func (d FileDriver) Rate() ListRepository[domain.ExchangeRate] {
+	return NewRateFile(d.files.RateFilePath, d.logger)
+}
+
+func (d FileDriver) Account() ListRepository[domain.Account] {
+	return NewAccountFile(d.files.AccountFilePath, d.logger)
+}
+
+func (d FileDriver) Transfer() ListRepository[domain.Transfer] {
+	return NewTransferFile(d.files.TransferFilePath, d.logger)
+}
+
+func (d FileDriver) Plan() ListRepository[domain.Plan] {
+	return NewPlanFile(d.files.PlanFilePath, d.logger)
+}
+
+func (d FileDriver) Recurring() ListRepository[domain.Recurring] {
+	return NewRecurringFile(d.files.RecurringFilePath, d.logger)
+}
+
+func (d FileDriver) Checkpoint() ListRepository[domain.Checkpoint] {
+	return NewCheckpointFile(d.files.CheckpointFilePath, d.logger)
+}
+
+func (d FileDriver) Goal() ListRepository[domain.Goal] {
+	return NewGoalFile(d.files.GoalFilePath, d.logger)
+}
+
+func (d FileDriver) Loan() ListRepository[domain.Loan] {
+	return NewLoanFile(d.files.LoanFilePath, d.logger)
+}
+
+func (d FileDriver) Valuation() ListRepository[domain.Valuation] {
+	return NewValuationFile(d.files.ValuationFilePath, d.logger)
+}
+
+func (d FileDriver) Split() ListRepository[domain.Split] {
+	return NewSplitFile(d.files.SplitFilePath, d.logger)
+}
+
+func (d FileDriver) Payee() ListRepository[domain.Payee] {
+	return NewPayeeFile(d.files.PayeeFilePath, d.logger)
+}
+
+func (d FileDriver) Attachment() AttachmentRepository {
+	return NewAttachmentFile(d.files, d.logger)
+}
+
+func (d FileDriver) DataFiles() []string {
+	return d.files.DataFiles()
+}
+
+func (d FileDriver) Close() error {
+	return d.lock.Release()
+}
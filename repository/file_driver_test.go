@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"table-app/conf"
+)
+
+func TestOpenFileDriverOldStyleConfig(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	driver, err := OpenFileDriver(conf.Files{
+		TableFilePath:    filepath.Join(dir, "tableData.csv"),
+		CategoryFilePath: filepath.Join(dir, "categoryData.csv"),
+	}, &testLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close()
+
+	_, _, err = driver.Cells().GetAll(ctx)
+	if err != nil {
+		t.Fatalf("read cells: %v", err)
+	}
+
+	_, err = driver.Payee().GetAll(ctx)
+	if err != nil {
+		t.Fatalf("read payees: %v", err)
+	}
+
+	_, err = os.Stat(filepath.Join(dir, "transactionData.csv"))
+	if err != nil {
+		t.Errorf("transaction file next to table: %v", err)
+	}
+
+	if len(driver.DataFiles()) != 15 {
+		t.Errorf("data files for backup = %v", driver.DataFiles())
+	}
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+const lockFileName = ".table-app.lock"
+
+// ErrStorageLocked
+// файлы данных уже открыты другим запущенным экземпляром приложения
+var ErrStorageLocked = errors.New("storage is used by another running instance of the application")
+
+// storageLock
+// рекомендательная блокировка файлов в каталогах с данными: второй экземпляр приложения
+// не открывает те же файлы, пока первый не завершится; система снимает блокировку
+// и при аварийном завершении процесса
+type storageLock struct {
+	files []*os.File
+}
+
+// acquireStorageLock
+// блокирует каждый каталог; файлы данных и вложения могут лежать в разных каталогах,
+// и экземпляр с другим конфигом, который делит с этим хотя бы один каталог, не запустится
+func acquireStorageLock(dirs ...string) (*storageLock, error) {
+	lock := &storageLock{}
+	for _, dir := range uniqueDirs(dirs) {
+		file, err := lockDir(dir)
+		if err != nil {
+			lock.Release()
+			return nil, err
+		}
+		lock.files = append(lock.files, file)
+	}
+
+	return lock, nil
+}
+
+func lockDir(dir string) (*os.File, error) {
+	file, err := os.OpenFile(filepath.Join(dir, lockFileName), os.O_RDWR|os.O_CREATE, 0664)
+	if err != nil {
+		return nil, errors.WithMessage(err, "open lock file")
+	}
+
+	err = lockFile(file)
+	if err != nil {
+		file.Close()
+		if errors.Is(err, ErrStorageLocked) {
+			return nil, errors.WithMessage(ErrStorageLocked, dir)
+		}
+		return nil, errors.WithMessage(err, "lock file")
+	}
+
+	return file, nil
+}
+
+// uniqueDirs
+// каталоги без повторов в постоянном порядке
+func uniqueDirs(dirs []string) []string {
+	result := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		result = append(result, filepath.Clean(dir))
+	}
+	slices.Sort(result)
+
+	return slices.Compact(result)
+}
+
+func (l *storageLock) Release() error {
+	var result error
+	for _, file := range l.files {
+		err := unlockFile(file)
+		if err != nil && result == nil {
+			result = errors.WithMessage(err, "unlock file")
+		}
+
+		err = file.Close()
+		if err != nil && result == nil {
+			result = errors.WithMessage(err, "close lock file")
+		}
+	}
+	l.files = nil
+
+	return result
+}
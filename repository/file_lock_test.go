@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"table-app/conf"
+
+	"github.com/pkg/errors"
+)
+
+func TestStorageLock(t *testing.T) {
+	dataDir := t.TempDir()
+	attachmentDir := t.TempDir()
+
+	lock, err := acquireStorageLock(dataDir, dataDir, attachmentDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dirs []string
+	}{
+		{name: "same dirs", dirs: []string{dataDir, attachmentDir}},
+		{name: "only data dir", dirs: []string{dataDir}},
+		{name: "only attachment dir", dirs: []string{t.TempDir(), attachmentDir}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			second, err := acquireStorageLock(tt.dirs...)
+			if !errors.Is(err, ErrStorageLocked) {
+				if second != nil {
+					second.Release()
+				}
+				t.Fatalf("second lock error = %v, want ErrStorageLocked", err)
+			}
+		})
+	}
+
+	err = lock.Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// после освобождения каталоги снова можно заблокировать
+	lock, err = acquireStorageLock(dataDir, attachmentDir)
+	if err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+	lock.Release()
+}
+
+func TestOpenFileDriverLocksAttachmentDir(t *testing.T) {
+	attachments := filepath.Join(t.TempDir(), "shared")
+	files := conf.Files{
+		TableFilePath: filepath.Join(t.TempDir(), "table.csv"),
+		AttachmentDir: attachments,
+	}
+
+	driver, err := OpenFileDriver(files, &testLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer driver.Close()
+
+	// другой каталог данных, но общий каталог вложений
+	other := conf.Files{
+		TableFilePath: filepath.Join(t.TempDir(), "table.csv"),
+		AttachmentDir: attachments,
+	}
+	_, err = OpenFileDriver(other, &testLogger{})
+	if !errors.Is(err, ErrStorageLocked) {
+		t.Fatalf("second driver error = %v, want ErrStorageLocked", err)
+	}
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+// goalLayout - колонки файла целей, после них идут привязки цели
+var goalLayout = csvLayout{
+	columns: []string{"id", "name", "target", "deadline"},
+	group:   []string{"linkKind", "linkMainCategory", "linkCategory", "linkAccountId"},
+}
+
+type Goal struct {
+	db db.DB
+}
+
+func NewGoal(db db.DB) Goal {
+	return Goal{
+		db: db,
+	}
+}
+
+type GoalFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewGoalFile(filePath string, logger log.Logger) GoalFile {
+	return GoalFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// ReplaceAll
+// целей немного, поэтому список сохраняется целиком; привязки удаляются каскадно вместе с целями
+func (r Goal) ReplaceAll(ctx context.Context, goals []domain.Goal) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace goals transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.goal;`)
+	if err == nil {
+		for _, goal := range goals {
+			err = insertGoal(ctx, tx.Exec, goal)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace goals transaction")
+		}
+
+		return errors.WithMessage(err, "replace goals transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace goals transaction")
+	}
+
+	return nil
+}
+
+func insertGoal(ctx context.Context, txExec TxFuncExec, goal domain.Goal) error {
+	q := `
+	INSERT INTO table_app.goal
+    	(id, name, target, deadline)
+	VALUES
+    	($1, $2, $3, $4);`
+
+	_, err := txExec(ctx, q, goal.Id, goal.Name, int64(goal.Target), goal.Deadline)
+	if err != nil {
+		return errors.WithMessage(err, "insert goal")
+	}
+
+	q = `
+	INSERT INTO table_app.goal_link
+    	(goal_id, position, kind, main_category, category, account_id)
+	VALUES
+    	($1, $2, $3, $4, $5, $6);`
+
+	for i, link := range goal.Links {
+		_, err = txExec(ctx, q, goal.Id, i, link.Kind, link.MainCategory, link.Category, link.AccountId)
+		if err != nil {
+			return errors.WithMessage(err, "insert goal link")
+		}
+	}
+
+	return nil
+}
+
+func (r Goal) GetAll(ctx context.Context) ([]domain.Goal, error) {
+	q := `
+	SELECT id, name, target, deadline
+	FROM table_app.goal
+	ORDER BY deadline, name;`
+
+	var goals []domain.Goal
+	indexById := make(map[string]int)
+
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get goals")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var goal domain.Goal
+		var target int64
+		err = rows.Scan(&goal.Id, &goal.Name, &target, &goal.Deadline)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		goal.Target = entity.Money(target)
+		indexById[goal.Id] = len(goals)
+		goals = append(goals, goal)
+	}
+	rows.Close()
+
+	q = `
+	SELECT goal_id, kind, main_category, category, account_id
+	FROM table_app.goal_link
+	ORDER BY goal_id, position;`
+
+	linkRows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get goal links")
+	}
+
+	defer linkRows.Close()
+	for linkRows.Next() {
+		var goalId string
+		var link domain.GoalLink
+		err = linkRows.Scan(&goalId, &link.Kind, &link.MainCategory, &link.Category, &link.AccountId)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+
+		i, ok := indexById[goalId]
+		if ok {
+			goals[i].Links = append(goals[i].Links, link)
+		}
+	}
+
+	return goals, nil
+}
+
+func (r GoalFile) ReplaceAll(ctx context.Context, goals []domain.Goal) error {
+	return r.writeToFile(goals)
+}
+
+func (r GoalFile) GetAll(ctx context.Context) ([]domain.Goal, error) {
+	return r.readFromFile()
+}
+
+// readFromFile
+// после колонок цели идут привязки, по колонкам группы goalLayout на каждую
+func (r GoalFile) readFromFile() ([]domain.Goal, error) {
+	table, err := readCSVFile(r.filePath, goalLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Goal, 0, len(table.rows))
+	for _, row := range table.rows {
+		goal, err := parseGoalRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, goal)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseGoalRow(row csvRow) (domain.Goal, error) {
+	var err error
+	goal := domain.Goal{}
+	goal.Id = row.Value("id")
+	goal.Name = row.Value("name")
+
+	goal.Target, err = entity.ParseMoney(row.Value("target"))
+	if err != nil {
+		return goal, errors.WithMessage(err, "convert goal target")
+	}
+
+	goal.Deadline, err = time.Parse(transactionDateLayout, row.Value("deadline"))
+	if err != nil {
+		return goal, errors.WithMessage(err, "convert goal deadline")
+	}
+
+	links, err := row.Groups(goalLayout)
+	if err != nil {
+		return goal, errors.WithMessage(err, "read goal links")
+	}
+
+	for _, link := range links {
+		goal.Links = append(goal.Links, domain.GoalLink{
+			Kind:         link[0],
+			MainCategory: link[1],
+			Category:     link[2],
+			AccountId:    link[3],
+		})
+	}
+
+	return goal, nil
+}
+
+func (r GoalFile) writeToFile(data []domain.Goal) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(goalLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, goal := range data {
+		record := []string{
+			goal.Id,
+			goal.Name,
+			goal.Target.String(),
+			goal.Deadline.Format(transactionDateLayout),
+		}
+
+		for _, link := range goal.Links {
+			record = append(record, link.Kind, link.MainCategory, link.Category, link.AccountId)
+		}
+
+		err := writer.Write(record)
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
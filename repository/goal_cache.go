@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"slices"
+	"sync"
+
+	"table-app/domain"
+)
+
+// GoalCache
+// цели накопления
+type GoalCache struct {
+	items []domain.Goal
+	mutex sync.Mutex
+}
+
+func NewGoalCache() *GoalCache {
+	return &GoalCache{
+		items: make([]domain.Goal, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *GoalCache) InitCache(items []domain.Goal) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(make([]domain.Goal, 0, len(items)), items...)
+}
+
+func (r *GoalCache) ReadAll() []domain.Goal {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Goal, 0, len(r.items)), r.items...)
+}
+
+// UpdateCategoryName
+// переносит привязки целей на новое название категории; повторная привязка к той же категории,
+// например после слияния, не добавляется
+func (r *GoalCache) UpdateCategoryName(oldCategory, newCategory domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		links := make([]domain.GoalLink, 0, len(r.items[i].Links))
+		for _, link := range r.items[i].Links {
+			if link.IsLinkedTo(oldCategory) {
+				link.MainCategory = newCategory.MainCategory
+				link.Category = newCategory.Name
+			}
+
+			if !slices.Contains(links, link) {
+				links = append(links, link)
+			}
+		}
+
+		r.items[i].Links = links
+	}
+}
+
+// DeleteCategory
+// убирает привязки целей к удаленной категории
+func (r *GoalCache) DeleteCategory(category domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		links := make([]domain.GoalLink, 0, len(r.items[i].Links))
+		for _, link := range r.items[i].Links {
+			if !link.IsLinkedTo(category) {
+				links = append(links, link)
+			}
+		}
+
+		r.items[i].Links = links
+	}
+}
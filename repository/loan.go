@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Loan struct {
+	db db.DB
+}
+
+func NewLoan(db db.DB) Loan {
+	return Loan{
+		db: db,
+	}
+}
+
+type LoanFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewLoanFile(filePath string, logger log.Logger) LoanFile {
+	return LoanFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// loanLayout - колонки файла кредитов
+var loanLayout = csvLayout{
+	columns: []string{"id", "name", "principal", "rate", "termMonths", "type", "startDate", "accountId",
+		"interestMainCategory", "interestCategory", "principalMainCategory", "principalCategory", "lastDate"},
+}
+
+// ReplaceAll
+// список кредитов небольшой, поэтому сохраняется целиком
+func (r Loan) ReplaceAll(ctx context.Context, loans []domain.Loan) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace loans transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.loan;`)
+	if err == nil {
+		for _, loan := range loans {
+			err = insertLoan(ctx, tx.Exec, loan)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace loans transaction")
+		}
+
+		return errors.WithMessage(err, "replace loans transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace loans transaction")
+	}
+
+	return nil
+}
+
+func insertLoan(ctx context.Context, txExec TxFuncExec, loan domain.Loan) error {
+	q := `
+	INSERT INTO table_app.loan
+    	(id, name, principal, rate, term_months, type, start_date, account_id,
+    	 interest_main_category, interest_category, principal_main_category, principal_category, last_date)
+	VALUES
+    	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);`
+
+	_, err := txExec(ctx, q, loan.Id, loan.Name, int64(loan.Principal), loan.Rate, loan.TermMonths, loan.Type,
+		loan.StartDate, loan.AccountId, loan.InterestMainCategory, loan.InterestCategory,
+		loan.PrincipalMainCategory, loan.PrincipalCategory, nullableDate(loan.LastDate))
+	if err != nil {
+		return errors.WithMessage(err, "insert loan")
+	}
+
+	return nil
+}
+
+func (r Loan) GetAll(ctx context.Context) ([]domain.Loan, error) {
+	q := `
+	SELECT id, name, principal, rate, term_months, type, start_date, account_id,
+	       interest_main_category, interest_category, principal_main_category, principal_category, last_date
+	FROM table_app.loan;`
+
+	var loans []domain.Loan
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get loans")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var loan domain.Loan
+		var principal int64
+		var lastDate *time.Time
+		err = rows.Scan(&loan.Id, &loan.Name, &principal, &loan.Rate, &loan.TermMonths, &loan.Type,
+			&loan.StartDate, &loan.AccountId, &loan.InterestMainCategory, &loan.InterestCategory,
+			&loan.PrincipalMainCategory, &loan.PrincipalCategory, &lastDate)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		loan.Principal = entity.Money(principal)
+		if lastDate != nil {
+			loan.LastDate = *lastDate
+		}
+		loans = append(loans, loan)
+	}
+
+	return loans, nil
+}
+
+func (r LoanFile) ReplaceAll(ctx context.Context, loans []domain.Loan) error {
+	return r.writeToFile(loans)
+}
+
+func (r LoanFile) GetAll(ctx context.Context) ([]domain.Loan, error) {
+	return r.readFromFile()
+}
+
+func (r LoanFile) readFromFile() ([]domain.Loan, error) {
+	table, err := readCSVFile(r.filePath, loanLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Loan, 0, len(table.rows))
+	for _, row := range table.rows {
+		loan, err := parseLoanRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, loan)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseLoanRow(row csvRow) (domain.Loan, error) {
+	var err error
+	loan := domain.Loan{}
+	loan.Id = row.Value("id")
+	loan.Name = row.Value("name")
+
+	loan.Principal, err = entity.ParseMoney(row.Value("principal"))
+	if err != nil {
+		return loan, errors.WithMessage(err, "convert loan principal")
+	}
+
+	rate, err := entity.ParseMoney(row.Value("rate"))
+	if err != nil {
+		return loan, errors.WithMessage(err, "convert loan rate")
+	}
+	loan.Rate = int(rate)
+
+	loan.TermMonths, err = strconv.Atoi(row.Value("termMonths"))
+	if err != nil {
+		return loan, errors.WithMessage(err, "convert loan term")
+	}
+
+	loan.Type = row.Value("type")
+
+	loan.StartDate, err = parseOptionalDate(row.Value("startDate"))
+	if err != nil {
+		return loan, errors.WithMessage(err, "convert loan start date")
+	}
+
+	loan.AccountId = row.Value("accountId")
+	loan.InterestMainCategory = row.Value("interestMainCategory")
+	loan.InterestCategory = row.Value("interestCategory")
+	loan.PrincipalMainCategory = row.Value("principalMainCategory")
+	loan.PrincipalCategory = row.Value("principalCategory")
+
+	loan.LastDate, err = parseOptionalDate(row.Value("lastDate"))
+	if err != nil {
+		return loan, errors.WithMessage(err, "convert loan last date")
+	}
+
+	return loan, nil
+}
+
+func (r LoanFile) writeToFile(data []domain.Loan) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(loanLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, loan := range data {
+		// ставка в сотых долях процента записывается как проценты: 12.50
+		err := writer.Write([]string{
+			loan.Id,
+			loan.Name,
+			loan.Principal.String(),
+			entity.Money(loan.Rate).String(),
+			strconv.Itoa(loan.TermMonths),
+			loan.Type,
+			formatOptionalDate(loan.StartDate),
+			loan.AccountId,
+			loan.InterestMainCategory,
+			loan.InterestCategory,
+			loan.PrincipalMainCategory,
+			loan.PrincipalCategory,
+			formatOptionalDate(loan.LastDate),
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
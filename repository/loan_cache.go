@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"table-app/domain"
+)
+
+// LoanCache
+// кредиты
+type LoanCache struct {
+	loans []domain.Loan
+	mutex sync.Mutex
+}
+
+func NewLoanCache() *LoanCache {
+	return &LoanCache{
+		loans: make([]domain.Loan, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *LoanCache) InitCache(loans []domain.Loan) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.loans = append(make([]domain.Loan, 0, len(loans)), loans...)
+}
+
+func (r *LoanCache) ReadAll() []domain.Loan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Loan, 0, len(r.loans)), r.loans...)
+}
+
+// SetLastDate
+// запоминает дату последнего проведенного платежа
+func (r *LoanCache) SetLastDate(id string, date time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.loans {
+		if r.loans[i].Id == id {
+			r.loans[i].LastDate = date
+			return
+		}
+	}
+}
+
+// UpdateCategoryName
+// переносит категории процентов и погашения долга на новое название
+func (r *LoanCache) UpdateCategoryName(oldCategory, newCategory domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.loans {
+		loan := &r.loans[i]
+		if loan.InterestMainCategory == oldCategory.MainCategory && loan.InterestCategory == oldCategory.Name {
+			loan.InterestMainCategory = newCategory.MainCategory
+			loan.InterestCategory = newCategory.Name
+		}
+
+		if loan.PrincipalMainCategory == oldCategory.MainCategory && loan.PrincipalCategory == oldCategory.Name {
+			loan.PrincipalMainCategory = newCategory.MainCategory
+			loan.PrincipalCategory = newCategory.Name
+		}
+	}
+}
+
+// DeleteCategory
+// удаляет кредиты, платежи которых проводятся в категорию, как и регулярные платежи
+func (r *LoanCache) DeleteCategory(category domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	loans := make([]domain.Loan, 0, len(r.loans))
+	for _, loan := range r.loans {
+		if !loan.IsLinkedTo(category) {
+			loans = append(loans, loan)
+		}
+	}
+
+	r.loans = loans
+}
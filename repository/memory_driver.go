@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"table-app/domain"
+
+	"github.com/pkg/errors"
+)
+
+// MemoryDriver
+// хранилище в памяти процесса: данные живут, пока открыт драйвер; нужно для тестов
+// и запуска без файлов и бд
+type MemoryDriver struct {
+	cells      *memoryCells
+	category   *memoryCategory
+	rate       *memoryList[domain.ExchangeRate]
+	account    *memoryList[domain.Account]
+	transfer   *memoryList[domain.Transfer]
+	plan       *memoryList[domain.Plan]
+	recurring  *memoryList[domain.Recurring]
+	checkpoint *memoryList[domain.Checkpoint]
+	goal       *memoryList[domain.Goal]
+	loan       *memoryList[domain.Loan]
+	valuation  *memoryList[domain.Valuation]
+	split      *memoryList[domain.Split]
+	payee      *memoryList[domain.Payee]
+	attachment *memoryAttachment
+}
+
+func NewMemoryDriver() MemoryDriver {
+	return MemoryDriver{
+		cells:      &memoryCells{},
+		category:   &memoryCategory{},
+		rate:       &memoryList[domain.ExchangeRate]{},
+		account:    &memoryList[domain.Account]{},
+		transfer:   &memoryList[domain.Transfer]{},
+		plan:       &memoryList[domain.Plan]{},
+		recurring:  &memoryList[domain.Recurring]{},
+		checkpoint: &memoryList[domain.Checkpoint]{},
+		goal:       &memoryList[domain.Goal]{},
+		loan:       &memoryList[domain.Loan]{},
+		valuation:  &memoryList[domain.Valuation]{},
+		split:      &memoryList[domain.Split]{},
+		payee:      &memoryList[domain.Payee]{},
+		attachment: &memoryAttachment{content: make(map[string][]byte)},
+	}
+}
+
+func (d MemoryDriver) Cells() CellRepository {
+	return d.cells
+}
+
+func (d MemoryDriver) Category() CategoryRepository {
+	return d.category
+}
+
+func (d MemoryDriver) CategoryMerge() CategoryMergeRepository {
+	return memoryMerge{cells: d.cells, category: d.category}
+}
+
+func (d MemoryDriver) Rate() ListRepository[domain.ExchangeRate] {
+	return d.rate
+}
+
+func (d MemoryDriver) Account() ListRepository[domain.Account] {
+	return d.account
+}
+
+func (d MemoryDriver) Transfer() ListRepository[domain.Transfer] {
+	return d.transfer
+}
+
+func (d MemoryDriver) Plan() ListRepository[domain.Plan] {
+	return d.plan
+}
+
+func (d MemoryDriver) Recurring() ListRepository[domain.Recurring] {
+	return d.recurring
+}
+
+func (d MemoryDriver) Checkpoint() ListRepository[domain.Checkpoint] {
+	return d.checkpoint
+}
+
+func (d MemoryDriver) Goal() ListRepository[domain.Goal] {
+	return d.goal
+}
+
+func (d MemoryDriver) Loan() ListRepository[domain.Loan] {
+	return d.loan
+}
+
+func (d MemoryDriver) Valuation() ListRepository[domain.Valuation] {
+	return d.valuation
+}
+
+func (d MemoryDriver) Split() ListRepository[domain.Split] {
+	return d.split
+}
+
+func (d MemoryDriver) Payee() ListRepository[domain.Payee] {
+	return d.payee
+}
+
+func (d MemoryDriver) Attachment() AttachmentRepository {
+	return d.attachment
+}
+
+func (d MemoryDriver) DataFiles() []string {
+	return nil
+}
+
+func (d MemoryDriver) Close() error {
+	return nil
+}
+
+type memoryList[T any] struct {
+	mu    sync.Mutex
+	items []T
+}
+
+func (r *memoryList[T]) ReplaceAll(ctx context.Context, items []T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = slices.Clone(items)
+	return nil
+}
+
+func (r *memoryList[T]) GetAll(ctx context.Context) ([]T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.items), nil
+}
+
+// memoryCells
+// ячейки и операции хранятся так же, как в файлах: без признаков изменения и без удаленных
+type memoryCells struct {
+	mu           sync.Mutex
+	cells        []domain.Cell
+	transactions []domain.Transaction
+}
+
+func (r *memoryCells) SaveAll(ctx context.Context, cells, deleted []domain.Cell) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.replace(cells)
+	return nil
+}
+
+func (r *memoryCells) replace(cells []domain.Cell) {
+	r.cells = make([]domain.Cell, 0, len(cells))
+	r.transactions = make([]domain.Transaction, 0)
+	for _, cell := range cells {
+		if cell.IsDeleted {
+			continue
+		}
+
+		for _, transaction := range cell.Transactions {
+			if transaction.IsDeleted {
+				continue
+			}
+
+			transaction.IsUpdated = false
+			r.transactions = append(r.transactions, transaction)
+		}
+
+		cell.IsUpdated = false
+		cell.Transactions = nil
+		r.cells = append(r.cells, cell)
+	}
+}
+
+func (r *memoryCells) GetAll(ctx context.Context) ([]domain.Cell, []domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.cells), slices.Clone(r.transactions), nil
+}
+
+type memoryCategory struct {
+	mu    sync.Mutex
+	items []domain.Category
+}
+
+func (r *memoryCategory) UpsertAll(ctx context.Context, categories []domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, category := range categories {
+		idx := slices.IndexFunc(r.items, func(item domain.Category) bool {
+			return item.Id == category.Id
+		})
+		if idx < 0 {
+			r.items = append(r.items, category)
+			continue
+		}
+
+		r.items[idx] = category
+	}
+
+	return nil
+}
+
+func (r *memoryCategory) DeleteAll(ctx context.Context, categories []domain.Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, category := range categories {
+		r.items = slices.DeleteFunc(r.items, func(item domain.Category) bool {
+			return item.Id == category.Id
+		})
+	}
+
+	return nil
+}
+
+func (r *memoryCategory) GetAll(ctx context.Context) ([]domain.Category, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.items), nil
+}
+
+// memoryMerge
+// после слияния категории и ячейки заменяются целиком
+type memoryMerge struct {
+	cells    *memoryCells
+	category *memoryCategory
+}
+
+func (r memoryMerge) Save(ctx context.Context, data MergeData) error {
+	r.category.mu.Lock()
+	r.category.items = slices.Clone(data.Categories)
+	r.category.mu.Unlock()
+
+	r.cells.mu.Lock()
+	r.cells.replace(data.AllCells)
+	r.cells.mu.Unlock()
+
+	return nil
+}
+
+type memoryAttachment struct {
+	mu      sync.Mutex
+	items   []domain.Attachment
+	content map[string][]byte
+}
+
+// ReplaceAll
+// содержимое вложений, которых нет в списке, удаляется
+func (r *memoryAttachment) ReplaceAll(ctx context.Context, items []domain.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = slices.Clone(items)
+
+	keep := make(map[string]bool, len(items))
+	for _, item := range items {
+		keep[item.Id] = true
+	}
+
+	for id := range r.content {
+		if !keep[id] {
+			delete(r.content, id)
+		}
+	}
+
+	return nil
+}
+
+func (r *memoryAttachment) WriteContent(ctx context.Context, item domain.Attachment, content []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.content[item.Id] = slices.Clone(content)
+	return nil
+}
+
+func (r *memoryAttachment) ReadContent(ctx context.Context, item domain.Attachment) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	content, ok := r.content[item.Id]
+	if !ok {
+		return nil, errors.Errorf("attachment %s content not found", item.Id)
+	}
+
+	return slices.Clone(content), nil
+}
+
+// LocalPath
+// вложение выгружается во временный каталог, как из бд
+func (r *memoryAttachment) LocalPath(ctx context.Context, item domain.Attachment) (string, error) {
+	content, err := r.ReadContent(ctx, item)
+	if err != nil {
+		return "", err
+	}
+
+	return writeTempAttachment(item, content)
+}
+
+func (r *memoryAttachment) GetAll(ctx context.Context) ([]domain.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.items), nil
+}
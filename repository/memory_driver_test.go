@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"table-app/conf"
+	"table-app/domain"
+)
+
+// testDrivers
+// драйверы, на которых проверяется одинаковое поведение репозиториев
+func testDrivers(t *testing.T) map[string]Driver {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := conf.Files{
+		TableFilePath:       filepath.Join(dir, "table.csv"),
+		CategoryFilePath:    filepath.Join(dir, "category.csv"),
+		TransactionFilePath: filepath.Join(dir, "transaction.csv"),
+		RateFilePath:        filepath.Join(dir, "rate.csv"),
+		AttachmentFilePath:  filepath.Join(dir, "attachment.csv"),
+	}
+
+	fileDriver, err := OpenFileDriver(files, &testLogger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		fileDriver.Close()
+	})
+
+	return map[string]Driver{
+		"memory": NewMemoryDriver(),
+		"files":  fileDriver,
+	}
+}
+
+func TestDriverCellsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	for name, driver := range testDrivers(t) {
+		t.Run(name, func(t *testing.T) {
+			cells := []domain.Cell{
+				{Id: "c1", MainCategory: "Расходы", Category: "Еда", Value: 1500, Month: time.March, Year: 2024,
+					IsUpdated: true, Transactions: []domain.Transaction{
+						{Id: "t1", CellId: "c1", Date: date, Amount: 1000, Payee: "Магазин", IsUpdated: true},
+						{Id: "t2", CellId: "c1", Date: date, Amount: 500, Note: "кофе", IsUpdated: true},
+						{Id: "t3", CellId: "c1", Date: date, Amount: 700, IsDeleted: true},
+					}},
+				{Id: "c2", MainCategory: "Доходы", Category: "Зарплата", Value: 100000, Month: time.April, Year: 2024,
+					Currency: "USD", AccountId: "acc", IsUpdated: true},
+				{Id: "c3", MainCategory: "Расходы", Category: "Кафе", Value: 300, Month: time.May, Year: 2024,
+					IsDeleted: true},
+			}
+
+			err := driver.Cells().SaveAll(ctx, cells, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotCells, gotTransactions, err := driver.Cells().GetAll(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertCells(t, gotCells, []domain.Cell{
+				{Id: "c1", MainCategory: "Расходы", Category: "Еда", Value: 1500, Month: time.March, Year: 2024},
+				{Id: "c2", MainCategory: "Доходы", Category: "Зарплата", Value: 100000, Month: time.April,
+					Year: 2024, Currency: "USD", AccountId: "acc"},
+			})
+			for _, cell := range gotCells {
+				if cell.IsUpdated || len(cell.Transactions) != 0 {
+					t.Errorf("cell %s is read with state %+v", cell.Id, cell)
+				}
+			}
+
+			if len(gotTransactions) != 2 {
+				t.Fatalf("transactions = %+v, want t1 and t2", gotTransactions)
+			}
+			for i, want := range cells[0].Transactions[:2] {
+				got := gotTransactions[i]
+				if got.Id != want.Id || got.CellId != want.CellId || !got.Date.Equal(want.Date) ||
+					got.Amount != want.Amount || got.Note != want.Note || got.Payee != want.Payee || got.IsUpdated {
+					t.Errorf("transaction %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDriverCategoryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	for name, driver := range testDrivers(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := driver.Category()
+			food := domain.Category{Id: "1", MainCategory: "Расходы", Name: "Еда", Priority: 1}
+			cafe := domain.Category{Id: "2", MainCategory: "Расходы", Name: "Кафе", Priority: 2, ParentId: "1",
+				Currency: "USD"}
+			salary := domain.Category{Id: "3", MainCategory: "Доходы", Name: "Зарплата", Priority: 1,
+				ArchivedMonth: time.June, ArchivedYear: 2024}
+
+			err := repo.UpsertAll(ctx, []domain.Category{food, cafe, salary})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// сервис передает в UpsertAll все категории кеша, а удаленные - отдельно в DeleteAll
+			cafe.Name = "Рестораны"
+			err = repo.UpsertAll(ctx, []domain.Category{cafe, salary})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = repo.DeleteAll(ctx, []domain.Category{food})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := repo.GetAll(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			byId := make(map[string]domain.Category, len(got))
+			for _, category := range got {
+				byId[category.Id] = category
+			}
+			if len(byId) != 2 || byId["2"] != cafe || byId["3"] != salary {
+				t.Errorf("categories = %+v, want %+v and %+v", got, cafe, salary)
+			}
+		})
+	}
+}
+
+func TestDriverListRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	for name, driver := range testDrivers(t) {
+		t.Run(name, func(t *testing.T) {
+			repo := driver.Rate()
+			rates := []domain.ExchangeRate{
+				{Currency: "USD", Month: time.March, Year: 2024, Rate: 91.5},
+				{Currency: "EUR", Month: time.March, Year: 2024, Rate: 99.25},
+			}
+
+			err := repo.ReplaceAll(ctx, rates)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// изменение переданного среза не меняет сохраненные данные
+			rates[0].Rate = 1
+
+			got, err := repo.GetAll(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 || got[0].Rate != 91.5 || got[1] != rates[1] {
+				t.Errorf("rates = %+v", got)
+			}
+
+			err = repo.ReplaceAll(ctx, rates[1:])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err = repo.GetAll(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0] != rates[1] {
+				t.Errorf("rates after replace = %+v, want %+v", got, rates[1:])
+			}
+		})
+	}
+}
+
+func TestDriverRegistry(t *testing.T) {
+	ctx := context.Background()
+	registry := NewDriverRegistry()
+	registry.Register(conf.StorageMemory, func(ctx context.Context, storage conf.Storage) (Driver, error) {
+		return NewMemoryDriver(), nil
+	})
+
+	driver, err := registry.Open(ctx, conf.Storage{Memory: &conf.Memory{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := driver.(MemoryDriver); !ok {
+		t.Errorf("opened driver %T, want MemoryDriver", driver)
+	}
+
+	_, err = registry.Open(ctx, conf.Storage{Files: &conf.Files{}})
+	if err == nil {
+		t.Error("want error for driver that is not registered")
+	}
+
+	_, err = registry.Open(ctx, conf.Storage{})
+	if err == nil {
+		t.Error("want error for empty storage config")
+	}
+
+	err = registry.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
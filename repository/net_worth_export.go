@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+
+	"table-app/domain"
+
+	"github.com/pkg/errors"
+)
+
+// NetWorthExport
+// выгрузка ряда капитала в csv файл
+type NetWorthExport struct{}
+
+func NewNetWorthExport() NetWorthExport {
+	return NetWorthExport{}
+}
+
+// Write
+// файл перезаписывается целиком; первая строка - заголовок, месяц записывается как ММ.ГГГГ
+func (r NetWorthExport) Write(filePath string, points []domain.NetWorthPoint) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0664)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write([]string{"month", "balance", "assets", "liabilities", "debt", "net_worth"})
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, point := range points {
+		month := strconv.Itoa(point.Date.Month)
+		if point.Date.Month < 10 {
+			month = "0" + month
+		}
+
+		err = writer.Write([]string{
+			month + "." + strconv.Itoa(point.Date.Year),
+			point.Balance.String(),
+			point.Assets.String(),
+			point.Liabilities.String(),
+			point.Debt.String(),
+			point.NetWorth().String(),
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return nil
+}
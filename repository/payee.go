@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+
+	"table-app/domain"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Payee struct {
+	db db.DB
+}
+
+func NewPayee(db db.DB) Payee {
+	return Payee{
+		db: db,
+	}
+}
+
+type PayeeFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewPayeeFile(filePath string, logger log.Logger) PayeeFile {
+	return PayeeFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// payeeLayout - колонки файла получателей
+var payeeLayout = csvLayout{
+	columns: []string{"id", "name", "mainCategory", "category"},
+}
+
+// ReplaceAll
+// справочник получателей сохраняется целиком
+func (r Payee) ReplaceAll(ctx context.Context, items []domain.Payee) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace payees transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.payee;`)
+	if err == nil {
+		for _, item := range items {
+			err = insertPayee(ctx, tx.Exec, item)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace payees transaction")
+		}
+
+		return errors.WithMessage(err, "replace payees transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace payees transaction")
+	}
+
+	return nil
+}
+
+func insertPayee(ctx context.Context, txExec TxFuncExec, item domain.Payee) error {
+	q := `
+	INSERT INTO table_app.payee
+    	(id, name, main_category, category)
+	VALUES
+    	($1, $2, $3, $4);`
+
+	_, err := txExec(ctx, q, item.Id, item.Name, item.MainCategory, item.Category)
+	if err != nil {
+		return errors.WithMessage(err, "insert payee")
+	}
+
+	return nil
+}
+
+func (r Payee) GetAll(ctx context.Context) ([]domain.Payee, error) {
+	q := `
+	SELECT id, name, main_category, category
+	FROM table_app.payee
+	ORDER BY name;`
+
+	var items []domain.Payee
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get payees")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var item domain.Payee
+		err = rows.Scan(&item.Id, &item.Name, &item.MainCategory, &item.Category)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r PayeeFile) ReplaceAll(ctx context.Context, items []domain.Payee) error {
+	return r.writeToFile(items)
+}
+
+func (r PayeeFile) GetAll(ctx context.Context) ([]domain.Payee, error) {
+	return r.readFromFile()
+}
+
+func (r PayeeFile) readFromFile() ([]domain.Payee, error) {
+	table, err := readCSVFile(r.filePath, payeeLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Payee, 0, len(table.rows))
+	for _, row := range table.rows {
+		item := domain.Payee{}
+		item.Id = row.Value("id")
+		item.Name = row.Value("name")
+		item.MainCategory = row.Value("mainCategory")
+		item.Category = row.Value("category")
+
+		result = append(result, item)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r PayeeFile) writeToFile(data []domain.Payee) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(payeeLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, item := range data {
+		err := writer.Write([]string{
+			item.Id,
+			item.Name,
+			item.MainCategory,
+			item.Category,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
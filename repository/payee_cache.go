@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"sync"
+
+	"table-app/domain"
+)
+
+// PayeeCache
+// справочник получателей
+type PayeeCache struct {
+	items []domain.Payee
+	mutex sync.Mutex
+}
+
+func NewPayeeCache() *PayeeCache {
+	return &PayeeCache{
+		items: make([]domain.Payee, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *PayeeCache) InitCache(items []domain.Payee) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(make([]domain.Payee, 0, len(items)), items...)
+}
+
+func (r *PayeeCache) ReadAll() []domain.Payee {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Payee, 0, len(r.items)), r.items...)
+}
+
+// GetByName
+// получатель по названию без учета регистра
+func (r *PayeeCache) GetByName(name string) (domain.Payee, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, item := range r.items {
+		if domain.IsSamePayee(item.Name, name) {
+			return item, true
+		}
+	}
+
+	return domain.Payee{}, false
+}
+
+func (r *PayeeCache) Add(item domain.Payee) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(r.items, item)
+}
+
+// UpdateCategoryName
+// переносит категорию по умолчанию на новое название категории
+func (r *PayeeCache) UpdateCategoryName(oldCategory, newCategory domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if r.items[i].IsLinkedTo(oldCategory) {
+			r.items[i].MainCategory = newCategory.MainCategory
+			r.items[i].Category = newCategory.Name
+		}
+	}
+}
+
+// DeleteCategory
+// у получателей удаленной категории категория по умолчанию сбрасывается, сами получатели остаются
+func (r *PayeeCache) DeleteCategory(category domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if r.items[i].IsLinkedTo(category) {
+			r.items[i].MainCategory = ""
+			r.items[i].Category = ""
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"strconv"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Plan struct {
+	db db.DB
+}
+
+func NewPlan(db db.DB) Plan {
+	return Plan{
+		db: db,
+	}
+}
+
+type PlanFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewPlanFile(filePath string, logger log.Logger) PlanFile {
+	return PlanFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// planLayout - колонки файла плана
+var planLayout = csvLayout{
+	columns: []string{"mainCategory", "category", "month", "year", "value", "currency"},
+}
+
+// ReplaceAll
+// планы сохраняются целиком, так как нулевой план означает удаление
+func (r Plan) ReplaceAll(ctx context.Context, plans []domain.Plan) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace plans transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.plan;`)
+	if err == nil {
+		for _, plan := range plans {
+			err = insertPlan(ctx, tx.Exec, plan)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace plans transaction")
+		}
+
+		return errors.WithMessage(err, "replace plans transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace plans transaction")
+	}
+
+	return nil
+}
+
+func insertPlan(ctx context.Context, txExec TxFuncExec, plan domain.Plan) error {
+	q := `
+	INSERT INTO table_app.plan
+    	(main_category, category, month, year, value, currency)
+	VALUES
+    	($1, $2, $3, $4, $5, $6);`
+
+	_, err := txExec(ctx, q, plan.MainCategory, plan.Category, plan.Month, plan.Year, int64(plan.Value),
+		plan.Currency)
+	if err != nil {
+		return errors.WithMessage(err, "insert plan")
+	}
+
+	return nil
+}
+
+func (r Plan) GetAll(ctx context.Context) ([]domain.Plan, error) {
+	q := `
+	SELECT main_category, category, month, year, value, currency
+	FROM table_app.plan;`
+
+	var plans []domain.Plan
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get plans")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var plan domain.Plan
+		var value int64
+		err = rows.Scan(&plan.MainCategory, &plan.Category, &plan.Month, &plan.Year, &value, &plan.Currency)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		plan.Value = entity.Money(value)
+		plans = append(plans, plan)
+	}
+
+	return plans, nil
+}
+
+func (r PlanFile) ReplaceAll(ctx context.Context, plans []domain.Plan) error {
+	return r.writeToFile(plans)
+}
+
+func (r PlanFile) GetAll(ctx context.Context) ([]domain.Plan, error) {
+	return r.readFromFile()
+}
+
+func (r PlanFile) readFromFile() ([]domain.Plan, error) {
+	table, err := readCSVFile(r.filePath, planLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Plan, 0, len(table.rows))
+	for _, row := range table.rows {
+		plan, err := parsePlanRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, plan)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parsePlanRow(row csvRow) (domain.Plan, error) {
+	plan := domain.Plan{}
+	plan.MainCategory = row.Value("mainCategory")
+	plan.Category = row.Value("category")
+
+	var err error
+	plan.Month, err = parseMonth(row.Value("month"))
+	if err != nil {
+		return plan, errors.WithMessage(err, "convert month value")
+	}
+
+	plan.Year, err = strconv.Atoi(row.Value("year"))
+	if err != nil {
+		return plan, errors.WithMessage(err, "convert year value")
+	}
+
+	plan.Value, err = entity.ParseMoney(row.Value("value"))
+	if err != nil {
+		return plan, errors.WithMessage(err, "convert plan value")
+	}
+	plan.Currency = row.Value("currency")
+
+	return plan, nil
+}
+
+func (r PlanFile) writeToFile(data []domain.Plan) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(planLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, plan := range data {
+		err := writer.Write([]string{
+			plan.MainCategory,
+			plan.Category,
+			strconv.Itoa(int(plan.Month)),
+			strconv.Itoa(plan.Year),
+			plan.Value.String(),
+			plan.Currency,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
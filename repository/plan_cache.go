@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"sync"
+
+	"table-app/domain"
+)
+
+// PlanCache
+// планы по key ячейки
+type PlanCache struct {
+	cache map[domain.CellKey]domain.Plan
+	mutex sync.Mutex
+}
+
+func NewPlanCache() *PlanCache {
+	return &PlanCache{
+		cache: make(map[domain.CellKey]domain.Plan),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *PlanCache) InitCache(plans []domain.Plan) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, plan := range plans {
+		r.cache[plan.Key()] = plan
+	}
+}
+
+// Upsert
+// нулевой план удаляется
+func (r *PlanCache) Upsert(plan domain.Plan) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if plan.Value == 0 {
+		delete(r.cache, plan.Key())
+		return
+	}
+
+	r.cache[plan.Key()] = plan
+}
+
+func (r *PlanCache) Get(key domain.CellKey) (domain.Plan, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	plan, ok := r.cache[key]
+	return plan, ok
+}
+
+func (r *PlanCache) ReadAll() []domain.Plan {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	all := make([]domain.Plan, 0, len(r.cache))
+	for _, plan := range r.cache {
+		all = append(all, plan)
+	}
+	return all
+}
+
+// UpdateCategoryName
+// переносит планы на новое название категории
+func (r *PlanCache) UpdateCategoryName(oldCategory, newCategory domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, plan := range r.cache {
+		if plan.MainCategory != oldCategory.MainCategory || plan.Category != oldCategory.Name {
+			continue
+		}
+
+		delete(r.cache, key)
+		plan.MainCategory = newCategory.MainCategory
+		plan.Category = newCategory.Name
+		r.cache[plan.Key()] = plan
+	}
+}
+
+// MoveCategory
+// переносит планы категории from в категорию to; планы одного месяца в одной валюте складываются
+func (r *PlanCache) MoveCategory(from, to domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, plan := range r.cache {
+		if plan.MainCategory != from.MainCategory || plan.Category != from.Name {
+			continue
+		}
+
+		delete(r.cache, key)
+		plan.MainCategory = to.MainCategory
+		plan.Category = to.Name
+
+		target, ok := r.cache[plan.Key()]
+		if ok && target.Currency == plan.Currency {
+			plan.Value += target.Value
+		} else if ok {
+			continue
+		}
+
+		r.cache[plan.Key()] = plan
+	}
+}
+
+// DeleteCategory
+// удаляет планы категории
+func (r *PlanCache) DeleteCategory(category domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, plan := range r.cache {
+		if plan.MainCategory == category.MainCategory && plan.Category == category.Name {
+			delete(r.cache, key)
+		}
+	}
+}
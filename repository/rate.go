@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"strconv"
+
+	"table-app/domain"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Rate struct {
+	db db.DB
+}
+
+func NewRate(db db.DB) Rate {
+	return Rate{
+		db: db,
+	}
+}
+
+type RateFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewRateFile(filePath string, logger log.Logger) RateFile {
+	return RateFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// rateLayout - колонки файла курсов валют
+var rateLayout = csvLayout{
+	columns: []string{"currency", "month", "year", "rate"},
+}
+
+// ReplaceAll
+// таблица курсов небольшая, поэтому сохраняется целиком
+func (r Rate) ReplaceAll(ctx context.Context, rates []domain.ExchangeRate) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace rates transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.exchange_rate;`)
+	if err == nil {
+		for _, rate := range rates {
+			err = insertRate(ctx, tx.Exec, rate)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace rates transaction")
+		}
+
+		return errors.WithMessage(err, "replace rates transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace rates transaction")
+	}
+
+	return nil
+}
+
+func insertRate(ctx context.Context, txExec TxFuncExec, rate domain.ExchangeRate) error {
+	q := `
+	INSERT INTO table_app.exchange_rate
+    	(currency, month, year, rate)
+	VALUES
+    	($1, $2, $3, $4);`
+
+	_, err := txExec(ctx, q, rate.Currency, rate.Month, rate.Year, rate.Rate)
+	if err != nil {
+		return errors.WithMessage(err, "insert rate")
+	}
+
+	return nil
+}
+
+func (r Rate) GetAll(ctx context.Context) ([]domain.ExchangeRate, error) {
+	q := `
+	SELECT currency, month, year, rate
+	FROM table_app.exchange_rate;`
+
+	var rates []domain.ExchangeRate
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get rates")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var rate domain.ExchangeRate
+		err = rows.Scan(&rate.Currency, &rate.Month, &rate.Year, &rate.Rate)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+func (r RateFile) ReplaceAll(ctx context.Context, rates []domain.ExchangeRate) error {
+	return r.writeToFile(rates)
+}
+
+func (r RateFile) GetAll(ctx context.Context) ([]domain.ExchangeRate, error) {
+	return r.readFromFile()
+}
+
+func (r RateFile) readFromFile() ([]domain.ExchangeRate, error) {
+	table, err := readCSVFile(r.filePath, rateLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.ExchangeRate, 0, len(table.rows))
+	for _, row := range table.rows {
+		rate, err := parseRateRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, rate)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseRateRow(row csvRow) (domain.ExchangeRate, error) {
+	rate := domain.ExchangeRate{}
+	rate.Currency = row.Value("currency")
+
+	var err error
+	rate.Month, err = parseMonth(row.Value("month"))
+	if err != nil {
+		return rate, errors.WithMessage(err, "convert month value")
+	}
+
+	rate.Year, err = strconv.Atoi(row.Value("year"))
+	if err != nil {
+		return rate, errors.WithMessage(err, "convert year value")
+	}
+
+	rate.Rate, err = strconv.ParseFloat(row.Value("rate"), 64)
+	if err != nil {
+		return rate, errors.WithMessage(err, "convert rate value")
+	}
+
+	return rate, nil
+}
+
+func (r RateFile) writeToFile(data []domain.ExchangeRate) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(rateLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, rate := range data {
+		err := writer.Write([]string{
+			rate.Currency,
+			strconv.Itoa(int(rate.Month)),
+			strconv.Itoa(rate.Year),
+			strconv.FormatFloat(rate.Rate, 'f', -1, 64),
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
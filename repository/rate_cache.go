@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+
+	"github.com/pkg/errors"
+)
+
+// RateCache
+// курсы валют по месяцам; если курса на месяц нет, берется последний известный до него
+type RateCache struct {
+	baseCurrency string
+
+	// ratesByCurrency - курсы каждой валюты, отсортированные по дате
+	ratesByCurrency map[string][]domain.ExchangeRate
+	mutex           sync.Mutex
+}
+
+func NewRateCache(baseCurrency string) *RateCache {
+	return &RateCache{
+		baseCurrency:    baseCurrency,
+		ratesByCurrency: make(map[string][]domain.ExchangeRate),
+		mutex:           sync.Mutex{},
+	}
+}
+
+func (r *RateCache) InitCache(rates []domain.ExchangeRate) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.ratesByCurrency = make(map[string][]domain.ExchangeRate)
+	for _, rate := range rates {
+		r.ratesByCurrency[rate.Currency] = append(r.ratesByCurrency[rate.Currency], rate)
+	}
+
+	for currency := range r.ratesByCurrency {
+		rates := r.ratesByCurrency[currency]
+		sort.Slice(rates, func(i, j int) bool {
+			if rates[i].Year != rates[j].Year {
+				return rates[i].Year < rates[j].Year
+			}
+			return rates[i].Month < rates[j].Month
+		})
+	}
+}
+
+func (r *RateCache) ReadAll() []domain.ExchangeRate {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	all := make([]domain.ExchangeRate, 0)
+	for _, rates := range r.ratesByCurrency {
+		all = append(all, rates...)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Currency != all[j].Currency {
+			return all[i].Currency < all[j].Currency
+		}
+		if all[i].Year != all[j].Year {
+			return all[i].Year < all[j].Year
+		}
+		return all[i].Month < all[j].Month
+	})
+
+	return all
+}
+
+// Convert
+// переводит сумму в базовую валюту по курсу месяца
+func (r *RateCache) Convert(value entity.Money, currency string, month time.Month, year int) (entity.Money, error) {
+	if len(currency) == 0 || currency == r.baseCurrency || value == 0 {
+		return value, nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	rates := r.ratesByCurrency[currency]
+	for i := len(rates) - 1; i >= 0; i-- {
+		if rates[i].Before(month, year) {
+			return entity.Money(math.Round(float64(value) * rates[i].Rate)), nil
+		}
+	}
+
+	return 0, errors.Errorf("exchange rate %s for %s %d not found", currency, month.String(), year)
+}
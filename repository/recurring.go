@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Recurring struct {
+	db db.DB
+}
+
+func NewRecurring(db db.DB) Recurring {
+	return Recurring{
+		db: db,
+	}
+}
+
+type RecurringFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewRecurringFile(filePath string, logger log.Logger) RecurringFile {
+	return RecurringFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// recurringLayout - колонки файла регулярных операций
+var recurringLayout = csvLayout{
+	columns: []string{"id", "mainCategory", "category", "amount", "note", "payee", "accountId", "day",
+		"everyMonths", "startDate", "endDate", "lastDate"},
+}
+
+// ReplaceAll
+// список регулярных платежей небольшой, поэтому сохраняется целиком
+func (r Recurring) ReplaceAll(ctx context.Context, items []domain.Recurring) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace recurring transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.recurring;`)
+	if err == nil {
+		for _, item := range items {
+			err = insertRecurring(ctx, tx.Exec, item)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace recurring transaction")
+		}
+
+		return errors.WithMessage(err, "replace recurring transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace recurring transaction")
+	}
+
+	return nil
+}
+
+func insertRecurring(ctx context.Context, txExec TxFuncExec, item domain.Recurring) error {
+	q := `
+	INSERT INTO table_app.recurring
+    	(id, main_category, category, amount, note, payee, account_id,
+    	 day, every_months, start_date, end_date, last_date)
+	VALUES
+    	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`
+
+	_, err := txExec(ctx, q, item.Id, item.MainCategory, item.Category, int64(item.Amount), item.Note,
+		item.Payee, item.AccountId, item.Day, item.EveryMonths, item.StartDate,
+		nullableDate(item.EndDate), nullableDate(item.LastDate))
+	if err != nil {
+		return errors.WithMessage(err, "insert recurring")
+	}
+
+	return nil
+}
+
+func (r Recurring) GetAll(ctx context.Context) ([]domain.Recurring, error) {
+	q := `
+	SELECT id, main_category, category, amount, note, payee, account_id,
+	       day, every_months, start_date, end_date, last_date
+	FROM table_app.recurring;`
+
+	var items []domain.Recurring
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get recurring")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var item domain.Recurring
+		var amount int64
+		var endDate, lastDate *time.Time
+		err = rows.Scan(&item.Id, &item.MainCategory, &item.Category, &amount, &item.Note, &item.Payee,
+			&item.AccountId, &item.Day, &item.EveryMonths, &item.StartDate, &endDate, &lastDate)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		item.Amount = entity.Money(amount)
+		if endDate != nil {
+			item.EndDate = *endDate
+		}
+		if lastDate != nil {
+			item.LastDate = *lastDate
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r RecurringFile) ReplaceAll(ctx context.Context, items []domain.Recurring) error {
+	return r.writeToFile(items)
+}
+
+func (r RecurringFile) GetAll(ctx context.Context) ([]domain.Recurring, error) {
+	return r.readFromFile()
+}
+
+func (r RecurringFile) readFromFile() ([]domain.Recurring, error) {
+	table, err := readCSVFile(r.filePath, recurringLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Recurring, 0, len(table.rows))
+	for _, row := range table.rows {
+		item, err := parseRecurringRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseRecurringRow(row csvRow) (domain.Recurring, error) {
+	var err error
+	item := domain.Recurring{}
+	item.Id = row.Value("id")
+	item.MainCategory = row.Value("mainCategory")
+	item.Category = row.Value("category")
+
+	item.Amount, err = entity.ParseMoney(row.Value("amount"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert recurring amount")
+	}
+
+	item.Note = row.Value("note")
+	item.Payee = row.Value("payee")
+	item.AccountId = row.Value("accountId")
+
+	item.Day, err = strconv.Atoi(row.Value("day"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert recurring day")
+	}
+
+	item.EveryMonths, err = strconv.Atoi(row.Value("everyMonths"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert recurring period")
+	}
+
+	item.StartDate, err = parseOptionalDate(row.Value("startDate"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert recurring start date")
+	}
+
+	item.EndDate, err = parseOptionalDate(row.Value("endDate"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert recurring end date")
+	}
+
+	item.LastDate, err = parseOptionalDate(row.Value("lastDate"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert recurring last date")
+	}
+
+	return item, nil
+}
+
+func (r RecurringFile) writeToFile(data []domain.Recurring) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(recurringLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, item := range data {
+		err := writer.Write([]string{
+			item.Id,
+			item.MainCategory,
+			item.Category,
+			item.Amount.String(),
+			item.Note,
+			item.Payee,
+			item.AccountId,
+			strconv.Itoa(item.Day),
+			strconv.Itoa(item.EveryMonths),
+			formatOptionalDate(item.StartDate),
+			formatOptionalDate(item.EndDate),
+			formatOptionalDate(item.LastDate),
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
+
+// nullableDate
+// пустая дата сохраняется в БД как NULL
+func nullableDate(date time.Time) *time.Time {
+	if date.IsZero() {
+		return nil
+	}
+
+	return &date
+}
+
+// parseOptionalDate
+// пустая строка в файле - пустая дата
+func parseOptionalDate(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(transactionDateLayout, value)
+}
+
+func formatOptionalDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	return date.Format(transactionDateLayout)
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"table-app/domain"
+)
+
+// RecurringCache
+// регулярные платежи
+type RecurringCache struct {
+	items []domain.Recurring
+	mutex sync.Mutex
+}
+
+func NewRecurringCache() *RecurringCache {
+	return &RecurringCache{
+		items: make([]domain.Recurring, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *RecurringCache) InitCache(items []domain.Recurring) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(make([]domain.Recurring, 0, len(items)), items...)
+}
+
+func (r *RecurringCache) ReadAll() []domain.Recurring {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Recurring, 0, len(r.items)), r.items...)
+}
+
+// SetLastDate
+// запоминает дату последнего проведенного платежа
+func (r *RecurringCache) SetLastDate(id string, date time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if r.items[i].Id == id {
+			r.items[i].LastDate = date
+			return
+		}
+	}
+}
+
+// UpdateCategoryName
+// переносит платежи на новое название категории
+func (r *RecurringCache) UpdateCategoryName(oldCategory, newCategory domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if r.items[i].MainCategory == oldCategory.MainCategory && r.items[i].Category == oldCategory.Name {
+			r.items[i].MainCategory = newCategory.MainCategory
+			r.items[i].Category = newCategory.Name
+		}
+	}
+}
+
+// DeleteCategory
+// удаляет платежи категории
+func (r *RecurringCache) DeleteCategory(category domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	items := make([]domain.Recurring, 0, len(r.items))
+	for _, item := range r.items {
+		if item.MainCategory != category.MainCategory || item.Category != category.Name {
+			items = append(items, item)
+		}
+	}
+
+	r.items = items
+}
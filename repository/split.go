@@ -0,0 +1,301 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+// splitLayout - колонки файла разделенных операций, после них идут части операции
+var splitLayout = csvLayout{
+	columns: []string{"id", "date", "amount", "byPercent", "accountId", "note", "payee"},
+	group:   []string{"partTransactionId", "partMainCategory", "partCategory", "partAmount", "partPercent"},
+}
+
+type Split struct {
+	db db.DB
+}
+
+func NewSplit(db db.DB) Split {
+	return Split{
+		db: db,
+	}
+}
+
+type SplitFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewSplitFile(filePath string, logger log.Logger) SplitFile {
+	return SplitFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// ReplaceAll
+// разделенные операции сохраняются целиком; части удаляются каскадно вместе с группами
+func (r Split) ReplaceAll(ctx context.Context, splits []domain.Split) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace splits transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.split;`)
+	if err == nil {
+		for _, split := range splits {
+			err = insertSplit(ctx, tx.Exec, split)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace splits transaction")
+		}
+
+		return errors.WithMessage(err, "replace splits transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace splits transaction")
+	}
+
+	return nil
+}
+
+func insertSplit(ctx context.Context, txExec TxFuncExec, split domain.Split) error {
+	q := `
+	INSERT INTO table_app.split
+    	(id, date, amount, by_percent, account_id, note, payee)
+	VALUES
+    	($1, $2, $3, $4, $5, $6, $7);`
+
+	_, err := txExec(ctx, q, split.Id, split.Date, int64(split.Amount), split.ByPercent, split.AccountId,
+		split.Note, split.Payee)
+	if err != nil {
+		return errors.WithMessage(err, "insert split")
+	}
+
+	q = `
+	INSERT INTO table_app.split_part
+    	(split_id, position, transaction_id, main_category, category, amount, percent)
+	VALUES
+    	($1, $2, $3, $4, $5, $6, $7);`
+
+	for i, part := range split.Parts {
+		_, err = txExec(ctx, q, split.Id, i, part.TransactionId, part.MainCategory, part.Category,
+			int64(part.Amount), part.Percent)
+		if err != nil {
+			return errors.WithMessage(err, "insert split part")
+		}
+	}
+
+	return nil
+}
+
+func (r Split) GetAll(ctx context.Context) ([]domain.Split, error) {
+	q := `
+	SELECT id, date, amount, by_percent, account_id, note, payee
+	FROM table_app.split
+	ORDER BY date;`
+
+	var splits []domain.Split
+	indexById := make(map[string]int)
+
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get splits")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var split domain.Split
+		var amount int64
+		err = rows.Scan(&split.Id, &split.Date, &amount, &split.ByPercent, &split.AccountId, &split.Note,
+			&split.Payee)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		split.Amount = entity.Money(amount)
+		indexById[split.Id] = len(splits)
+		splits = append(splits, split)
+	}
+	rows.Close()
+
+	q = `
+	SELECT split_id, transaction_id, main_category, category, amount, percent
+	FROM table_app.split_part
+	ORDER BY split_id, position;`
+
+	partRows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get split parts")
+	}
+
+	defer partRows.Close()
+	for partRows.Next() {
+		var splitId string
+		var part domain.SplitPart
+		var amount int64
+		err = partRows.Scan(&splitId, &part.TransactionId, &part.MainCategory, &part.Category, &amount,
+			&part.Percent)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		part.Amount = entity.Money(amount)
+
+		i, ok := indexById[splitId]
+		if ok {
+			splits[i].Parts = append(splits[i].Parts, part)
+		}
+	}
+
+	return splits, nil
+}
+
+func (r SplitFile) ReplaceAll(ctx context.Context, splits []domain.Split) error {
+	return r.writeToFile(splits)
+}
+
+func (r SplitFile) GetAll(ctx context.Context) ([]domain.Split, error) {
+	return r.readFromFile()
+}
+
+// readFromFile
+// после колонок разделенной операции идут части, по колонкам группы splitLayout на каждую
+func (r SplitFile) readFromFile() ([]domain.Split, error) {
+	table, err := readCSVFile(r.filePath, splitLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Split, 0, len(table.rows))
+	for _, row := range table.rows {
+		split, err := parseSplitRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, split)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseSplitRow(row csvRow) (domain.Split, error) {
+	var err error
+	split := domain.Split{}
+	split.Id = row.Value("id")
+
+	split.Date, err = time.Parse(transactionDateLayout, row.Value("date"))
+	if err != nil {
+		return split, errors.WithMessage(err, "convert split date")
+	}
+
+	split.Amount, err = entity.ParseMoney(row.Value("amount"))
+	if err != nil {
+		return split, errors.WithMessage(err, "convert split amount")
+	}
+
+	split.ByPercent, err = strconv.ParseBool(row.Value("byPercent"))
+	if err != nil {
+		return split, errors.WithMessage(err, "convert split mode")
+	}
+
+	split.AccountId = row.Value("accountId")
+	split.Note = row.Value("note")
+	split.Payee = row.Value("payee")
+
+	parts, err := row.Groups(splitLayout)
+	if err != nil {
+		return split, errors.WithMessage(err, "read split parts")
+	}
+
+	for _, values := range parts {
+		part := domain.SplitPart{
+			TransactionId: values[0],
+			MainCategory:  values[1],
+			Category:      values[2],
+		}
+
+		part.Amount, err = entity.ParseMoney(values[3])
+		if err != nil {
+			return split, errors.WithMessage(err, "convert split part amount")
+		}
+
+		part.Percent, err = strconv.Atoi(values[4])
+		if err != nil {
+			return split, errors.WithMessage(err, "convert split part percent")
+		}
+
+		split.Parts = append(split.Parts, part)
+	}
+
+	return split, nil
+}
+
+func (r SplitFile) writeToFile(data []domain.Split) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(splitLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, split := range data {
+		record := []string{
+			split.Id,
+			split.Date.Format(transactionDateLayout),
+			split.Amount.String(),
+			strconv.FormatBool(split.ByPercent),
+			split.AccountId,
+			split.Note,
+			split.Payee,
+		}
+
+		for _, part := range split.Parts {
+			record = append(record, part.TransactionId, part.MainCategory, part.Category, part.Amount.String(),
+				strconv.Itoa(part.Percent))
+		}
+
+		err := writer.Write(record)
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"sync"
+
+	"table-app/domain"
+)
+
+// SplitCache
+// операции, разделенные между несколькими категориями
+type SplitCache struct {
+	items []domain.Split
+	mutex sync.Mutex
+}
+
+func NewSplitCache() *SplitCache {
+	return &SplitCache{
+		items: make([]domain.Split, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *SplitCache) InitCache(items []domain.Split) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(make([]domain.Split, 0, len(items)), items...)
+}
+
+func (r *SplitCache) ReadAll() []domain.Split {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Split, 0, len(r.items)), r.items...)
+}
+
+func (r *SplitCache) Get(id string) (domain.Split, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, item := range r.items {
+		if item.Id == id {
+			return item, true
+		}
+	}
+
+	return domain.Split{}, false
+}
+
+// GetByTransaction
+// разделенная операция, частью которой является операция ячейки
+func (r *SplitCache) GetByTransaction(transactionId string) (domain.Split, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, item := range r.items {
+		for _, part := range item.Parts {
+			if part.TransactionId == transactionId {
+				return item, true
+			}
+		}
+	}
+
+	return domain.Split{}, false
+}
+
+func (r *SplitCache) Upsert(split domain.Split) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if r.items[i].Id == split.Id {
+			r.items[i] = split
+			return
+		}
+	}
+
+	r.items = append(r.items, split)
+}
+
+func (r *SplitCache) Delete(id string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	items := make([]domain.Split, 0, len(r.items))
+	for _, item := range r.items {
+		if item.Id != id {
+			items = append(items, item)
+		}
+	}
+
+	r.items = items
+}
+
+// RenamePayee
+// меняет получателя разделенных операций
+func (r *SplitCache) RenamePayee(oldName, newName string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if domain.IsSamePayee(r.items[i].Payee, oldName) {
+			r.items[i].Payee = newName
+		}
+	}
+}
+
+// UpdateCategoryName
+// переносит части на новое название категории
+func (r *SplitCache) UpdateCategoryName(oldCategory, newCategory domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		for j := range r.items[i].Parts {
+			part := &r.items[i].Parts[j]
+			if part.IsLinkedTo(oldCategory) {
+				part.MainCategory = newCategory.MainCategory
+				part.Category = newCategory.Name
+			}
+		}
+	}
+}
+
+// DeleteCategory
+// убирает части удаленной категории вместе с их суммой: операции частей удаляются с ячейками,
+// оставшиеся части задаются суммами; группа из одной части перестает быть разделенной
+func (r *SplitCache) DeleteCategory(category domain.Category) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	items := make([]domain.Split, 0, len(r.items))
+	for _, item := range r.items {
+		parts := make([]domain.SplitPart, 0, len(item.Parts))
+		for _, part := range item.Parts {
+			if part.IsLinkedTo(category) {
+				item.Amount -= part.Amount
+				item.ByPercent = false
+				continue
+			}
+
+			parts = append(parts, part)
+		}
+		item.Parts = parts
+
+		if len(item.Parts) > 1 {
+			items = append(items, item)
+		}
+	}
+
+	r.items = items
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"table-app/domain"
+	"table-app/internal/db"
+)
+
+// SQLClient
+// клиент бд, который закрывается вместе с драйвером
+type SQLClient interface {
+	db.DB
+	Close() error
+}
+
+// SQLDriver
+// хранилище в бд: запросы репозиториев общие для postgres и sqlite
+type SQLDriver struct {
+	client SQLClient
+}
+
+func NewSQLDriver(client SQLClient) SQLDriver {
+	return SQLDriver{
+		client: client,
+	}
+}
+
+func (d SQLDriver) Cells() CellRepository {
+	return NewCells(d.client)
+}
+
+func (d SQLDriver) Category() CategoryRepository {
+	return NewCategory(d.client)
+}
+
+func (d SQLDriver) CategoryMerge() CategoryMergeRepository {
+	return NewCategoryMerge(d.client)
+}
+
+func (d SQLDriver) Rate() ListRepository[domain.ExchangeRate] {
+	return NewRate(d.client)
+}
+
+func (d SQLDriver) Account() ListRepository[domain.Account] {
+	return NewAccount(d.client)
+}
+
+func (d SQLDriver) Transfer() ListRepository[domain.Transfer] {
+	return NewTransfer(d.client)
+}
+
+func (d SQLDriver) Plan() ListRepository[domain.Plan] {
+	return NewPlan(d.client)
+}
+
+func (d SQLDriver) Recurring() ListRepository[domain.Recurring] {
+	return NewRecurring(d.client)
+}
+
+func (d SQLDriver) Checkpoint() ListRepository[domain.Checkpoint] {
+	return NewCheckpoint(d.client)
+}
+
+func (d SQLDriver) Goal() ListRepository[domain.Goal] {
+	return NewGoal(d.client)
+}
+
+func (d SQLDriver) Loan() ListRepository[domain.Loan] {
+	return NewLoan(d.client)
+}
+
+func (d SQLDriver) Valuation() ListRepository[domain.Valuation] {
+	return NewValuation(d.client)
+}
+
+func (d SQLDriver) Split() ListRepository[domain.Split] {
+	return NewSplit(d.client)
+}
+
+func (d SQLDriver) Payee() ListRepository[domain.Payee] {
+	return NewPayee(d.client)
+}
+
+func (d SQLDriver) Attachment() AttachmentRepository {
+	return NewAttachment(d.client)
+}
+
+func (d SQLDriver) DataFiles() []string {
+	return nil
+}
+
+func (d SQLDriver) Close() error {
+	return d.client.Close()
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/db/sqlite"
+)
+
+// testSQLiteDriver
+// драйвер бд поверх sqlite в памяти со схемой, созданной при открытии
+func testSQLiteDriver(t *testing.T) SQLDriver {
+	t.Helper()
+
+	client := sqlite.NewClient(&testLogger{})
+	err := client.Upgrade(context.Background(), sqlite.Config{Path: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driver := NewSQLDriver(client)
+	t.Cleanup(func() {
+		driver.Close()
+	})
+
+	return driver
+}
+
+func TestSQLDriverRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	driver := testSQLiteDriver(t)
+	date := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	food := domain.Category{Id: "1", MainCategory: "Расходы", Name: "Еда", Priority: 1, Currency: "USD",
+		ArchivedMonth: time.June, ArchivedYear: 2024}
+	err := driver.Category().UpsertAll(ctx, []domain.Category{food})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categories, err := driver.Category().GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(categories) != 1 || categories[0] != food {
+		t.Errorf("categories = %+v, want %+v", categories, food)
+	}
+
+	cell := domain.Cell{Id: "c1", MainCategory: "Расходы", Category: "Еда", Value: 1500, Month: time.March,
+		Year: 2024, Currency: "USD", AccountId: "acc", IsUpdated: true, Transactions: []domain.Transaction{
+			{Id: "t1", CellId: "c1", Date: date, Amount: 1500, Note: "кофе", Payee: "Магазин", IsUpdated: true},
+		}}
+	err = driver.Cells().SaveAll(ctx, []domain.Cell{cell}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cells, transactions, err := driver.Cells().GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertCells(t, cells, []domain.Cell{
+		{Id: "c1", MainCategory: "Расходы", Category: "Еда", Value: 1500, Month: time.March, Year: 2024,
+			Currency: "USD", AccountId: "acc"},
+	})
+
+	if len(transactions) != 1 {
+		t.Fatalf("transactions = %+v, want t1", transactions)
+	}
+	want := cell.Transactions[0]
+	got := transactions[0]
+	if got.Id != want.Id || got.CellId != want.CellId || !got.Date.Equal(want.Date) || got.Amount != want.Amount ||
+		got.Note != want.Note || got.Payee != want.Payee || got.IsUpdated {
+		t.Errorf("transaction = %+v, want %+v", got, want)
+	}
+
+	// удаление ячейки удаляет и ее операции
+	err = driver.Cells().SaveAll(ctx, nil, []domain.Cell{cell})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cells, transactions, err = driver.Cells().GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 0 || len(transactions) != 0 {
+		t.Errorf("after delete cells = %+v, transactions = %+v", cells, transactions)
+	}
+}
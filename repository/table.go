@@ -2,81 +2,185 @@ package repository
 
 import (
 	"context"
-	"encoding/csv"
-	"os"
 	"strconv"
-	"time"
 
-	"table-app/conf"
 	"table-app/domain"
+	"table-app/entity"
 	"table-app/internal/db"
+	"table-app/internal/log"
 
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pkg/errors"
 )
 
-const batchSize = 1000
-
 type TxFuncExec func(ctx context.Context, sql string, arguments ...any) (commandTag pgconn.CommandTag, err error)
 
-type Table struct {
-	db       db.DB
-	filePath string
+// Cells
+// ячейки вместе с операциями в бд: сохраняются только новые, измененные и удаленные,
+// все в одной транзакции
+type Cells struct {
+	db db.DB
 }
 
-func NewTable(db db.DB, storage conf.Storage) Table {
-	var filePath string
-
-	if storage.Files != nil {
-		filePath = storage.Files.TableFilePath
+func NewCells(db db.DB) Cells {
+	return Cells{
+		db: db,
 	}
+}
 
-	return Table{
-		db:       db,
-		filePath: filePath,
+// SaveAll
+// cells - все ячейки кеша с операциями, deleted - удаленные ячейки
+func (r Cells) SaveAll(ctx context.Context, cells, deleted []domain.Cell) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin save cells transaction")
 	}
-}
 
-func (r Table) UpsertAll(ctx context.Context, cells []domain.Cell) error {
-	if len(r.filePath) != 0 {
-		return r.writeToFile(cells)
+	err = saveCells(ctx, tx.Exec, cells, deleted)
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback save cells transaction")
+		}
+
+		return errors.WithMessage(err, "save cells transaction")
 	}
 
-	tx, err := r.db.Begin(ctx)
+	err = tx.Commit(ctx)
 	if err != nil {
-		return errors.WithMessage(err, "begin upsert transaction")
+		return errors.WithMessage(err, "commit save cells transaction")
 	}
 
+	return nil
+}
+
+// saveCells
+// операции ссылаются на ячейки, поэтому сохраняются после них, а ячейки удаляются последними:
+// перенесенные из них операции уже ссылаются на новые ячейки
+func saveCells(ctx context.Context, txExec TxFuncExec, cells, deleted []domain.Cell) error {
+	updatedCells := make([]domain.Cell, 0)
 	for _, cell := range cells {
-		err = upsertCell(ctx, tx.Exec, cell)
+		if !cell.IsUpdated {
+			continue
+		}
+
+		err := upsertCell(ctx, txExec, cell)
+		if err != nil {
+			return errors.WithMessage(err, "upsert cell")
+		}
+
+		updatedCells = append(updatedCells, cell)
+	}
+
+	for _, transaction := range collectTransactions(updatedCells, true) {
+		var err error
+		if transaction.IsDeleted {
+			err = deleteTransaction(ctx, txExec, transaction.Id)
+		} else {
+			err = upsertTransaction(ctx, txExec, transaction)
+		}
+
+		if err != nil {
+			return errors.WithMessage(err, "save transaction")
+		}
+	}
+
+	for _, cell := range deleted {
+		err := deleteCell(ctx, txExec, cell.Id)
 		if err != nil {
-			rollbackErr := tx.Rollback(ctx)
-			if rollbackErr != nil {
-				return errors.WithMessage(err, "rollback upsert transaction")
+			return errors.WithMessage(err, "delete cell")
+		}
+	}
+
+	return nil
+}
+
+// collectTransactions
+// собирает операции ячеек; onlyChanged - только новые, измененные и удаленные
+func collectTransactions(cells []domain.Cell, onlyChanged bool) []domain.Transaction {
+	result := make([]domain.Transaction, 0)
+	for _, cell := range cells {
+		for _, transaction := range cell.Transactions {
+			if onlyChanged && !transaction.IsUpdated && !transaction.IsDeleted {
+				continue
 			}
 
-			return errors.WithMessage(err, "upsert cell transaction")
+			result = append(result, transaction)
 		}
 	}
 
-	err = tx.Commit(ctx)
+	return result
+}
+
+func (r Cells) GetAll(ctx context.Context) ([]domain.Cell, []domain.Transaction, error) {
+	cells, err := selectCells(ctx, r.db)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "select cells")
+	}
+
+	transactions, err := selectTransactions(ctx, r.db)
 	if err != nil {
-		return errors.WithMessage(err, "commit upsert transaction")
+		return nil, nil, errors.WithMessage(err, "select transactions")
+	}
+
+	return cells, transactions, nil
+}
+
+// CellsFile
+// ячейки и операции в двух файлах csv, которые переписываются целиком
+type CellsFile struct {
+	table       TableFile
+	transaction TransactionFile
+}
+
+func NewCellsFile(tableFilePath, transactionFilePath string, logger log.Logger) CellsFile {
+	return CellsFile{
+		table:       TableFile{filePath: tableFilePath, logger: logger},
+		transaction: TransactionFile{filePath: transactionFilePath, logger: logger},
+	}
+}
+
+// SaveAll
+// удаленные ячейки и операции просто не попадают в файлы
+func (r CellsFile) SaveAll(ctx context.Context, cells, deleted []domain.Cell) error {
+	err := r.table.writeToFile(cells)
+	if err != nil {
+		return errors.WithMessage(err, "write cells")
+	}
+
+	err = r.transaction.writeToFile(collectTransactions(cells, false))
+	if err != nil {
+		return errors.WithMessage(err, "write transactions")
 	}
 
 	return nil
 }
 
+func (r CellsFile) GetAll(ctx context.Context) ([]domain.Cell, []domain.Transaction, error) {
+	cells, err := r.table.readFromFile()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "read cells")
+	}
+
+	transactions, err := r.transaction.readFromFile()
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "read transactions")
+	}
+
+	return cells, transactions, nil
+}
+
 func upsertCell(ctx context.Context, txExec TxFuncExec, cell domain.Cell) error {
 	q := `
 	INSERT INTO table_app.finances
-    	(id, main_category, category, value, month, year)
+    	(id, main_category, category, value, month, year, currency, account_id)
 	VALUES
-    	($1, $2, $3, $4, $5, $6)
+    	($1, $2, $3, $4, $5, $6, $7, $8)
 	ON CONFLICT (id) DO UPDATE 
-	    SET value = $4;`
+	    SET main_category = $2, category = $3, value = $4, currency = $7, account_id = $8;`
 
-	_, err := txExec(ctx, q, cell.Id, cell.MainCategory, cell.Category, cell.Value, cell.Month, cell.Year)
+	_, err := txExec(ctx, q, cell.Id, cell.MainCategory, cell.Category, int64(cell.Value), cell.Month, cell.Year,
+		cell.Currency, cell.AccountId)
 	if err != nil {
 		return errors.WithMessage(err, "upsert cell")
 	}
@@ -84,17 +188,28 @@ func upsertCell(ctx context.Context, txExec TxFuncExec, cell domain.Cell) error
 	return nil
 }
 
-func (r Table) GetAll(ctx context.Context) ([]domain.Cell, error) {
-	if len(r.filePath) != 0 {
-		return r.readFromFile()
+// deleteCell
+// операции ячейки удаляются каскадно
+func deleteCell(ctx context.Context, txExec TxFuncExec, id string) error {
+	q := `
+	DELETE FROM table_app.finances
+	WHERE id = $1;`
+
+	_, err := txExec(ctx, q, id)
+	if err != nil {
+		return errors.WithMessage(err, "delete cell")
 	}
 
+	return nil
+}
+
+func selectCells(ctx context.Context, db db.DB) ([]domain.Cell, error) {
 	q := `
-	SELECT id, main_category, category, value, month, year 
+	SELECT id, main_category, category, value, month, year, currency, account_id
 	FROM table_app.finances;`
 
 	var cells []domain.Cell
-	rows, err := r.db.Select(ctx, q)
+	rows, err := db.Select(ctx, q)
 	if err != nil {
 		return nil, errors.WithMessage(err, "get cells")
 	}
@@ -102,82 +217,119 @@ func (r Table) GetAll(ctx context.Context) ([]domain.Cell, error) {
 	defer rows.Close()
 	for rows.Next() {
 		var cell domain.Cell
-		err = rows.Scan(&cell.Id, &cell.MainCategory, &cell.Category, &cell.Value, &cell.Month, &cell.Year)
+		var value int64
+		err = rows.Scan(&cell.Id, &cell.MainCategory, &cell.Category, &value, &cell.Month, &cell.Year,
+			&cell.Currency, &cell.AccountId)
 		if err != nil {
 			return nil, errors.WithMessage(err, "scan row")
 		}
+		cell.Value = entity.Money(value)
 		cells = append(cells, cell)
 	}
 
 	return cells, nil
 }
 
-func (r Table) readFromFile() ([]domain.Cell, error) {
-	file, err := os.OpenFile(r.filePath, os.O_RDONLY|os.O_CREATE, 0664)
-	if err != nil {
-		return nil, errors.WithMessage(err, "open file")
-	}
-	defer file.Close()
+// TableFile
+// файл csv ячеек
+type TableFile struct {
+	filePath string
+	logger   log.Logger
+}
 
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
+// cellLayout - колонки файла ячеек; в старых файлах обязательны первые шесть
+var cellLayout = csvLayout{
+	columns:        []string{"id", "mainCategory", "category", "value", "month", "year", "currency", "accountId"},
+	legacyRequired: 6,
+}
+
+func (r TableFile) readFromFile() ([]domain.Cell, error) {
+	table, err := readCSVFile(r.filePath, cellLayout)
 	if err != nil {
-		return nil, errors.WithMessage(err, "read all file")
+		return nil, errors.WithMessage(err, "read file")
 	}
 
-	result := make([]domain.Cell, 0)
-	for _, record := range records {
-		cell := domain.Cell{}
-		cell.Id = record[0]
-		cell.MainCategory = record[1]
-		cell.Category = record[2]
-
-		value, err := strconv.Atoi(record[3])
-		if err != nil {
-			return nil, errors.WithMessage(err, "convert cell value")
-		}
-		cell.Value = value
-
-		month, err := strconv.Atoi(record[4])
+	result := make([]domain.Cell, 0, len(table.rows))
+	for _, row := range table.rows {
+		cell, err := parseCellRow(row)
 		if err != nil {
-			return nil, errors.WithMessage(err, "convert month value")
+			table.reject(row, err)
+			continue
 		}
-		cell.Month = time.Month(month)
-
-		year, err := strconv.Atoi(record[5])
-		if err != nil {
-			return nil, errors.WithMessage(err, "convert year value")
-		}
-		cell.Year = year
 
 		result = append(result, cell)
 	}
 
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
 
-func (r Table) writeToFile(data []domain.Cell) error {
-	file, err := os.OpenFile(r.filePath, os.O_WRONLY, 0664)
+// parseCellRow
+// валюта и счет появились позже, в старых файлах этих колонок нет
+func parseCellRow(row csvRow) (domain.Cell, error) {
+	var err error
+	cell := domain.Cell{}
+	cell.Id = row.Value("id")
+	cell.MainCategory = row.Value("mainCategory")
+	cell.Category = row.Value("category")
+
+	// старые файлы хранят целые рубли, ParseMoney читает их без потерь
+	cell.Value, err = entity.ParseMoney(row.Value("value"))
 	if err != nil {
-		return errors.WithMessage(err, "open file")
+		return cell, errors.WithMessage(err, "convert cell value")
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
+	cell.Month, err = parseMonth(row.Value("month"))
+	if err != nil {
+		return cell, errors.WithMessage(err, "convert month value")
+	}
+
+	cell.Year, err = strconv.Atoi(row.Value("year"))
+	if err != nil {
+		return cell, errors.WithMessage(err, "convert year value")
+	}
+
+	cell.Currency = row.Value("currency")
+	cell.AccountId = row.Value("accountId")
+
+	return cell, nil
+}
+
+func (r TableFile) writeToFile(data []domain.Cell) error {
+	file, err := r.writeTempFile(data)
+	if err != nil {
+		return err
+	}
+
+	return file.Commit()
+}
+
+// writeTempFile
+// записывает ячейки во временный файл; файл данных заменяется только после Commit
+func (r TableFile) writeTempFile(data []domain.Cell) (*atomicFile, error) {
+	records := make([][]string, 0, len(data))
 	for _, cell := range data {
-		err := writer.Write([]string{
+		if cell.IsDeleted {
+			continue
+		}
+
+		records = append(records, []string{
 			cell.Id,
 			cell.MainCategory,
 			cell.Category,
-			strconv.Itoa(cell.Value),
+			cell.Value.String(),
 			strconv.Itoa(int(cell.Month)),
 			strconv.Itoa(cell.Year),
+			cell.Currency,
+			cell.AccountId,
 		})
-		if err != nil {
-			return errors.WithMessage(err, "write to file")
-		}
 	}
-	writer.Flush()
 
-	return nil
+	return writeCSVTemp(r.filePath, cellLayout, records)
 }
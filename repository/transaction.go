@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+const transactionDateLayout = "2006-01-02"
+
+func upsertTransaction(ctx context.Context, txExec TxFuncExec, transaction domain.Transaction) error {
+	q := `
+	INSERT INTO table_app.transaction
+    	(id, cell_id, date, amount, note, payee, account_id)
+	VALUES
+    	($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (id) DO UPDATE 
+	    SET cell_id = $2, date = $3, amount = $4, note = $5, payee = $6, account_id = $7;`
+
+	_, err := txExec(ctx, q, transaction.Id, transaction.CellId, transaction.Date,
+		int64(transaction.Amount), transaction.Note, transaction.Payee, transaction.AccountId)
+	if err != nil {
+		return errors.WithMessage(err, "upsert transaction")
+	}
+
+	return nil
+}
+
+func deleteTransaction(ctx context.Context, txExec TxFuncExec, id string) error {
+	q := `
+	DELETE FROM table_app.transaction
+	WHERE id = $1;`
+
+	_, err := txExec(ctx, q, id)
+	if err != nil {
+		return errors.WithMessage(err, "delete transaction")
+	}
+
+	return nil
+}
+
+func selectTransactions(ctx context.Context, db db.DB) ([]domain.Transaction, error) {
+	q := `
+	SELECT id, cell_id, date, amount, note, payee, account_id
+	FROM table_app.transaction;`
+
+	var transactions []domain.Transaction
+	rows, err := db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get transactions")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var transaction domain.Transaction
+		var amount int64
+		err = rows.Scan(&transaction.Id, &transaction.CellId, &transaction.Date,
+			&amount, &transaction.Note, &transaction.Payee, &transaction.AccountId)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		transaction.Amount = entity.Money(amount)
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, nil
+}
+
+// TransactionFile
+// файл csv операций ячеек
+type TransactionFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+// transactionLayout - колонки файла операций; в старых файлах обязательны первые шесть
+var transactionLayout = csvLayout{
+	columns:        []string{"id", "cellId", "date", "amount", "note", "payee", "accountId"},
+	legacyRequired: 6,
+}
+
+func (r TransactionFile) readFromFile() ([]domain.Transaction, error) {
+	table, err := readCSVFile(r.filePath, transactionLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Transaction, 0, len(table.rows))
+	for _, row := range table.rows {
+		transaction, err := parseTransactionRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, transaction)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// parseTransactionRow
+// счет появился позже, в старых файлах этой колонки нет
+func parseTransactionRow(row csvRow) (domain.Transaction, error) {
+	var err error
+	transaction := domain.Transaction{}
+	transaction.Id = row.Value("id")
+	transaction.CellId = row.Value("cellId")
+
+	transaction.Date, err = time.Parse(transactionDateLayout, row.Value("date"))
+	if err != nil {
+		return transaction, errors.WithMessage(err, "convert transaction date")
+	}
+
+	transaction.Amount, err = entity.ParseMoney(row.Value("amount"))
+	if err != nil {
+		return transaction, errors.WithMessage(err, "convert transaction amount")
+	}
+
+	transaction.Note = row.Value("note")
+	transaction.Payee = row.Value("payee")
+	transaction.AccountId = row.Value("accountId")
+
+	return transaction, nil
+}
+
+func (r TransactionFile) writeToFile(data []domain.Transaction) error {
+	file, err := r.writeTempFile(data)
+	if err != nil {
+		return err
+	}
+
+	return file.Commit()
+}
+
+// writeTempFile
+// записывает операции во временный файл; файл данных заменяется только после Commit
+func (r TransactionFile) writeTempFile(data []domain.Transaction) (*atomicFile, error) {
+	records := make([][]string, 0, len(data))
+	for _, transaction := range data {
+		if transaction.IsDeleted {
+			continue
+		}
+
+		records = append(records, []string{
+			transaction.Id,
+			transaction.CellId,
+			transaction.Date.Format(transactionDateLayout),
+			transaction.Amount.String(),
+			transaction.Note,
+			transaction.Payee,
+			transaction.AccountId,
+		})
+	}
+
+	return writeCSVTemp(r.filePath, transactionLayout, records)
+}
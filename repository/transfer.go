@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Transfer struct {
+	db db.DB
+}
+
+func NewTransfer(db db.DB) Transfer {
+	return Transfer{
+		db: db,
+	}
+}
+
+type TransferFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewTransferFile(filePath string, logger log.Logger) TransferFile {
+	return TransferFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// transferLayout - колонки файла переводов между счетами
+var transferLayout = csvLayout{
+	columns: []string{"id", "fromAccountId", "toAccountId", "amount", "date", "note"},
+}
+
+// ReplaceAll
+// переводы сохраняются целиком вместе со счетами
+func (r Transfer) ReplaceAll(ctx context.Context, transfers []domain.Transfer) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace transfers transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.transfer;`)
+	if err == nil {
+		for _, transfer := range transfers {
+			err = insertTransfer(ctx, tx.Exec, transfer)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace transfers transaction")
+		}
+
+		return errors.WithMessage(err, "replace transfers transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace transfers transaction")
+	}
+
+	return nil
+}
+
+func insertTransfer(ctx context.Context, txExec TxFuncExec, transfer domain.Transfer) error {
+	q := `
+	INSERT INTO table_app.transfer
+    	(id, from_account_id, to_account_id, amount, date, note)
+	VALUES
+    	($1, $2, $3, $4, $5, $6);`
+
+	_, err := txExec(ctx, q, transfer.Id, transfer.FromAccountId, transfer.ToAccountId,
+		int64(transfer.Amount), transfer.Date, transfer.Note)
+	if err != nil {
+		return errors.WithMessage(err, "insert transfer")
+	}
+
+	return nil
+}
+
+func (r Transfer) GetAll(ctx context.Context) ([]domain.Transfer, error) {
+	q := `
+	SELECT id, from_account_id, to_account_id, amount, date, note
+	FROM table_app.transfer;`
+
+	var transfers []domain.Transfer
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get transfers")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var transfer domain.Transfer
+		var amount int64
+		err = rows.Scan(&transfer.Id, &transfer.FromAccountId, &transfer.ToAccountId,
+			&amount, &transfer.Date, &transfer.Note)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		transfer.Amount = entity.Money(amount)
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+func (r TransferFile) ReplaceAll(ctx context.Context, transfers []domain.Transfer) error {
+	return r.writeToFile(transfers)
+}
+
+func (r TransferFile) GetAll(ctx context.Context) ([]domain.Transfer, error) {
+	return r.readFromFile()
+}
+
+func (r TransferFile) readFromFile() ([]domain.Transfer, error) {
+	table, err := readCSVFile(r.filePath, transferLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Transfer, 0, len(table.rows))
+	for _, row := range table.rows {
+		transfer, err := parseTransferRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, transfer)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseTransferRow(row csvRow) (domain.Transfer, error) {
+	var err error
+	transfer := domain.Transfer{}
+	transfer.Id = row.Value("id")
+	transfer.FromAccountId = row.Value("fromAccountId")
+	transfer.ToAccountId = row.Value("toAccountId")
+
+	transfer.Amount, err = entity.ParseMoney(row.Value("amount"))
+	if err != nil {
+		return transfer, errors.WithMessage(err, "convert transfer amount")
+	}
+
+	transfer.Date, err = time.Parse(transactionDateLayout, row.Value("date"))
+	if err != nil {
+		return transfer, errors.WithMessage(err, "convert transfer date")
+	}
+
+	transfer.Note = row.Value("note")
+
+	return transfer, nil
+}
+
+func (r TransferFile) writeToFile(data []domain.Transfer) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(transferLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, transfer := range data {
+		err := writer.Write([]string{
+			transfer.Id,
+			transfer.FromAccountId,
+			transfer.ToAccountId,
+			transfer.Amount.String(),
+			transfer.Date.Format(transactionDateLayout),
+			transfer.Note,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"encoding/csv"
+	"time"
+
+	"table-app/domain"
+	"table-app/entity"
+	"table-app/internal/db"
+	"table-app/internal/log"
+
+	"github.com/pkg/errors"
+)
+
+type Valuation struct {
+	db db.DB
+}
+
+func NewValuation(db db.DB) Valuation {
+	return Valuation{
+		db: db,
+	}
+}
+
+type ValuationFile struct {
+	filePath string
+	logger   log.Logger
+}
+
+func NewValuationFile(filePath string, logger log.Logger) ValuationFile {
+	return ValuationFile{
+		filePath: filePath,
+		logger:   logger,
+	}
+}
+
+// valuationLayout - колонки файла оценок активов
+var valuationLayout = csvLayout{
+	columns: []string{"id", "asset", "kind", "date", "value", "note"},
+}
+
+// ReplaceAll
+// оценок немного, поэтому список сохраняется целиком
+func (r Valuation) ReplaceAll(ctx context.Context, items []domain.Valuation) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "begin replace valuations transaction")
+	}
+
+	_, err = tx.Exec(ctx, `DELETE FROM table_app.valuation;`)
+	if err == nil {
+		for _, item := range items {
+			err = insertValuation(ctx, tx.Exec, item)
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		rollbackErr := tx.Rollback(ctx)
+		if rollbackErr != nil {
+			return errors.WithMessage(err, "rollback replace valuations transaction")
+		}
+
+		return errors.WithMessage(err, "replace valuations transaction")
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "commit replace valuations transaction")
+	}
+
+	return nil
+}
+
+func insertValuation(ctx context.Context, txExec TxFuncExec, item domain.Valuation) error {
+	q := `
+	INSERT INTO table_app.valuation
+    	(id, asset, kind, date, value, note)
+	VALUES
+    	($1, $2, $3, $4, $5, $6);`
+
+	_, err := txExec(ctx, q, item.Id, item.Asset, item.Kind, item.Date, int64(item.Value), item.Note)
+	if err != nil {
+		return errors.WithMessage(err, "insert valuation")
+	}
+
+	return nil
+}
+
+func (r Valuation) GetAll(ctx context.Context) ([]domain.Valuation, error) {
+	q := `
+	SELECT id, asset, kind, date, value, note
+	FROM table_app.valuation
+	ORDER BY date;`
+
+	var items []domain.Valuation
+	rows, err := r.db.Select(ctx, q)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get valuations")
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var item domain.Valuation
+		var value int64
+		err = rows.Scan(&item.Id, &item.Asset, &item.Kind, &item.Date, &value, &item.Note)
+		if err != nil {
+			return nil, errors.WithMessage(err, "scan row")
+		}
+		item.Value = entity.Money(value)
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r ValuationFile) ReplaceAll(ctx context.Context, items []domain.Valuation) error {
+	return r.writeToFile(items)
+}
+
+func (r ValuationFile) GetAll(ctx context.Context) ([]domain.Valuation, error) {
+	return r.readFromFile()
+}
+
+func (r ValuationFile) readFromFile() ([]domain.Valuation, error) {
+	table, err := readCSVFile(r.filePath, valuationLayout)
+	if err != nil {
+		return nil, errors.WithMessage(err, "read file")
+	}
+
+	result := make([]domain.Valuation, 0, len(table.rows))
+	for _, row := range table.rows {
+		item, err := parseValuationRow(row)
+		if err != nil {
+			table.reject(row, err)
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	err = table.finish(r.logger, func() error {
+		return r.writeToFile(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseValuationRow(row csvRow) (domain.Valuation, error) {
+	var err error
+	item := domain.Valuation{}
+	item.Id = row.Value("id")
+	item.Asset = row.Value("asset")
+	item.Kind = row.Value("kind")
+
+	item.Date, err = time.Parse(transactionDateLayout, row.Value("date"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert valuation date")
+	}
+
+	item.Value, err = entity.ParseMoney(row.Value("value"))
+	if err != nil {
+		return item, errors.WithMessage(err, "convert valuation value")
+	}
+
+	item.Note = row.Value("note")
+
+	return item, nil
+}
+
+func (r ValuationFile) writeToFile(data []domain.Valuation) error {
+	file, err := createAtomicFile(r.filePath)
+	if err != nil {
+		return errors.WithMessage(err, "open file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.Write(valuationLayout.header())
+	if err != nil {
+		return errors.WithMessage(err, "write header")
+	}
+
+	for _, item := range data {
+		err := writer.Write([]string{
+			item.Id,
+			item.Asset,
+			item.Kind,
+			item.Date.Format(transactionDateLayout),
+			item.Value.String(),
+			item.Note,
+		})
+		if err != nil {
+			return errors.WithMessage(err, "write to file")
+		}
+	}
+	writer.Flush()
+
+	err = writer.Error()
+	if err != nil {
+		return errors.WithMessage(err, "flush file")
+	}
+
+	return file.Commit()
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"sync"
+
+	"table-app/domain"
+)
+
+// ValuationCache
+// оценки активов и обязательств
+type ValuationCache struct {
+	items []domain.Valuation
+	mutex sync.Mutex
+}
+
+func NewValuationCache() *ValuationCache {
+	return &ValuationCache{
+		items: make([]domain.Valuation, 0),
+		mutex: sync.Mutex{},
+	}
+}
+
+func (r *ValuationCache) InitCache(items []domain.Valuation) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.items = append(make([]domain.Valuation, 0, len(items)), items...)
+}
+
+func (r *ValuationCache) ReadAll() []domain.Valuation {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append(make([]domain.Valuation, 0, len(r.items)), r.items...)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type AccountRepository interface {
+	ReplaceAll(ctx context.Context, accounts []domain.Account) error
+}
+
+type TransferRepository interface {
+	ReplaceAll(ctx context.Context, transfers []domain.Transfer) error
+}
+
+type Account struct {
+	logger       log.Logger
+	cache        *repository.AccountCache
+	repo         AccountRepository
+	transferRepo TransferRepository
+}
+
+func NewAccount(logger log.Logger, cache *repository.AccountCache, repo AccountRepository,
+	transferRepo TransferRepository) *Account {
+	return &Account{
+		logger:       logger,
+		cache:        cache,
+		repo:         repo,
+		transferRepo: transferRepo,
+	}
+}
+
+func (s *Account) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAccounts())
+	if err != nil {
+		return errors.WithMessage(err, "replace accounts")
+	}
+
+	err = s.transferRepo.ReplaceAll(ctx, s.cache.ReadTransfers())
+	if err != nil {
+		return errors.WithMessage(err, "replace transfers")
+	}
+
+	return nil
+}
+
+func (s *Account) GetAccounts() []domain.Account {
+	return s.cache.ReadAccounts()
+}
+
+func (s *Account) GetTransfers() []domain.Transfer {
+	return s.cache.ReadTransfers()
+}
+
+// ReplaceAccounts
+// заменяет списки счетов и переводов; порядок счетов задается порядком в списке
+func (s *Account) ReplaceAccounts(accounts []domain.Account, transfers []domain.Transfer) error {
+	for i := range accounts {
+		err := accounts[i].Validate()
+		if err != nil {
+			return errors.WithMessagef(err, "validate account %s", accounts[i].Name)
+		}
+
+		if len(accounts[i].Id) == 0 {
+			accounts[i].Id = uuid.New().String()
+		}
+		accounts[i].Priority = i + 1
+	}
+
+	for i := range transfers {
+		err := transfers[i].Validate()
+		if err != nil {
+			return errors.WithMessage(err, "validate transfer")
+		}
+
+		if len(transfers[i].Id) == 0 {
+			transfers[i].Id = uuid.New().String()
+		}
+	}
+
+	s.cache.InitCache(accounts, transfers)
+	return nil
+}
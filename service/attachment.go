@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type AttachmentRepository interface {
+	ReplaceAll(ctx context.Context, items []domain.Attachment) error
+	WriteContent(ctx context.Context, item domain.Attachment, content []byte) error
+	ReadContent(ctx context.Context, item domain.Attachment) ([]byte, error)
+	LocalPath(ctx context.Context, item domain.Attachment) (string, error)
+}
+
+type BackupWriter interface {
+	Write(filePath string, attachments []domain.Attachment,
+		content func(item domain.Attachment) ([]byte, error)) error
+}
+
+type Attachment struct {
+	logger log.Logger
+	cache  *repository.AttachmentCache
+	repo   AttachmentRepository
+	backup BackupWriter
+}
+
+func NewAttachment(logger log.Logger, cache *repository.AttachmentCache, repo AttachmentRepository,
+	backup BackupWriter) *Attachment {
+	return &Attachment{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+		backup: backup,
+	}
+}
+
+func (s *Attachment) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace attachments")
+	}
+
+	return nil
+}
+
+func (s *Attachment) GetAttachments(key domain.CellKey) []domain.Attachment {
+	return s.cache.GetByCell(key)
+}
+
+// AddAttachment
+// копирует файл sourcePath в хранилище вложений сразу, а описание вложения - в кеш
+func (s *Attachment) AddAttachment(ctx context.Context, item domain.Attachment,
+	sourcePath string) (domain.Attachment, error) {
+	item.Name = filepath.Base(sourcePath)
+
+	err := item.Validate()
+	if err != nil {
+		return domain.Attachment{}, errors.WithMessage(err, "validate attachment")
+	}
+
+	content, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return domain.Attachment{}, errors.WithMessage(err, "read attachment file")
+	}
+
+	item.Id = uuid.New().String()
+	item.Size = int64(len(content))
+	item.Added = time.Now().UTC().Truncate(time.Second)
+
+	err = s.repo.WriteContent(ctx, item, content)
+	if err != nil {
+		return domain.Attachment{}, errors.WithMessage(err, "write attachment content")
+	}
+
+	s.cache.Add(item)
+	return item, nil
+}
+
+// DeleteAttachment
+// удаляет описание вложения; содержимое удаляется из хранилища при сохранении
+func (s *Attachment) DeleteAttachment(id string) {
+	s.cache.Delete(id)
+}
+
+// AttachmentPath
+// путь к файлу вложения для открытия в программе просмотра
+func (s *Attachment) AttachmentPath(ctx context.Context, id string) (string, error) {
+	item, ok := s.cache.Get(id)
+	if !ok {
+		return "", errors.Errorf("attachment %s not found", id)
+	}
+
+	path, err := s.repo.LocalPath(ctx, item)
+	if err != nil {
+		return "", errors.WithMessage(err, "attachment local path")
+	}
+
+	return path, nil
+}
+
+func (s *Attachment) UpdateCategoryName(oldCateg, newCateg domain.Category) {
+	s.cache.UpdateCategoryName(oldCateg, newCateg)
+}
+
+func (s *Attachment) DeleteCategory(category domain.Category) {
+	s.cache.DeleteCategory(category)
+}
+
+// Backup
+// резервная копия данных вместе с содержимым всех вложений
+func (s *Attachment) Backup(ctx context.Context, filePath string) error {
+	err := s.backup.Write(filePath, s.cache.ReadAll(), func(item domain.Attachment) ([]byte, error) {
+		return s.repo.ReadContent(ctx, item)
+	})
+	if err != nil {
+		return errors.WithMessage(err, "write backup")
+	}
+
+	return nil
+}
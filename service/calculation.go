@@ -1,11 +1,13 @@
 package service
 
 import (
-	"strconv"
+	"sort"
+	"strings"
 	"time"
 
 	"table-app/conf"
 	"table-app/domain"
+	"table-app/entity"
 	"table-app/repository"
 	"table-app/utils"
 
@@ -16,25 +18,47 @@ type Calculation struct {
 	cache         *repository.CalculationCache
 	cellsCache    *repository.CellsCache
 	categoryCache *repository.CategoryCache
-	settings      conf.Setting
+	rateCache     *repository.RateCache
+	accountCache  *repository.AccountCache
+	planCache     *repository.PlanCache
+
+	checkpointCache *repository.CheckpointCache
+	loanCache       *repository.LoanCache
+	valuationCache  *repository.ValuationCache
+	settings        conf.Setting
 }
 
 func NewCalculation(
 	cache *repository.CalculationCache,
 	cellsCache *repository.CellsCache,
 	categoryCache *repository.CategoryCache,
+	rateCache *repository.RateCache,
+	accountCache *repository.AccountCache,
+	planCache *repository.PlanCache,
+	checkpointCache *repository.CheckpointCache,
+	loanCache *repository.LoanCache,
+	valuationCache *repository.ValuationCache,
 	settings conf.Setting,
 ) *Calculation {
 	return &Calculation{
 		cache:         cache,
 		cellsCache:    cellsCache,
 		categoryCache: categoryCache,
-		settings:      settings,
+		rateCache:     rateCache,
+		accountCache:  accountCache,
+		planCache:     planCache,
+
+		checkpointCache: checkpointCache,
+		loanCache:       loanCache,
+		valuationCache:  valuationCache,
+		settings:        settings,
 	}
 }
 
-func (s *Calculation) ConsumptionSum(month, year int) int {
-	res := 0
+// ConsumptionSum
+// сумма расходов за месяц в базовой валюте; при отсутствии курса возвращается
+// сумма без непереведенных ячеек вместе с ошибкой
+func (s *Calculation) ConsumptionSum(month, year int) (entity.Money, error) {
 	s.categoryCache.Lock()
 	categories := s.categoryCache.GetCategoryArray()
 	s.categoryCache.Unlock()
@@ -43,29 +67,19 @@ func (s *Calculation) ConsumptionSum(month, year int) int {
 	valuesList := s.cellsCache.GetList()
 	s.cellsCache.Unlock()
 
-	idx := s.settings.MainCategoryOrder["Расходы"]
-
-	for i, mainCategory := range categories {
-		if i != idx {
-			continue
-		}
-		for _, categ := range mainCategory {
-			compositeId := categ.MainCategory + categ.Name + strconv.Itoa(month) + strconv.Itoa(year)
-			cell, ok := valuesList[compositeId]
-			if ok {
-				res += cell.Value
-			}
-		}
-	}
+	res, err := domain.SumByKind(categories, valuesList, s.settings.MainCategoryOrder,
+		conf.KindExpense, month, year, s.rateCache.Convert)
 
 	s.cache.UpsertConsumption(month, year, res)
 
-	return res
-}
+	if err != nil {
+		return res, errors.WithMessage(err, "convert consumption")
+	}
 
-func (s *Calculation) BalanceSum(month, year int) (int, error) {
-	res := 0
+	return res, nil
+}
 
+func (s *Calculation) BalanceSum(month, year int) (entity.Money, error) {
 	s.categoryCache.Lock()
 	categories := s.categoryCache.GetCategoryArray()
 	s.categoryCache.Unlock()
@@ -74,20 +88,9 @@ func (s *Calculation) BalanceSum(month, year int) (int, error) {
 	valuesList := s.cellsCache.GetList()
 	s.cellsCache.Unlock()
 
-	idx := s.settings.MainCategoryOrder["Доходы"]
-
-	for i, mainCategory := range categories {
-		if i != idx {
-			continue
-		}
-		for _, categ := range mainCategory {
-			compositeId := categ.MainCategory + categ.Name + strconv.Itoa(month) + strconv.Itoa(year)
-			cell, ok := valuesList[compositeId]
-			if ok {
-				res += cell.Value
-			}
-		}
-	}
+	// переводы и нейтральные категории на остаток не влияют
+	res, convertErr := domain.SumByKind(categories, valuesList, s.settings.MainCategoryOrder,
+		conf.KindIncome, month, year, s.rateCache.Convert)
 
 	consumption, ok := s.cache.GetConsumption(month, year)
 	if !ok {
@@ -103,11 +106,21 @@ func (s *Calculation) BalanceSum(month, year int) (int, error) {
 
 	s.cache.UpsertBalance(month, year, res)
 
+	err = s.upsertCheckpointDiff(month, year, prevBalance, categories, valuesList)
+	if err != nil && convertErr == nil {
+		convertErr = err
+	}
+
+	if convertErr != nil {
+		return res, errors.WithMessage(convertErr, "convert income")
+	}
+
 	return res, nil
 }
 
-func (s *Calculation) UpsertBalance(currentMonth, currentYear int) (map[string]int, error) {
-	res := make(map[string]int)
+func (s *Calculation) UpsertBalance(currentMonth, currentYear int) (map[entity.MonthYear]entity.Money, error) {
+	res := make(map[entity.MonthYear]entity.Money)
+	var convertErr error
 
 	s.categoryCache.Lock()
 	categories := s.categoryCache.GetCategoryArray()
@@ -117,9 +130,7 @@ func (s *Calculation) UpsertBalance(currentMonth, currentYear int) (map[string]i
 	valuesList := s.cellsCache.GetList()
 	s.cellsCache.Unlock()
 
-	idx := s.settings.MainCategoryOrder["Доходы"]
-
-	var firstMonth, lastMonth time.Month
+	var firstMonth time.Month
 	for year := currentYear; year <= time.Now().Year(); year++ {
 		if year != currentYear {
 			// если год не текущий
@@ -128,26 +139,12 @@ func (s *Calculation) UpsertBalance(currentMonth, currentYear int) (map[string]i
 			firstMonth = time.Month(currentMonth)
 		}
 
-		if year != time.Now().Year() {
-			// если год не текущий
-			lastMonth = time.December
-		} else {
-			lastMonth = time.Now().Month()
-		}
-
-		for month := firstMonth; month <= lastMonth; month++ {
-			sum := 0
-			for i, mainCategory := range categories {
-				if i != idx {
-					continue
-				}
-				for _, categ := range mainCategory {
-					compositeId := categ.MainCategory + categ.Name + strconv.Itoa(int(month)) + strconv.Itoa(year)
-					cell, ok := valuesList[compositeId]
-					if ok {
-						sum += cell.Value
-					}
-				}
+		// остаток считается до конца текущего года, так как будущие месяцы можно планировать
+		for month := firstMonth; month <= time.December; month++ {
+			sum, err := domain.SumByKind(categories, valuesList, s.settings.MainCategoryOrder,
+				conf.KindIncome, int(month), year, s.rateCache.Convert)
+			if err != nil && convertErr == nil {
+				convertErr = err
 			}
 
 			consumption, ok := s.cache.GetConsumption(int(month), year)
@@ -163,49 +160,262 @@ func (s *Calculation) UpsertBalance(currentMonth, currentYear int) (map[string]i
 			sum = sum - consumption + prevBalance
 
 			s.cache.UpsertBalance(int(month), year, sum)
-			res[utils.GetCompositeDate(int(month), year)] = sum
+
+			err = s.upsertCheckpointDiff(int(month), year, prevBalance, categories, valuesList)
+			if err != nil && convertErr == nil {
+				convertErr = err
+			}
+			res[entity.MonthYear{Month: int(month), Year: year}] = sum
 		}
 	}
 
+	if convertErr != nil {
+		return res, errors.WithMessage(convertErr, "convert income")
+	}
+
 	return res, nil
 }
 
-func (s *Calculation) getPreviousBalance(month, year int) (int, error) {
-	if year == s.settings.StartYear {
-		if month == s.settings.StartMonth {
-			return s.settings.StartMoney, nil
+// Recalculate
+// пересчет расходов и остатков всех месяцев, например после изменения курсов валют
+func (s *Calculation) Recalculate() error {
+	var convertErr error
+
+	for year := s.settings.StartYear; year <= time.Now().Year(); year++ {
+		firstMonth := time.January
+		if year == s.settings.StartYear {
+			firstMonth = time.Month(s.settings.StartMonth)
 		}
 
-		balance, ok := s.cache.GetBalance(month-1, year)
-		if !ok {
-			return 0, errors.Errorf("not found month balance, %s %d", time.Month(month-1).String(), year)
+		for month := firstMonth; month <= time.December; month++ {
+			_, err := s.ConsumptionSum(int(month), year)
+			if err != nil && convertErr == nil {
+				convertErr = err
+			}
+		}
+	}
+
+	_, err := s.UpsertBalance(s.settings.StartMonth, s.settings.StartYear)
+	if err != nil {
+		return errors.WithMessage(err, "upsert balance")
+	}
+
+	err = s.RecalculateAccounts()
+	if err != nil {
+		return errors.WithMessage(err, "recalculate accounts")
+	}
+
+	if convertErr != nil {
+		return convertErr
+	}
+
+	return nil
+}
+
+// RecalculateAccounts
+// пересчет остатков по счетам для всех месяцев: начальный остаток счета, доходы и расходы,
+// отнесенные на счет, и переводы; ячейки без счета относятся к условному счету "без счета"
+func (s *Calculation) RecalculateAccounts() error {
+	var convertErr error
+
+	s.categoryCache.Lock()
+	categories := s.categoryCache.GetCategoryArray()
+	s.categoryCache.Unlock()
+
+	s.cellsCache.Lock()
+	valuesList := s.cellsCache.GetList()
+	s.cellsCache.Unlock()
+
+	accounts := s.accountCache.ReadAccounts()
+
+	running := make(map[string]entity.Money, len(accounts)+1)
+	unassigned := s.settings.StartMoney
+	for _, account := range accounts {
+		running[account.Id] = account.OpeningBalance
+		unassigned -= account.OpeningBalance
+	}
+	running[domain.UnassignedAccountId] = unassigned
+
+	accountOf := func(accountId string) string {
+		if _, ok := running[accountId]; ok {
+			return accountId
 		}
 
-		return balance, nil
+		return domain.UnassignedAccountId
 	}
 
-	if month == 1 {
+	transfersByDate := make(map[entity.MonthYear][]domain.Transfer)
+	for _, transfer := range s.accountCache.ReadTransfers() {
+		date := entity.MonthYear{Month: int(transfer.Date.Month()), Year: transfer.Date.Year()}
+		transfersByDate[date] = append(transfersByDate[date], transfer)
+	}
+
+	balancesByDate := make(map[entity.MonthYear]map[string]entity.Money)
+	for year := s.settings.StartYear; year <= time.Now().Year(); year++ {
+		firstMonth := time.January
+		if year == s.settings.StartYear {
+			firstMonth = time.Month(s.settings.StartMonth)
+		}
+
+		for month := firstMonth; month <= time.December; month++ {
+			for _, mainCategory := range categories {
+				for _, category := range mainCategory {
+					var sign entity.Money
+					switch s.settings.MainCategoryOrder.Kind(category.MainCategory) {
+					case conf.KindIncome:
+						sign = 1
+					case conf.KindExpense:
+						sign = -1
+					default:
+						continue
+					}
+
+					cell, ok := valuesList[category.CellKey(month, year)]
+					if !ok {
+						continue
+					}
+
+					currency := domain.ResolveCurrency(cell.Currency, category.Currency, "")
+					for accountId, amount := range cell.AccountFlows() {
+						value, err := s.rateCache.Convert(amount, currency, month, year)
+						if err != nil && convertErr == nil {
+							convertErr = err
+						}
+
+						running[accountOf(accountId)] += sign * value
+					}
+				}
+			}
+
+			date := entity.MonthYear{Month: int(month), Year: year}
+			for _, transfer := range transfersByDate[date] {
+				running[accountOf(transfer.FromAccountId)] -= transfer.Amount
+				running[accountOf(transfer.ToAccountId)] += transfer.Amount
+			}
+
+			snapshot := make(map[string]entity.Money, len(running))
+			for accountId, balance := range running {
+				snapshot[accountId] = balance
+			}
+			balancesByDate[date] = snapshot
+		}
+	}
+
+	s.cache.SetAccountBalances(balancesByDate)
+
+	if convertErr != nil {
+		return errors.WithMessage(convertErr, "convert account flows")
+	}
+
+	return nil
+}
+
+// AccountBalances
+// остатки по счетам на конец месяца
+func (s *Calculation) AccountBalances(month, year int) map[string]entity.Money {
+	balances, ok := s.cache.GetAccountBalances(month, year)
+	if !ok {
+		return make(map[string]entity.Money)
+	}
+
+	return balances
+}
+
+// getPreviousBalance
+// остаток на конец предыдущего месяца; если в нем была сверка, остаток отсчитывается от нее:
+// к расчетному остатку прибавляется расхождение сверки
+func (s *Calculation) getPreviousBalance(month, year int) (entity.Money, error) {
+	if year == s.settings.StartYear && month == s.settings.StartMonth {
+		return s.settings.StartMoney, nil
+	}
+
+	prevMonth, prevYear := month-1, year
+	if month == 1 && year != s.settings.StartYear {
 		// если Январь, то берем Декабрь предыдущего года
-		balance, ok := s.cache.GetBalance(int(time.December), year-1)
+		prevMonth, prevYear = int(time.December), year-1
+	}
+
+	balance, ok := s.cache.GetBalance(prevMonth, prevYear)
+	if !ok {
+		return 0, errors.Errorf("not found month balance, %s %d", time.Month(prevMonth).String(), prevYear)
+	}
+
+	diff, _ := s.cache.GetCheckpointDiff(prevMonth, prevYear)
+
+	return balance + diff, nil
+}
+
+// upsertCheckpointDiff
+// пересчитывает расхождение последней сверки месяца с расчетом, если сверка есть
+func (s *Calculation) upsertCheckpointDiff(month, year int, prevBalance entity.Money,
+	categories [][]domain.Category, valuesList map[domain.CellKey]domain.Cell) error {
+	date := entity.MonthYear{Month: month, Year: year}
+	checkpoint, ok := domain.LatestCheckpoints(s.checkpointCache.ReadAll())[date]
+	if !ok {
+		s.cache.UpsertCheckpointDiff(month, year, 0, false)
+		return nil
+	}
+
+	diff, err := domain.CheckpointDiff(checkpoint, prevBalance, categories, valuesList,
+		s.settings.MainCategoryOrder, s.rateCache.Convert)
+	s.cache.UpsertCheckpointDiff(month, year, diff, true)
+	if err != nil {
+		return errors.WithMessage(err, "convert checkpoint flow")
+	}
+
+	return nil
+}
+
+// CheckpointDiff
+// расхождение последней сверки месяца: фактический остаток минус расчетный на дату сверки
+func (s *Calculation) CheckpointDiff(month, year int) (entity.Money, bool) {
+	return s.cache.GetCheckpointDiff(month, year)
+}
+
+// CategoryRollUp
+// значение категории за месяц вместе со всеми подкатегориями в базовой валюте
+func (s *Calculation) CategoryRollUp(category domain.Category, month, year int) (entity.Money, error) {
+	var convertErr error
+
+	s.categoryCache.Lock()
+	tree := s.categoryCache.GetTree()
+	s.categoryCache.Unlock()
+
+	s.cellsCache.Lock()
+	valuesList := s.cellsCache.GetList()
+	s.cellsCache.Unlock()
+
+	var res entity.Money
+	for _, categ := range append([]domain.Category{category}, tree.Descendants(category.Id)...) {
+		cell, ok := valuesList[categ.CellKey(time.Month(month), year)]
 		if !ok {
-			return 0, errors.Errorf("not found month balance, %s %d", time.December, year-1)
+			continue
 		}
-		return balance, nil
+
+		value, err := domain.ConvertCell(cell, categ, s.rateCache.Convert)
+		if err != nil && convertErr == nil {
+			convertErr = err
+		}
+		res += value
 	}
 
-	balance, ok := s.cache.GetBalance(month-1, year)
-	if !ok {
-		return 0, errors.Errorf("not found month balance, %s %d", time.Month(month-1).String(), year)
+	if convertErr != nil {
+		return res, errors.WithMessage(convertErr, "convert roll-up")
 	}
 
-	return balance, nil
+	return res, nil
 }
 
-func (s *Calculation) GetAnnualResult(year int) map[string]int {
-	res := make(map[string]int)
+// GetAnnualResult
+// годовые итоги по категориям (факт и план), расходам и остатку в базовой валюте;
+// итог родительской категории включает итоги всех ее подкатегорий
+func (s *Calculation) GetAnnualResult(year int) (map[string]domain.CategoryResult, error) {
+	res := make(map[string]domain.CategoryResult)
+	var convertErr error
 
 	s.categoryCache.Lock()
 	categories := s.categoryCache.GetCategoryArray()
+	tree := s.categoryCache.GetTree()
 	s.categoryCache.Unlock()
 
 	s.cellsCache.Lock()
@@ -214,14 +424,31 @@ func (s *Calculation) GetAnnualResult(year int) map[string]int {
 
 	for _, mainCategoryArr := range categories {
 		for _, category := range mainCategoryArr {
-			categoryResult := 0
+			var categoryResult domain.CategoryResult
 
 			for month := 1; month <= int(time.December); month++ {
-				compositeId := utils.GetCompositeId(category.MainCategory, category.Name, month, year)
-				cell, ok := valuesList[compositeId]
+				key := category.CellKey(time.Month(month), year)
+
+				plan, ok := s.planCache.Get(key)
 				if ok {
-					categoryResult += cell.Value
+					currency := domain.ResolveCurrency(plan.Currency, category.Currency, "")
+					value, err := s.rateCache.Convert(plan.Value, currency, time.Month(month), year)
+					if err != nil && convertErr == nil {
+						convertErr = err
+					}
+					categoryResult.Plan += value
+				}
+
+				cell, ok := valuesList[key]
+				if !ok {
+					continue
+				}
+
+				value, err := domain.ConvertCell(cell, category, s.rateCache.Convert)
+				if err != nil && convertErr == nil {
+					convertErr = err
 				}
+				categoryResult.Actual += value
 			}
 
 			compositeCategory := utils.GetCompositeCategory(category.MainCategory, category.Name)
@@ -229,7 +456,26 @@ func (s *Calculation) GetAnnualResult(year int) map[string]int {
 		}
 	}
 
-	consumptionResult := 0
+	// собственные итоги подкатегорий прибавляются ко всем их родителям
+	ownResults := make(map[string]domain.CategoryResult, len(res))
+	for compositeCategory, result := range res {
+		ownResults[compositeCategory] = result
+	}
+
+	for _, mainCategoryArr := range categories {
+		for _, category := range mainCategoryArr {
+			own := ownResults[utils.GetCompositeCategory(category.MainCategory, category.Name)]
+			for _, parent := range tree.Ancestors(category) {
+				compositeParent := utils.GetCompositeCategory(parent.MainCategory, parent.Name)
+				parentResult := res[compositeParent]
+				parentResult.Actual += own.Actual
+				parentResult.Plan += own.Plan
+				res[compositeParent] = parentResult
+			}
+		}
+	}
+
+	var consumptionResult entity.Money
 	for month := 1; month <= int(time.December); month++ {
 		consumption, ok := s.cache.GetConsumption(month, year)
 		if ok {
@@ -237,15 +483,276 @@ func (s *Calculation) GetAnnualResult(year int) map[string]int {
 		}
 	}
 
-	var balanceResult int
+	var balanceResult entity.Money
 	var ok bool
 	balanceResult, ok = s.cache.GetBalance(int(time.December), year)
 	if !ok {
 		balanceResult = 0
 	}
 
-	res[domain.ColumnConsumption] = consumptionResult
-	res[domain.ColumnBalance] = balanceResult
+	res[domain.ColumnConsumption] = domain.CategoryResult{Actual: consumptionResult}
+	res[domain.ColumnBalance] = domain.CategoryResult{Actual: balanceResult}
+
+	// остаток долга по кредитам и капитал на конец года
+	netWorth := s.netWorthPoint(int(time.December), year, s.loanCache.ReadAll(), s.valuationCache.ReadAll())
+	res[domain.ColumnDebt] = domain.CategoryResult{Actual: netWorth.Debt}
+	res[domain.ColumnNetWorth] = domain.CategoryResult{Actual: netWorth.NetWorth()}
+
+	if convertErr != nil {
+		return res, errors.WithMessage(convertErr, "convert annual result")
+	}
+
+	return res, nil
+}
+
+// GetPeriodResults
+// итоги по периодам в интервале [from, to): значения ячеек распределяются по датам операций,
+// поэтому период может начинаться с любого дня, например от зарплаты до зарплаты
+func (s *Calculation) GetPeriodResults(setting domain.PeriodSetting, from, to time.Time) ([]domain.PeriodResult, error) {
+	err := setting.Validate()
+	if err != nil {
+		return nil, errors.WithMessage(err, "validate period setting")
+	}
+
+	var convertErr error
+
+	s.categoryCache.Lock()
+	categories := s.categoryCache.GetCategoryArray()
+	tree := s.categoryCache.GetTree()
+	s.categoryCache.Unlock()
+
+	s.cellsCache.Lock()
+	valuesList := s.cellsCache.GetList()
+	s.cellsCache.Unlock()
+
+	periods := setting.Periods(from, to)
+	res := make([]domain.PeriodResult, 0, len(periods))
+	indexByStart := make(map[time.Time]int, len(periods))
+	for i, period := range periods {
+		res = append(res, domain.PeriodResult{
+			Period:     period,
+			ByCategory: make(map[string]entity.Money),
+		})
+		indexByStart[period.Start] = i
+	}
+
+	// операции ячейки лежат в ее месяце, поэтому достаточно месяцев, пересекающих интервал
+	firstMonth := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for date := firstMonth; date.Before(to); date = date.AddDate(0, 1, 0) {
+		for _, mainCategoryArr := range categories {
+			for _, category := range mainCategoryArr {
+				cell, ok := valuesList[category.CellKey(date.Month(), date.Year())]
+				if !ok {
+					continue
+				}
+
+				kind := s.settings.MainCategoryOrder.Kind(category.MainCategory)
+				currency := domain.ResolveCurrency(cell.Currency, category.Currency, "")
+				for _, dated := range cell.DatedValues() {
+					if dated.Date.Before(from) || !dated.Date.Before(to) {
+						continue
+					}
+
+					i, ok := indexByStart[setting.PeriodOf(dated.Date).Start]
+					if !ok {
+						continue
+					}
+
+					value, err := s.rateCache.Convert(dated.Amount, currency, cell.Month, cell.Year)
+					if err != nil && convertErr == nil {
+						convertErr = err
+					}
+
+					res[i].ByCategory[utils.GetCompositeCategory(category.MainCategory, category.Name)] += value
+					for _, parent := range tree.Ancestors(category) {
+						res[i].ByCategory[utils.GetCompositeCategory(parent.MainCategory, parent.Name)] += value
+					}
+
+					switch kind {
+					case conf.KindIncome:
+						res[i].Income += value
+					case conf.KindExpense:
+						res[i].Consumption += value
+					}
+				}
+			}
+		}
+	}
+
+	if convertErr != nil {
+		return res, errors.WithMessage(convertErr, "convert period results")
+	}
+
+	return res, nil
+}
+
+// GoalProgress
+// прогресс целей на месяц now: накопленное складывается из ячеек привязанных категорий
+// вместе с подкатегориями по этот месяц включительно и остатков привязанных счетов на его конец
+func (s *Calculation) GoalProgress(goals []domain.Goal, now time.Time) ([]domain.GoalProgress, error) {
+	var convertErr error
+
+	s.categoryCache.Lock()
+	tree := s.categoryCache.GetTree()
+	s.categoryCache.Unlock()
+
+	s.cellsCache.Lock()
+	valuesList := s.cellsCache.GetList()
+	s.cellsCache.Unlock()
+
+	balances := s.AccountBalances(int(now.Month()), now.Year())
+
+	res := make([]domain.GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		var saved entity.Money
+
+		for _, link := range goal.Links {
+			if link.Kind == domain.GoalLinkAccount {
+				saved += balances[link.AccountId]
+				continue
+			}
+
+			category, ok := tree.FindByName(link.MainCategory, link.Category)
+			if !ok {
+				continue
+			}
+
+			for _, categ := range append([]domain.Category{category}, tree.Descendants(category.Id)...) {
+				for year := s.settings.StartYear; year <= now.Year(); year++ {
+					lastMonth := time.December
+					if year == now.Year() {
+						lastMonth = now.Month()
+					}
+
+					for month := time.January; month <= lastMonth; month++ {
+						cell, ok := valuesList[categ.CellKey(month, year)]
+						if !ok {
+							continue
+						}
+
+						value, err := domain.ConvertCell(cell, categ, s.rateCache.Convert)
+						if err != nil && convertErr == nil {
+							convertErr = err
+						}
+						saved += value
+					}
+				}
+			}
+		}
+
+		res = append(res, goal.Progress(saved, now))
+	}
+
+	if convertErr != nil {
+		return res, errors.WithMessage(convertErr, "convert goal progress")
+	}
+
+	return res, nil
+}
+
+// NetWorth
+// капитал на конец каждого месяца с fromYear по toYear включительно; месяцы без расчетного остатка пропускаются
+func (s *Calculation) NetWorth(fromYear, toYear int) []domain.NetWorthPoint {
+	loans := s.loanCache.ReadAll()
+	valuations := s.valuationCache.ReadAll()
+
+	res := make([]domain.NetWorthPoint, 0)
+	for year := fromYear; year <= toYear; year++ {
+		for month := 1; month <= int(time.December); month++ {
+			if _, ok := s.cache.GetBalance(month, year); !ok {
+				continue
+			}
+
+			res = append(res, s.netWorthPoint(month, year, loans, valuations))
+		}
+	}
 
 	return res
 }
+
+// netWorthPoint
+// капитал на последний день месяца; остаток берется с учетом расхождения сверки месяца
+func (s *Calculation) netWorthPoint(month, year int, loans []domain.Loan,
+	valuations []domain.Valuation) domain.NetWorthPoint {
+	monthEnd := time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC)
+
+	point := domain.NetWorthPoint{Date: entity.MonthYear{Month: month, Year: year}}
+	point.Balance, _ = s.cache.GetBalance(month, year)
+	if diff, ok := s.cache.GetCheckpointDiff(month, year); ok {
+		point.Balance += diff
+	}
+
+	point.Assets, point.Liabilities = domain.ValuationsOn(valuations, monthEnd)
+	for _, loan := range loans {
+		point.Debt += loan.Outstanding(monthEnd)
+	}
+
+	return point
+}
+
+// PayeeSpending
+// расходы по получателям операций в ячейках расходных категорий с fromYear по toYear в базовой валюте,
+// по убыванию общей суммы; названия, отличающиеся регистром, считаются одним получателем
+func (s *Calculation) PayeeSpending(fromYear, toYear int) ([]domain.PayeeSpending, error) {
+	var convertErr error
+
+	s.categoryCache.Lock()
+	categories := s.categoryCache.GetCategoryArray()
+	s.categoryCache.Unlock()
+
+	s.cellsCache.Lock()
+	valuesList := s.cellsCache.GetList()
+	s.cellsCache.Unlock()
+
+	res := make([]domain.PayeeSpending, 0)
+	indexByName := make(map[string]int)
+	for year := fromYear; year <= toYear; year++ {
+		for _, mainCategoryArr := range categories {
+			for _, category := range mainCategoryArr {
+				if s.settings.MainCategoryOrder.Kind(category.MainCategory) != conf.KindExpense {
+					continue
+				}
+
+				for month := time.January; month <= time.December; month++ {
+					cell, ok := valuesList[category.CellKey(month, year)]
+					if !ok {
+						continue
+					}
+
+					currency := domain.ResolveCurrency(cell.Currency, category.Currency, "")
+					for _, transaction := range cell.ActiveTransactions() {
+						name := strings.TrimSpace(transaction.Payee)
+						if len(name) == 0 {
+							continue
+						}
+
+						value, err := s.rateCache.Convert(transaction.Amount, currency, cell.Month, cell.Year)
+						if err != nil && convertErr == nil {
+							convertErr = err
+						}
+
+						i, ok := indexByName[strings.ToLower(name)]
+						if !ok {
+							i = len(res)
+							indexByName[strings.ToLower(name)] = i
+							res = append(res, domain.PayeeSpending{Payee: name, ByYear: make(map[int]entity.Money)})
+						}
+
+						res[i].ByYear[year] += value
+						res[i].Total += value
+					}
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Total > res[j].Total
+	})
+
+	if convertErr != nil {
+		return res, errors.WithMessage(convertErr, "convert payee spending")
+	}
+
+	return res, nil
+}
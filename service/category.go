@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"table-app/conf"
 	"table-app/domain"
@@ -13,6 +14,7 @@ import (
 
 type CategoryRepository interface {
 	UpsertAll(ctx context.Context, categories []domain.Category) error
+	DeleteAll(ctx context.Context, categories []domain.Category) error
 }
 
 type Category struct {
@@ -44,6 +46,21 @@ func (s *Category) SaveAll(ctx context.Context) error {
 	return nil
 }
 
+// SaveDeleted
+// сохраняет удаление категорий; вызывается после сохранения ячеек, которые на них ссылались
+func (s *Category) SaveDeleted(ctx context.Context) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	err := s.repo.DeleteAll(ctx, s.cache.ReadDeleted())
+	if err != nil {
+		return errors.WithMessage(err, "delete categories")
+	}
+
+	s.cache.ClearDeleted()
+	return nil
+}
+
 func (s *Category) AddCategory(newCat domain.Category) error {
 	s.cache.Lock()
 	defer s.cache.Unlock()
@@ -56,6 +73,31 @@ func (s *Category) AddCategory(newCat domain.Category) error {
 	return nil
 }
 
+// EnsureCategory
+// категория name в первой по порядку основной категории вида kind; создается, если ее нет
+func (s *Category) EnsureCategory(kind conf.CategoryKind, name string) (domain.Category, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	mainCategory, ok := s.cache.MainCategoryOrder().FirstOfKind(kind)
+	if !ok {
+		return domain.Category{}, errors.Errorf("main category of kind %s not found", kind)
+	}
+
+	category, ok := s.cache.Get(mainCategory, name)
+	if ok {
+		return category, nil
+	}
+
+	err := s.cache.Insert(domain.Category{Name: name, MainCategory: mainCategory})
+	if err != nil {
+		return domain.Category{}, errors.WithMessage(err, "insert category")
+	}
+
+	category, _ = s.cache.Get(mainCategory, name)
+	return category, nil
+}
+
 func (s *Category) CategoryIsExist(category domain.Category) bool {
 	s.cache.Lock()
 	defer s.cache.Unlock()
@@ -73,3 +115,64 @@ func (s *Category) UpdateCategory(old, new domain.Category) error {
 
 	return nil
 }
+
+func (s *Category) GetCategory(mainCategory, name string) (domain.Category, bool) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	return s.cache.Get(mainCategory, name)
+}
+
+func (s *Category) DeleteCategory(category domain.Category) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	err := s.cache.Delete(category)
+	if err != nil {
+		return errors.WithMessage(err, "delete category")
+	}
+
+	return nil
+}
+
+// ReorderCategory
+// shift < 0 - выше, shift > 0 - ниже среди категорий с тем же родителем
+func (s *Category) ReorderCategory(category domain.Category, shift int) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	err := s.cache.Reorder(category, shift)
+	if err != nil {
+		return errors.WithMessage(err, "reorder category")
+	}
+
+	return nil
+}
+
+// ChangeMainCategory
+// возвращает перенесенные категории до переноса
+func (s *Category) ChangeMainCategory(category domain.Category, mainCategory string) ([]domain.Category, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	moved, err := s.cache.ChangeMainCategory(category, mainCategory)
+	if err != nil {
+		return nil, errors.WithMessage(err, "change main category")
+	}
+
+	return moved, nil
+}
+
+// ArchiveCategory
+// нулевой год возвращает категорию из архива
+func (s *Category) ArchiveCategory(category domain.Category, month time.Month, year int) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	err := s.cache.SetArchived(category, month, year)
+	if err != nil {
+		return errors.WithMessage(err, "archive category")
+	}
+
+	return nil
+}
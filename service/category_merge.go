@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/pkg/errors"
+)
+
+type CategoryMergeRepository interface {
+	Save(ctx context.Context, data repository.MergeData) error
+}
+
+type CategoryMerge struct {
+	logger        log.Logger
+	cellsCache    *repository.CellsCache
+	categoryCache *repository.CategoryCache
+	repo          CategoryMergeRepository
+}
+
+func NewCategoryMerge(logger log.Logger, cellsCache *repository.CellsCache, categoryCache *repository.CategoryCache,
+	repo CategoryMergeRepository) *CategoryMerge {
+	return &CategoryMerge{
+		logger:        logger,
+		cellsCache:    cellsCache,
+		categoryCache: categoryCache,
+		repo:          repo,
+	}
+}
+
+// Merge
+// сливает категорию from в категорию to: ячейки одного месяца складываются, их операции объединяются,
+// подкатегории from переходят к ее родителю, а сама from удаляется. Результат сразу сохраняется;
+// при ошибке сохранения изменения остаются в кешах помеченными измененными и сохранятся вместе
+// с остальными данными, а возвращается domain.MergeNotSavedError с ошибкой хранилища,
+// чтобы пользователь знал, что слияние не записано
+func (s *CategoryMerge) Merge(ctx context.Context, from, to domain.Category) error {
+	s.categoryCache.Lock()
+	defer s.categoryCache.Unlock()
+
+	s.cellsCache.Lock()
+	defer s.cellsCache.Unlock()
+
+	from, ok := s.categoryCache.Get(from.MainCategory, from.Name)
+	if !ok {
+		return errors.Errorf("category %s not found", from.Name)
+	}
+
+	to, ok = s.categoryCache.Get(to.MainCategory, to.Name)
+	if !ok {
+		return errors.Errorf("target category %s not found", to.Name)
+	}
+
+	if from.Id == to.Id {
+		return errors.New("category is merged into itself")
+	}
+
+	if from.Currency != to.Currency {
+		return errors.Errorf("target category currency %s differs from %s", to.Currency, from.Currency)
+	}
+
+	err := s.categoryCache.CheckDelete(from)
+	if err != nil {
+		return errors.WithMessage(err, "check delete category")
+	}
+
+	updated, absorbed, err := s.cellsCache.MoveCategory(from, to)
+	if err != nil {
+		return errors.WithMessage(err, "move cells")
+	}
+
+	err = s.categoryCache.Delete(from)
+	if err != nil {
+		return errors.WithMessage(err, "delete category")
+	}
+
+	data := repository.MergeData{
+		Cells:        updated,
+		AllCells:     s.cellsCache.ReadAll(),
+		DeletedCells: absorbed,
+		Categories:   s.categoryCache.ReadAll(),
+		Deleted:      from,
+	}
+
+	err = s.repo.Save(ctx, data)
+	if err != nil {
+		return domain.MergeNotSavedError{Err: errors.WithMessage(err, "save merge")}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type CheckpointRepository interface {
+	ReplaceAll(ctx context.Context, items []domain.Checkpoint) error
+}
+
+type Checkpoint struct {
+	logger log.Logger
+	cache  *repository.CheckpointCache
+	repo   CheckpointRepository
+}
+
+func NewCheckpoint(logger log.Logger, cache *repository.CheckpointCache, repo CheckpointRepository) *Checkpoint {
+	return &Checkpoint{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Checkpoint) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace checkpoints")
+	}
+
+	return nil
+}
+
+func (s *Checkpoint) GetCheckpoints() []domain.Checkpoint {
+	return s.cache.ReadAll()
+}
+
+// ReplaceCheckpoints
+// заменяет список сверок, упорядочивая по дате; новым сверкам присваивается id
+func (s *Checkpoint) ReplaceCheckpoints(items []domain.Checkpoint) error {
+	for i := range items {
+		err := items[i].Validate()
+		if err != nil {
+			return errors.WithMessage(err, "validate checkpoint")
+		}
+
+		if len(items[i].Id) == 0 {
+			items[i].Id = uuid.New().String()
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Date.Before(items[j].Date)
+	})
+
+	s.cache.InitCache(items)
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type GoalRepository interface {
+	ReplaceAll(ctx context.Context, goals []domain.Goal) error
+}
+
+type Goal struct {
+	logger log.Logger
+	cache  *repository.GoalCache
+	repo   GoalRepository
+}
+
+func NewGoal(logger log.Logger, cache *repository.GoalCache, repo GoalRepository) *Goal {
+	return &Goal{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Goal) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace goals")
+	}
+
+	return nil
+}
+
+func (s *Goal) GetGoals() []domain.Goal {
+	return s.cache.ReadAll()
+}
+
+// ReplaceGoals
+// заменяет список целей; новым целям присваивается id
+func (s *Goal) ReplaceGoals(goals []domain.Goal) error {
+	for i := range goals {
+		err := goals[i].Validate()
+		if err != nil {
+			return errors.WithMessagef(err, "validate goal %s", goals[i].Name)
+		}
+
+		if len(goals[i].Id) == 0 {
+			goals[i].Id = uuid.New().String()
+		}
+	}
+
+	s.cache.InitCache(goals)
+	return nil
+}
+
+func (s *Goal) UpdateCategoryName(oldCateg, newCateg domain.Category) {
+	s.cache.UpdateCategoryName(oldCateg, newCateg)
+}
+
+func (s *Goal) DeleteCategory(category domain.Category) {
+	s.cache.DeleteCategory(category)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type LoanRepository interface {
+	ReplaceAll(ctx context.Context, loans []domain.Loan) error
+}
+
+type Loan struct {
+	logger log.Logger
+	cache  *repository.LoanCache
+	repo   LoanRepository
+}
+
+func NewLoan(logger log.Logger, cache *repository.LoanCache, repo LoanRepository) *Loan {
+	return &Loan{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Loan) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace loans")
+	}
+
+	return nil
+}
+
+func (s *Loan) GetLoans() []domain.Loan {
+	return s.cache.ReadAll()
+}
+
+// ReplaceLoans
+// заменяет список кредитов; новым кредитам присваивается id
+func (s *Loan) ReplaceLoans(loans []domain.Loan) error {
+	for i := range loans {
+		err := loans[i].Validate()
+		if err != nil {
+			return errors.WithMessagef(err, "validate loan %s", loans[i].Name)
+		}
+
+		if len(loans[i].Id) == 0 {
+			loans[i].Id = uuid.New().String()
+		}
+	}
+
+	s.cache.InitCache(loans)
+	return nil
+}
+
+func (s *Loan) MarkMaterialized(id string, date time.Time) {
+	s.cache.SetLastDate(id, date)
+}
+
+func (s *Loan) UpdateCategoryName(oldCateg, newCateg domain.Category) {
+	s.cache.UpdateCategoryName(oldCateg, newCateg)
+}
+
+func (s *Loan) DeleteCategory(category domain.Category) {
+	s.cache.DeleteCategory(category)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type PayeeRepository interface {
+	ReplaceAll(ctx context.Context, items []domain.Payee) error
+}
+
+type Payee struct {
+	logger log.Logger
+	cache  *repository.PayeeCache
+	repo   PayeeRepository
+}
+
+func NewPayee(logger log.Logger, cache *repository.PayeeCache, repo PayeeRepository) *Payee {
+	return &Payee{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Payee) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace payees")
+	}
+
+	return nil
+}
+
+func (s *Payee) GetPayees() []domain.Payee {
+	return s.cache.ReadAll()
+}
+
+func (s *Payee) GetPayee(name string) (domain.Payee, bool) {
+	return s.cache.GetByName(name)
+}
+
+// ReplacePayees
+// заменяет справочник, упорядочивая по названию; новым получателям присваивается id;
+// возвращает переименования: старое название по новому
+func (s *Payee) ReplacePayees(items []domain.Payee) (map[string]string, error) {
+	prev := make(map[string]string)
+	for _, item := range s.cache.ReadAll() {
+		prev[item.Id] = item.Name
+	}
+
+	renamed := make(map[string]string)
+	for i := range items {
+		items[i].Name = strings.TrimSpace(items[i].Name)
+
+		err := items[i].Validate()
+		if err != nil {
+			return nil, errors.WithMessagef(err, "validate payee %s", items[i].Name)
+		}
+
+		for j := 0; j < i; j++ {
+			if domain.IsSamePayee(items[i].Name, items[j].Name) {
+				return nil, errors.Errorf("payee %s is duplicated", items[i].Name)
+			}
+		}
+
+		if len(items[i].Id) == 0 {
+			items[i].Id = uuid.New().String()
+			continue
+		}
+
+		oldName, ok := prev[items[i].Id]
+		if ok && oldName != items[i].Name {
+			renamed[items[i].Name] = oldName
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name)
+	})
+
+	s.cache.InitCache(items)
+	return renamed, nil
+}
+
+// Remember
+// добавляет в справочник получателей, которых в нем еще нет, с категорией по умолчанию category;
+// категория уже известных получателей не меняется
+func (s *Payee) Remember(names []string, category domain.Category) {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+
+		_, ok := s.cache.GetByName(name)
+		if ok {
+			continue
+		}
+
+		s.cache.Add(domain.Payee{
+			Id:           uuid.New().String(),
+			Name:         name,
+			MainCategory: category.MainCategory,
+			Category:     category.Name,
+		})
+	}
+}
+
+func (s *Payee) UpdateCategoryName(oldCateg, newCateg domain.Category) {
+	s.cache.UpdateCategoryName(oldCateg, newCateg)
+}
+
+func (s *Payee) DeleteCategory(category domain.Category) {
+	s.cache.DeleteCategory(category)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/pkg/errors"
+)
+
+type PlanRepository interface {
+	ReplaceAll(ctx context.Context, plans []domain.Plan) error
+}
+
+type Plan struct {
+	logger log.Logger
+	cache  *repository.PlanCache
+	repo   PlanRepository
+}
+
+func NewPlan(logger log.Logger, cache *repository.PlanCache, repo PlanRepository) *Plan {
+	return &Plan{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Plan) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace plans")
+	}
+
+	return nil
+}
+
+func (s *Plan) Upsert(plan domain.Plan) error {
+	err := plan.Validate()
+	if err != nil {
+		return errors.WithMessage(err, "validate plan")
+	}
+
+	s.cache.Upsert(plan)
+	return nil
+}
+
+func (s *Plan) GetPlanById(key domain.CellKey) (domain.Plan, bool) {
+	return s.cache.Get(key)
+}
+
+func (s *Plan) UpdateCategoryName(oldCateg, newCateg domain.Category) {
+	s.cache.UpdateCategoryName(oldCateg, newCateg)
+}
+
+func (s *Plan) MoveCategory(from, to domain.Category) {
+	s.cache.MoveCategory(from, to)
+}
+
+func (s *Plan) DeleteCategory(category domain.Category) {
+	s.cache.DeleteCategory(category)
+}
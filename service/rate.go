@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/pkg/errors"
+)
+
+type RateRepository interface {
+	ReplaceAll(ctx context.Context, rates []domain.ExchangeRate) error
+}
+
+type Rate struct {
+	logger log.Logger
+	cache  *repository.RateCache
+	repo   RateRepository
+}
+
+func NewRate(logger log.Logger, cache *repository.RateCache, repo RateRepository) *Rate {
+	return &Rate{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Rate) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace rates")
+	}
+
+	return nil
+}
+
+func (s *Rate) GetRates() []domain.ExchangeRate {
+	return s.cache.ReadAll()
+}
+
+// ReplaceRates
+// заменяет таблицу курсов целиком
+func (s *Rate) ReplaceRates(rates []domain.ExchangeRate) error {
+	for _, rate := range rates {
+		err := rate.Validate()
+		if err != nil {
+			return errors.WithMessagef(err, "validate rate %s %d.%d", rate.Currency, rate.Month, rate.Year)
+		}
+	}
+
+	s.cache.InitCache(rates)
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type RecurringRepository interface {
+	ReplaceAll(ctx context.Context, items []domain.Recurring) error
+}
+
+type Recurring struct {
+	logger log.Logger
+	cache  *repository.RecurringCache
+	repo   RecurringRepository
+}
+
+func NewRecurring(logger log.Logger, cache *repository.RecurringCache, repo RecurringRepository) *Recurring {
+	return &Recurring{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Recurring) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace recurring")
+	}
+
+	return nil
+}
+
+func (s *Recurring) GetRecurring() []domain.Recurring {
+	return s.cache.ReadAll()
+}
+
+// ReplaceRecurring
+// заменяет список регулярных платежей; новым платежам присваивается id
+func (s *Recurring) ReplaceRecurring(items []domain.Recurring) error {
+	for i := range items {
+		err := items[i].Validate()
+		if err != nil {
+			return errors.WithMessagef(err, "validate recurring %s", items[i].Category)
+		}
+
+		if len(items[i].Id) == 0 {
+			items[i].Id = uuid.New().String()
+		}
+	}
+
+	s.cache.InitCache(items)
+	return nil
+}
+
+func (s *Recurring) MarkMaterialized(id string, date time.Time) {
+	s.cache.SetLastDate(id, date)
+}
+
+func (s *Recurring) UpdateCategoryName(oldCateg, newCateg domain.Category) {
+	s.cache.UpdateCategoryName(oldCateg, newCateg)
+}
+
+func (s *Recurring) DeleteCategory(category domain.Category) {
+	s.cache.DeleteCategory(category)
+}
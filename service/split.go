@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/pkg/errors"
+)
+
+type SplitRepository interface {
+	ReplaceAll(ctx context.Context, splits []domain.Split) error
+}
+
+type Split struct {
+	logger log.Logger
+	cache  *repository.SplitCache
+	repo   SplitRepository
+}
+
+func NewSplit(logger log.Logger, cache *repository.SplitCache, repo SplitRepository) *Split {
+	return &Split{
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+	}
+}
+
+func (s *Split) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace splits")
+	}
+
+	return nil
+}
+
+func (s *Split) GetSplit(id string) (domain.Split, bool) {
+	return s.cache.Get(id)
+}
+
+func (s *Split) GetSplitByTransaction(transactionId string) (domain.Split, bool) {
+	return s.cache.GetByTransaction(transactionId)
+}
+
+// UpsertSplit
+// сохраняет разделенную операцию в кеш; id группы и операций частей назначает вызывающий
+func (s *Split) UpsertSplit(split domain.Split) error {
+	err := split.Validate()
+	if err != nil {
+		return errors.WithMessage(err, "validate split")
+	}
+
+	if len(split.Id) == 0 {
+		return errors.New("split id is empty")
+	}
+
+	s.cache.Upsert(split)
+	return nil
+}
+
+func (s *Split) DeleteSplit(id string) {
+	s.cache.Delete(id)
+}
+
+func (s *Split) RenamePayee(oldName, newName string) {
+	s.cache.RenamePayee(oldName, newName)
+}
+
+func (s *Split) UpdateCategoryName(oldCateg, newCateg domain.Category) {
+	s.cache.UpdateCategoryName(oldCateg, newCateg)
+}
+
+func (s *Split) DeleteCategory(category domain.Category) {
+	s.cache.DeleteCategory(category)
+}
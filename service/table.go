@@ -12,25 +12,22 @@ import (
 )
 
 type TableRepository interface {
-	UpsertAll(ctx context.Context, cells []domain.Cell) error
-	GetAll(ctx context.Context) ([]domain.Cell, error)
+	SaveAll(ctx context.Context, cells, deleted []domain.Cell) error
 }
 
 type Table struct {
-	logger        log.Logger
-	cache         *repository.CellsCache
-	repo          TableRepository
-	cfg           conf.Setting
-	isFileStorage bool
+	logger log.Logger
+	cache  *repository.CellsCache
+	repo   TableRepository
+	cfg    conf.Setting
 }
 
-func NewTable(logger log.Logger, cache *repository.CellsCache, repo TableRepository, cfg conf.Setting, isFileStorage bool) *Table {
+func NewTable(logger log.Logger, cache *repository.CellsCache, repo TableRepository, cfg conf.Setting) *Table {
 	return &Table{
-		logger:        logger,
-		cache:         cache,
-		repo:          repo,
-		cfg:           cfg,
-		isFileStorage: isFileStorage,
+		logger: logger,
+		cache:  cache,
+		repo:   repo,
+		cfg:    cfg,
 	}
 }
 
@@ -43,35 +40,42 @@ func (s *Table) Upsert(cell domain.Cell) error {
 	return nil
 }
 
-func (s *Table) SaveAll(ctx context.Context) error {
+// UpsertAll
+// обновляет несколько ячеек за одну блокировку кеша, например части разделенной операции
+func (s *Table) UpsertAll(cells []domain.Cell) error {
 	s.cache.Lock()
 	defer s.cache.Unlock()
 
-	cells := s.cache.ReadAll()
+	for _, cell := range cells {
+		s.cache.Upsert(cell)
+	}
 
-	if s.isFileStorage {
-		err := s.repo.UpsertAll(ctx, cells)
-		if err != nil {
-			return errors.WithMessage(err, "upsert cells")
-		}
+	return nil
+}
 
-		return nil
-	}
+// AddTransaction
+// добавляет операцию в ячейку за одну блокировку кеша: сохранение той же ячейки из окна
+// в это время не потеряет операцию; false - операция уже проведена
+func (s *Table) AddTransaction(key domain.CellKey, transaction domain.Transaction) (domain.Cell, bool, error) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
 
-	updatedCells := make([]domain.Cell, 0)
-	for _, cell := range cells {
-		if !cell.IsUpdated {
-			continue
-		}
+	return s.cache.AddTransaction(key, transaction)
+}
 
-		updatedCells = append(updatedCells, cell)
-	}
+// SaveAll
+// хранилище получает все ячейки и удаленные ячейки и само выбирает, что сохранить
+func (s *Table) SaveAll(ctx context.Context) error {
+	s.cache.Lock()
+	defer s.cache.Unlock()
 
-	err := s.repo.UpsertAll(ctx, updatedCells)
+	err := s.repo.SaveAll(ctx, s.cache.ReadAll(), s.cache.ReadDeleted())
 	if err != nil {
-		return errors.WithMessage(err, "upsert cells")
+		return errors.WithMessage(err, "save cells")
 	}
 
+	s.cache.ClearDeletedTransactions()
+	s.cache.ClearDeleted()
 	return nil
 }
 
@@ -82,9 +86,27 @@ func (s *Table) UpdateCategoryName(oldCateg, newCateg domain.Category) {
 	s.cache.UpdateCategoryName(oldCateg, newCateg, s.cfg.StartMonth, s.cfg.StartYear)
 }
 
-func (s *Table) GetCellById(compositeId string) (domain.Cell, bool) {
+// RenamePayee
+// меняет получателя во всех операциях ячеек
+func (s *Table) RenamePayee(oldName, newName string) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	s.cache.RenamePayee(oldName, newName)
+}
+
+// DeleteCategory
+// удаляет ячейки категории
+func (s *Table) DeleteCategory(category domain.Category) {
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	s.cache.DeleteCategory(category)
+}
+
+func (s *Table) GetCellById(key domain.CellKey) (domain.Cell, bool) {
 	s.cache.Lock()
 	defer s.cache.Unlock()
 
-	return s.cache.Get(compositeId)
+	return s.cache.Get(key)
 }
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"table-app/domain"
+	"table-app/internal/log"
+	"table-app/repository"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+type ValuationRepository interface {
+	ReplaceAll(ctx context.Context, items []domain.Valuation) error
+}
+
+type NetWorthExporter interface {
+	Write(filePath string, points []domain.NetWorthPoint) error
+}
+
+type Valuation struct {
+	logger   log.Logger
+	cache    *repository.ValuationCache
+	repo     ValuationRepository
+	exporter NetWorthExporter
+}
+
+func NewValuation(logger log.Logger, cache *repository.ValuationCache, repo ValuationRepository,
+	exporter NetWorthExporter) *Valuation {
+	return &Valuation{
+		logger:   logger,
+		cache:    cache,
+		repo:     repo,
+		exporter: exporter,
+	}
+}
+
+func (s *Valuation) SaveAll(ctx context.Context) error {
+	err := s.repo.ReplaceAll(ctx, s.cache.ReadAll())
+	if err != nil {
+		return errors.WithMessage(err, "replace valuations")
+	}
+
+	return nil
+}
+
+func (s *Valuation) GetValuations() []domain.Valuation {
+	return s.cache.ReadAll()
+}
+
+// ReplaceValuations
+// заменяет список оценок, упорядочивая по дате; новым оценкам присваивается id
+func (s *Valuation) ReplaceValuations(items []domain.Valuation) error {
+	for i := range items {
+		err := items[i].Validate()
+		if err != nil {
+			return errors.WithMessagef(err, "validate valuation %s", items[i].Asset)
+		}
+
+		if len(items[i].Id) == 0 {
+			items[i].Id = uuid.New().String()
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Date.Before(items[j].Date)
+	})
+
+	s.cache.InitCache(items)
+	return nil
+}
+
+func (s *Valuation) ExportNetWorth(filePath string, points []domain.NetWorthPoint) error {
+	err := s.exporter.Write(filePath, points)
+	if err != nil {
+		return errors.WithMessage(err, "write net worth")
+	}
+
+	return nil
+}
@@ -2,14 +2,9 @@ package utils
 
 import "strconv"
 
-func GetCompositeDate(month, year int) string {
-	return strconv.Itoa(month) + strconv.Itoa(year)
-}
-
-func GetCompositeId(mainCategory, category string, month, year int) string {
-	return mainCategory + category + strconv.Itoa(month) + strconv.Itoa(year)
-}
-
+// GetCompositeCategory
+// Однозначный ключ категории: названия в кавычках, поэтому "Доход"+"ы X" и "Доходы"+" X"
+// не совпадают; используется как ключ итогов и имя узлов интерфейса
 func GetCompositeCategory(mainCategory, category string) string {
-	return mainCategory + category
+	return strconv.Quote(mainCategory) + ":" + strconv.Quote(category)
 }
@@ -0,0 +1,13 @@
+package utils
+
+import "testing"
+
+func TestGetCompositeCategoryCollidingNames(t *testing.T) {
+	if GetCompositeCategory("Доход", "ы X") == GetCompositeCategory("Доходы", " X") {
+		t.Error("composite categories of different categories are equal")
+	}
+
+	if GetCompositeCategory("Доходы", "X") != GetCompositeCategory("Доходы", "X") {
+		t.Error("composite category is not stable")
+	}
+}